@@ -6,6 +6,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/qraqula/qla/internal/app"
+	"github.com/qraqula/qla/internal/cli"
 )
 
 var version = "dev"
@@ -15,6 +16,11 @@ func main() {
 		fmt.Println("qla " + version)
 		return
 	}
+	if len(os.Args) > 1 {
+		if cmd, ok := cli.Commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
 
 	m := app.NewModel()
 	p := tea.NewProgram(m)
@@ -43,6 +43,11 @@ var resultsHints = []statusbar.Hint{
 	{Key: "↵", Label: "execute"},
 	{Key: "tab", Label: "next"},
 	{Key: "/", Label: "search"},
+	{Key: "e", Label: "errors"},
+	{Key: "i", Label: "inspect"},
+	{Key: "b", Label: "bench"},
+	{Key: "w", Label: "poll"},
+	{Key: "c", Label: "compare"},
 	{Key: "^y", Label: "copy"},
 	{Key: "^s", Label: "save"},
 	{Key: "^d", Label: "docs"},
@@ -64,6 +69,46 @@ var schemaBrowserHints = []statusbar.Hint{
 	{Key: "^q", Label: "quit"},
 }
 
+var errorsHints = []statusbar.Hint{
+	{Key: "j/k", Label: "navigate"},
+	{Key: "↵", Label: "show in result"},
+	{Key: "g", Label: "go to query"},
+	{Key: "esc", Label: "result"},
+	{Key: "^q", Label: "quit"},
+}
+
+var inspectorHints = []statusbar.Hint{
+	{Key: "j/k", Label: "scroll"},
+	{Key: "r", Label: "reveal secrets"},
+	{Key: "esc", Label: "result"},
+	{Key: "^q", Label: "quit"},
+}
+
+var reportHints = []statusbar.Hint{
+	{Key: "j/k", Label: "navigate"},
+	{Key: "↵", Label: "load entry"},
+	{Key: "^s", Label: "save JUnit"},
+	{Key: "^c", Label: "abort"},
+	{Key: "esc", Label: "result"},
+	{Key: "^q", Label: "quit"},
+}
+
+var benchHints = []statusbar.Hint{
+	{Key: "↵", Label: "run"},
+	{Key: "^c", Label: "abort"},
+	{Key: "esc", Label: "result"},
+	{Key: "^q", Label: "quit"},
+}
+
+var compareHints = []statusbar.Hint{
+	{Key: "j/k", Label: "scroll"},
+	{Key: "↵", Label: "run again"},
+	{Key: "i", Label: "ignore"},
+	{Key: "^c", Label: "abort"},
+	{Key: "esc", Label: "result"},
+	{Key: "^q", Label: "quit"},
+}
+
 var endpointHints = []statusbar.Hint{
 	{Key: "tab", Label: "next"},
 	{Key: "^y", Label: "copy"},
@@ -81,6 +126,8 @@ var historyHints = []statusbar.Hint{
 	{Key: "r", Label: "rename"},
 	{Key: "d", Label: "delete"},
 	{Key: "m/M", Label: "move"},
+	{Key: "R", Label: "run folder"},
+	{Key: "s", Label: "saved runs"},
 	{Key: "/", Label: "filter"},
 	{Key: "^b", Label: "close"},
 	{Key: "^q", Label: "quit"},
@@ -94,8 +141,19 @@ func hintsForFocus(panel Panel, rpMode rightPanelMode) []statusbar.Hint {
 	case PanelVariables:
 		return variablesHints
 	case PanelResults:
-		if rpMode == modeSchema {
+		switch rpMode {
+		case modeSchema:
 			return schemaBrowserHints
+		case modeErrors:
+			return errorsHints
+		case modeInspector:
+			return inspectorHints
+		case modeReport:
+			return reportHints
+		case modeBench:
+			return benchHints
+		case modeCompare:
+			return compareHints
 		}
 		return resultsHints
 	case PanelEndpoint:
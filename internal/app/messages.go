@@ -1,8 +1,12 @@
 package app
 
 import (
+	"github.com/qraqula/qla/internal/bench"
+	"github.com/qraqula/qla/internal/compare"
 	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/poll"
 	"github.com/qraqula/qla/internal/schema"
+	"github.com/qraqula/qla/internal/suite"
 )
 
 // QueryResultMsg is sent when a query completes successfully.
@@ -18,6 +22,19 @@ type QueryErrorMsg struct {
 // QueryAbortedMsg is sent when a query is cancelled.
 type QueryAbortedMsg struct{}
 
+// StreamEventMsg is sent for each event received on an open stream.
+type StreamEventMsg struct {
+	Event  graphql.Event
+	Stream *graphql.Stream
+}
+
+// StreamDoneMsg is sent when a stream ends. Err is the context error when
+// the user stopped it.
+type StreamDoneMsg struct {
+	Err    error
+	Stream *graphql.Stream
+}
+
 // SchemaFetchedMsg is sent when schema introspection completes.
 type SchemaFetchedMsg struct {
 	Schema *schema.Schema
@@ -35,6 +52,39 @@ type EditorFinishedMsg struct {
 	Err     error
 }
 
+// suiteResultMsg carries the result of the current entry of a suite run.
+type suiteResultMsg struct {
+	Result suite.Result
+}
+
+// benchTickMsg refreshes the statistics of a running benchmark.
+type benchTickMsg struct{}
+
+// benchDoneMsg carries the final statistics of a benchmark.
+type benchDoneMsg struct {
+	Stats bench.Stats
+}
+
+// compareDoneMsg carries the responses of both environments of a
+// comparison, unless it was aborted.
+type compareDoneMsg struct {
+	A, B    compare.Side
+	Aborted bool
+}
+
+// pollResultMsg carries a response of a poll, or why the request failed.
+type pollResultMsg struct {
+	poller *poll.Poller
+	Result *graphql.Result
+	Err    error
+}
+
+// pollTickMsg fires when the next request of a poll is due.
+type pollTickMsg struct{ poller *poll.Poller }
+
+// pollStoppedMsg is sent when a poll is aborted.
+type pollStoppedMsg struct{ poller *poll.Poller }
+
 // statusClearMsg fires after a delay to clear the status bar error.
 type statusClearMsg struct{ gen int }
 
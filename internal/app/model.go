@@ -2,20 +2,29 @@ package app
 
 import (
 	"context"
-	"os"
 	"path/filepath"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/qraqula/qla/internal/bench"
 	"github.com/qraqula/qla/internal/builder"
+	"github.com/qraqula/qla/internal/compare"
 	"github.com/qraqula/qla/internal/config"
 	"github.com/qraqula/qla/internal/editor"
 	"github.com/qraqula/qla/internal/endpoint"
+	"github.com/qraqula/qla/internal/extract"
 	"github.com/qraqula/qla/internal/graphql"
 	"github.com/qraqula/qla/internal/history"
+	"github.com/qraqula/qla/internal/inspector"
 	"github.com/qraqula/qla/internal/overlay"
+	"github.com/qraqula/qla/internal/picker"
+	"github.com/qraqula/qla/internal/poll"
+	"github.com/qraqula/qla/internal/request"
 	"github.com/qraqula/qla/internal/results"
 	"github.com/qraqula/qla/internal/schema"
+	"github.com/qraqula/qla/internal/secret"
 	"github.com/qraqula/qla/internal/statusbar"
+	"github.com/qraqula/qla/internal/suite"
 	"github.com/qraqula/qla/internal/validate"
 	"github.com/qraqula/qla/internal/variables"
 )
@@ -25,6 +34,11 @@ type rightPanelMode int
 const (
 	modeResults rightPanelMode = iota
 	modeSchema
+	modeErrors
+	modeInspector
+	modeReport
+	modeBench
+	modeCompare
 )
 
 type Model struct {
@@ -32,11 +46,17 @@ type Model struct {
 	editor    editor.Model
 	variables variables.Model
 	results   results.Model
+	errors    results.ErrorsView
+	inspector inspector.Model
+	report    suite.Report
+	bench     bench.Model
+	compare   compare.Model
 	statusbar statusbar.Model
 
 	browser   schema.Browser
 	schemaAST *validate.SchemaAST
-	gqlClient *graphql.Client
+	clients   map[string]*graphql.Client // per environment name, built lazily
+	secrets   *secret.Resolver
 
 	histSidebar history.Sidebar
 	histStore   *history.Store
@@ -45,16 +65,65 @@ type Model struct {
 	configStore *config.Store
 	overlay     overlay.Model
 	builder     builder.Model
+	picker      picker.Model
+
+	// Why the workspace config is not used, shown in the status bar at
+	// startup
+	workspaceErr string
 
 	cancelQuery    context.CancelFunc
 	rightPanelMode rightPanelMode
 
+	// Suite run of a history folder in progress, one entry at a time
+	suiteRunner *suite.Runner
+	suiteCtx    context.Context
+
+	// Benchmark in progress
+	benchRun *bench.Bench
+	benchCtx context.Context
+
+	// Poll in progress, re-sending pollRequest until stopped, and the spec
+	// last typed in the poll prompt
+	poller      *poll.Poller
+	pollCtx     context.Context
+	pollRequest *request.Prepared
+	pollSpec    string
+
+	// Environment last compared with the active one
+	compareEnv string
+
+	// Operation last run or picked in a multi-operation document, and the
+	// choices of the picker when it is open for operations or environments
+	operationName string
+	pickerOps     []validate.Operation
+	pickerEnvs    []string
+	pickerSnaps   []history.Snapshot
+
+	// Entry last loaded from history: runs of the same request keep their
+	// responses with it
+	loadedEntry history.Entry
+
+	// Built-in {{$...}} values drawn for the last request, saved to history
+	dynamic map[string]string
+
+	// Extraction rules of the request in flight and the environment its
+	// values are stored in
+	extract    []extract.Rule
+	extractEnv string
+
 	focus        Panel
 	querying     bool
+	queryStart   time.Time
 	lastEndpoint string
 	width        int
 	height       int
 
+	// Set while an @defer/@stream response is open: each part replaces the
+	// results with the merged response instead of being appended as an event.
+	incremental bool
+	parts       int
+	partErrors  bool
+
 	// When true, auto-open builder after schema fetch completes
 	pendingBuilderOpen bool
 
@@ -82,15 +151,27 @@ func NewModel() Model {
 	ed := editor.New()
 	ed.Focus()
 
-	cfgDir := defaultConfigDir()
+	cfgDir := config.DefaultDir()
 	histDir := filepath.Join(cfgDir, "history")
 	store := history.NewStore(histDir)
-	_ = store.Load()
-	sidebarOpen := store.Meta.SidebarOpen
-
 	cfgStore := config.NewStore(cfgDir)
 	_ = cfgStore.Load()
 
+	// A trusted .qraqula workspace in the project shares environments,
+	// headers and collections over the personal config.
+	var workspaceErr error
+	if ws := config.FindWorkspace("."); ws != "" {
+		workspaceErr = cfgStore.UseWorkspace(ws)
+		if cfgStore.Trusts(ws) {
+			store.SetCollections(filepath.Join(ws, "collections"))
+		}
+	}
+	_ = store.Load()
+	sidebarOpen := store.Meta.SidebarOpen
+	secrets := secret.NewResolver(cfgDir)
+	ov := overlay.New()
+	ov.SetSecrets(secrets)
+
 	ep := endpoint.New()
 	vars := variables.New()
 
@@ -100,14 +181,14 @@ func NewModel() Model {
 		// Try to find the env in current config
 		cfgStore.Config.ActiveEnv = meta.LastEnvName
 		if env := cfgStore.Config.ActiveEnvironment(); env != nil {
-			ep.SetValue(env.Endpoint)
+			ep.SetValue(cfgStore.Config.Endpoint(env.Name))
 			ep.SetEnvName(env.Name)
 		} else {
 			// Env was deleted — fall back to no env
 			cfgStore.Config.ActiveEnv = ""
 		}
 	} else if env := cfgStore.Config.ActiveEnvironment(); env != nil {
-		ep.SetValue(env.Endpoint)
+		ep.SetValue(cfgStore.Config.Endpoint(env.Name))
 		ep.SetEnvName(env.Name)
 	}
 	if meta.LastEndpoint != "" {
@@ -119,23 +200,36 @@ func NewModel() Model {
 	if meta.LastVariables != "" {
 		vars.SetValue(meta.LastVariables)
 	}
+	cmpPanel := compare.NewModel()
+	cmpPanel.SetIgnore(cfgStore.Config.CompareIgnore)
 
-	return Model{
+	m := Model{
 		endpoint:    ep,
 		editor:      ed,
 		variables:   vars,
 		results:     results.New(80, 20),
+		errors:      results.NewErrorsView(),
+		inspector:   inspector.New(),
+		report:      suite.NewReport(),
+		bench:       bench.NewModel(),
+		compare:     cmpPanel,
 		statusbar:   statusbar.New(),
 		browser:     schema.NewBrowser(),
-		gqlClient:   graphql.NewClient(),
 		histStore:   store,
 		histSidebar: history.NewSidebar(store),
 		sidebarOpen: sidebarOpen,
 		configStore: cfgStore,
-		overlay:     overlay.New(),
+		secrets:     secrets,
+		overlay:     ov,
 		builder:     builder.New(),
+		picker:      picker.New(),
 		focus:       PanelEditor,
 	}
+	if workspaceErr != nil {
+		m.workspaceErr = "Workspace: " + workspaceErr.Error()
+		m.statusbar.SetError(m.workspaceErr)
+	}
+	return m
 }
 
 // NewModelWithStores creates a Model with custom stores (for testing).
@@ -146,27 +240,38 @@ func NewModelWithStores(histStore *history.Store, cfgStore *config.Store) Model
 	ep := endpoint.New()
 	if cfgStore != nil {
 		if env := cfgStore.Config.ActiveEnvironment(); env != nil {
-			ep.SetValue(env.Endpoint)
+			ep.SetValue(cfgStore.Config.Endpoint(env.Name))
 			ep.SetEnvName(env.Name)
 		}
 	} else {
 		cfgStore = config.NewStore("")
 	}
+	secrets := secret.NewResolver(cfgStore.Dir())
+	ov := overlay.New()
+	ov.SetSecrets(secrets)
+	cmpPanel := compare.NewModel()
+	cmpPanel.SetIgnore(cfgStore.Config.CompareIgnore)
 
 	return Model{
 		endpoint:    ep,
 		editor:      ed,
 		variables:   variables.New(),
 		results:     results.New(80, 20),
+		errors:      results.NewErrorsView(),
+		inspector:   inspector.New(),
+		report:      suite.NewReport(),
+		bench:       bench.NewModel(),
+		compare:     cmpPanel,
 		statusbar:   statusbar.New(),
 		browser:     schema.NewBrowser(),
-		gqlClient:   graphql.NewClient(),
 		histStore:   histStore,
 		histSidebar: history.NewSidebar(histStore),
 		sidebarOpen: histStore.Meta.SidebarOpen,
 		configStore: cfgStore,
-		overlay:     overlay.New(),
+		secrets:     secrets,
+		overlay:     ov,
 		builder:     builder.New(),
+		picker:      picker.New(),
 		focus:       PanelEditor,
 	}
 }
@@ -174,11 +279,3 @@ func NewModelWithStores(histStore *history.Store, cfgStore *config.Store) Model
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(m.editor.Focus(), m.autoFetchSchema())
 }
-
-func defaultConfigDir() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		home = os.Getenv("HOME")
-	}
-	return filepath.Join(home, ".config", "qraqula")
-}
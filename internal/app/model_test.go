@@ -1,17 +1,30 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/qraqula/qla/internal/auth"
+	"github.com/qraqula/qla/internal/bench"
 	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/extract"
 	"github.com/qraqula/qla/internal/graphql"
 	"github.com/qraqula/qla/internal/history"
+	"github.com/qraqula/qla/internal/overlay"
+	"github.com/qraqula/qla/internal/results"
 	"github.com/qraqula/qla/internal/schema"
 )
 
@@ -94,6 +107,33 @@ func TestToggleDocs(t *testing.T) {
 	}
 }
 
+func TestWorkspaceNotUsedShown(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	project := t.TempDir()
+	collection := filepath.Join(project, config.WorkspaceDir, "collections", "Shared")
+	if err := os.MkdirAll(collection, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	entry := `{"id":"shared-1","name":"users","query":"{ users }","endpoint":"https://evil.example"}`
+	if err := os.WriteFile(filepath.Join(collection, "shared-1.json"), []byte(entry), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+
+	m := NewModel()
+	m, _ = updateModel(m, tea.WindowSizeMsg{Width: 200, Height: 40})
+	if !strings.Contains(m.statusbar.View(), "is not trusted; run qla trust") {
+		t.Errorf("expected the untrusted workspace reported, got %q", m.statusbar.View())
+	}
+	if entries := m.histStore.AllEntries(); len(entries) != 0 {
+		t.Errorf("expected no collection entries from an untrusted workspace, got %v", entries)
+	}
+	m, _ = updateModel(m, SchemaFetchedMsg{Schema: &schema.Schema{}})
+	if !strings.Contains(m.statusbar.View(), "not trusted") {
+		t.Errorf("expected the report kept after the schema loaded, got %q", m.statusbar.View())
+	}
+}
+
 func TestSchemaFetchedMsg(t *testing.T) {
 	m := NewModel()
 	m, _ = updateModel(m, tea.WindowSizeMsg{Width: 120, Height: 40})
@@ -419,3 +459,920 @@ func TestSidebarTabCycleSkipsHistoryWhenClosed(t *testing.T) {
 		t.Error("expected tab to skip history panel when sidebar is closed")
 	}
 }
+
+func TestStreamEventsAppendToResults(t *testing.T) {
+	m := newTestModel(t)
+	m.editor.SetValue("subscription { tick }")
+	m.querying = true
+
+	for i := 0; i < 2; i++ {
+		m, _ = updateModel(m, StreamEventMsg{Event: graphql.Event{
+			Response: graphql.Response{Data: json.RawMessage(`{"tick":1}`)},
+			Received: time.Now(),
+		}})
+	}
+	if m.results.EventCount() != 2 {
+		t.Errorf("expected 2 events in results, got %d", m.results.EventCount())
+	}
+	if !m.querying {
+		t.Error("expected subscription to stay active while events arrive")
+	}
+
+	m, _ = updateModel(m, StreamDoneMsg{Err: context.Canceled})
+	if m.querying {
+		t.Error("expected querying to be false after stream ends")
+	}
+	if !strings.Contains(m.statusbar.View(), "2 events") {
+		t.Errorf("expected final event count in status bar, got %q", m.statusbar.View())
+	}
+	if len(m.histStore.AllEntries()) != 1 {
+		t.Error("expected subscription to be saved to history")
+	}
+}
+
+func TestIncrementalPartsReplaceResults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"user":{"id":"1","name":"Ada"}}}`)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue(`{ user { id ... @defer { name } } }`)
+
+	m, cmd := m.executeQuery()
+	if !m.incremental {
+		t.Fatal("expected @defer query to run as an incremental stream")
+	}
+	ev, ok := cmd().(StreamEventMsg)
+	if !ok {
+		t.Fatal("expected first part as StreamEventMsg")
+	}
+
+	m, cmd = updateModel(m, ev)
+	m, _ = updateModel(m, StreamEventMsg{Event: ev.Event, Stream: ev.Stream})
+	if m.results.EventCount() != 0 {
+		t.Error("expected parts to replace results rather than append events")
+	}
+	if !strings.Contains(m.results.Content(), "Ada") {
+		t.Errorf("expected merged data in results, got %q", m.results.Content())
+	}
+
+	done, ok := cmd().(StreamDoneMsg)
+	if !ok {
+		t.Fatal("expected StreamDoneMsg after the last part")
+	}
+	m, _ = updateModel(m, done)
+	if m.querying || m.incremental {
+		t.Error("expected stream state to reset when done")
+	}
+	if !strings.Contains(m.statusbar.View(), "200") {
+		t.Errorf("expected final status in status bar, got %q", m.statusbar.View())
+	}
+}
+
+func TestExecutePicksOperation(t *testing.T) {
+	var sent []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		sent = append(sent, req.OperationName)
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("query A { a }\n\nquery B { b }")
+
+	// Outside the editor there is no cursor to go by: ask.
+	m.setFocus(PanelResults)
+	m, cmd := m.executeQuery()
+	if !m.picker.IsOpen() || cmd != nil {
+		t.Fatal("expected operation picker to open")
+	}
+	m, cmd = updateModel(m, tea.KeyPressMsg{Code: -1, Text: "2"})
+	m, cmd = updateModel(m, cmd())
+	if !m.querying || cmd == nil {
+		t.Fatal("expected picked operation to run")
+	}
+	cmd()
+
+	// In the editor the operation under the cursor runs directly.
+	m.querying = false
+	m.setFocus(PanelEditor)
+	m.editor.SetValue("query A { a }\n\nquery B { b }")
+	m, cmd = m.executeQuery()
+	if m.picker.IsOpen() || cmd == nil {
+		t.Fatal("expected operation under cursor to run without picker")
+	}
+	cmd()
+
+	if len(sent) != 2 || sent[0] != "B" || sent[1] != "B" {
+		t.Errorf("expected operationName B twice, got %v", sent)
+	}
+}
+
+func TestErrorsViewNavigation(t *testing.T) {
+	m := newTestModel(t)
+	m.editor.SetValue("{\n  user {\n    secret\n  }\n}")
+	m, _ = updateModel(m, QueryResultMsg{Result: &graphql.Result{
+		StatusCode: 200,
+		Response: graphql.Response{
+			Data: json.RawMessage(`{"user":{"secret":null}}`),
+			Errors: []graphql.Error{{
+				Message:    "not allowed",
+				Locations:  []graphql.Location{{Line: 3, Column: 5}},
+				Path:       []any{"user", "secret"},
+				Extensions: map[string]any{"code": "FORBIDDEN"},
+			}},
+		},
+	}})
+	m.setFocus(PanelResults)
+
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: -1, Text: "e"})
+	if m.rightPanelMode != modeErrors {
+		t.Fatal("expected e to open the errors view")
+	}
+	if view := m.errors.View(); !strings.Contains(view, "FORBIDDEN") || !strings.Contains(view, "user.secret") {
+		t.Errorf("expected code and path in errors view, got %q", view)
+	}
+
+	m, cmd := updateModel(m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m, _ = updateModel(m, cmd())
+	if m.rightPanelMode != modeResults {
+		t.Error("expected enter to follow the path into the result")
+	}
+
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: -1, Text: "e"})
+	m, cmd = updateModel(m, tea.KeyPressMsg{Code: -1, Text: "g"})
+	m, _ = updateModel(m, cmd())
+	if m.focus != PanelEditor || m.editor.CursorLine() != 3 {
+		t.Errorf("expected editor focused on line 3, got focus %v line %d", m.focus, m.editor.CursorLine())
+	}
+
+	// A clean response leaves the errors view.
+	m.setFocus(PanelResults)
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: -1, Text: "e"})
+	m, _ = updateModel(m, QueryResultMsg{Result: &graphql.Result{StatusCode: 200, Response: graphql.Response{Data: json.RawMessage(`{}`)}}})
+	if m.rightPanelMode != modeResults || m.errors.Count() != 0 {
+		t.Error("expected errors view to close when the new response has no errors")
+	}
+}
+
+func TestInspectorView(t *testing.T) {
+	m := newTestModel(t)
+	m.setFocus(PanelResults)
+
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: -1, Text: "i"})
+	if m.rightPanelMode != modeResults {
+		t.Fatal("expected inspector to stay closed before the first request")
+	}
+
+	m, _ = updateModel(m, QueryResultMsg{Result: &graphql.Result{
+		StatusCode: 200,
+		Response:   graphql.Response{Data: json.RawMessage(`{}`)},
+		Exchange: &graphql.Exchange{
+			Method:          "POST",
+			URL:             "http://localhost/graphql",
+			RequestHeaders:  http.Header{"Authorization": {"Bearer s3cr3t"}},
+			Status:          "200 OK",
+			ResponseHeaders: http.Header{"X-Trace-Id": {"abc123"}},
+		},
+	}})
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: -1, Text: "i"})
+	if m.rightPanelMode != modeInspector {
+		t.Fatal("expected i to open the inspector")
+	}
+	view := m.View().Content
+	if !strings.Contains(view, "abc123") || strings.Contains(view, "s3cr3t") {
+		t.Errorf("expected response headers shown and secrets masked, got %q", view)
+	}
+
+	m, cmd := updateModel(m, tea.KeyPressMsg{Code: tea.KeyEscape})
+	m, _ = updateModel(m, cmd())
+	if m.rightPanelMode != modeResults {
+		t.Error("expected esc to return to the result")
+	}
+}
+
+func TestPersistedQueryNoteInStatusBar(t *testing.T) {
+	m := newTestModel(t)
+	m, _ = updateModel(m, QueryResultMsg{Result: &graphql.Result{
+		StatusCode:     200,
+		Response:       graphql.Response{Data: json.RawMessage(`{}`)},
+		PersistedQuery: graphql.PersistedMiss,
+	}})
+	if !strings.Contains(m.statusbar.View(), "APQ miss") {
+		t.Errorf("expected APQ miss in status bar, got %q", m.statusbar.View())
+	}
+}
+
+func TestConnectionSettingsErrors(t *testing.T) {
+	m := newTestModel(t)
+	m.configStore.Config.Environments = []config.Environment{
+		{Name: "dev", Endpoint: "http://localhost:1", Timeout: "soon"},
+	}
+	m.configStore.Config.ActiveEnv = "dev"
+	m.endpoint.SetValue("http://localhost:1")
+	m.editor.SetValue("{ a }")
+
+	m, _ = m.executeQuery()
+	if m.querying {
+		t.Fatal("expected query not to start with an invalid timeout")
+	}
+	if !strings.Contains(m.statusbar.View(), `invalid timeout "soon"`) {
+		t.Errorf("expected connection error in status bar, got %q", m.statusbar.View())
+	}
+
+	// Editing the environment drops the cached client.
+	m.configStore.Config.Environments[0].Timeout = "5s"
+	m, _ = updateModel(m, overlay.ConfigChangedMsg{Config: m.configStore.Config})
+	if _, err := m.client(); err != nil {
+		t.Errorf("expected client after fixing timeout, got %v", err)
+	}
+}
+
+func TestEnvironmentVariablesMerged(t *testing.T) {
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		sent = req.Variables
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.configStore.Config.Environments = []config.Environment{{
+		Name:      "dev",
+		Endpoint:  srv.URL,
+		Variables: `{"tenantId": "t-1", "region": "eu", "unused": 1}`,
+	}}
+	m.configStore.Config.ActiveEnv = "dev"
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("query Q($tenantId: ID!, $region: String) { a }")
+	m.variables.SetValue(`{"region": "us"}`)
+
+	m, cmd := m.executeQuery()
+	if cmd == nil {
+		t.Fatal("expected query to run")
+	}
+	cmd()
+	if len(sent) != 2 || sent["tenantId"] != "t-1" || sent["region"] != "us" {
+		t.Errorf("expected env tenantId and panel region, got %v", sent)
+	}
+	if view := m.variables.View(); !strings.Contains(view, "env: tenantId") {
+		t.Errorf("expected env variables listed in panel title, got %q", view)
+	}
+
+	m.configStore.Config.Environments[0].Variables = `{"tenantId": `
+	if err := m.lintVariables(m.variables.Value()); err == nil || !strings.Contains(err.Error(), "dev variables") {
+		t.Errorf("expected environment variables error, got %v", err)
+	}
+}
+
+func TestPlaceholdersResolved(t *testing.T) {
+	var auth string
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		auth, sent = r.Header.Get("Authorization"), req.Variables
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	t.Setenv("QLA_TEST_TOKEN", "s3cr3t")
+
+	m := newTestModel(t)
+	m.configStore.Config.Environments = []config.Environment{{
+		Name:     "dev",
+		Endpoint: "{{base}}/graphql",
+		Values:   map[string]string{"base": srv.URL, "tenant": "t-1"},
+		Headers:  []config.Header{{Key: "Authorization", Value: "Bearer {{env.QLA_TEST_TOKEN}}", Enabled: true}},
+	}}
+	m.configStore.Config.ActiveEnv = "dev"
+	m.endpoint.SetValue("{{base}}/graphql")
+	m.editor.SetValue("query Q($tenant: ID!) { a }")
+	m.variables.SetValue(`{"tenant": "{{tenant}}"}`)
+
+	m, cmd := m.executeQuery()
+	if cmd == nil {
+		t.Fatal("expected query to run")
+	}
+	cmd()
+	if auth != "Bearer s3cr3t" || sent["tenant"] != "t-1" {
+		t.Errorf("expected placeholders resolved, got auth %q vars %v", auth, sent)
+	}
+
+	m.querying = false
+	m.variables.SetValue(`{"tenant": "{{tenantId}}"}`)
+	m, cmd = m.executeQuery()
+	if m.querying || !strings.Contains(m.statusbar.View(), "unresolved {{tenantId}}") {
+		t.Errorf("expected unresolved placeholder to block the query, got %q", m.statusbar.View())
+	}
+	if err := m.lintPlaceholders(); err == nil || !strings.Contains(err.Error(), "{{tenantId}}") {
+		t.Errorf("expected lint error for {{tenantId}}, got %v", err)
+	}
+}
+
+func TestDynamicValuesSharedAndRecorded(t *testing.T) {
+	var key string
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		key, sent = r.Header.Get("Idempotency-Key"), req.Variables
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.configStore.Config.GlobalHeaders = []config.Header{{Key: "Idempotency-Key", Value: "{{$uuid}}", Enabled: true}}
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("mutation M($id: ID!, $email: String!) { a }")
+	m.variables.SetValue(`{"id": "{{$uuid}}", "email": "user-{{$randomString 8}}@example.com"}`)
+
+	run := func() {
+		t.Helper()
+		var cmd tea.Cmd
+		m, cmd = m.executeQuery()
+		if cmd == nil {
+			t.Fatal("expected query to run")
+		}
+		m, _ = updateModel(m, cmd())
+	}
+	run()
+	if key == "" || sent["id"] != key {
+		t.Errorf("expected the same uuid in header and variables, got %q and %v", key, sent["id"])
+	}
+	first := key
+	run()
+	if key == first {
+		t.Error("expected a fresh uuid per execution")
+	}
+
+	all := m.histStore.AllEntries()
+	if len(all) != 2 {
+		t.Fatalf("expected both runs in history, got %d", len(all))
+	}
+	uuids := map[string]bool{all[0].Dynamic["$uuid"]: true, all[1].Dynamic["$uuid"]: true}
+	if !uuids[first] || !uuids[key] {
+		t.Errorf("expected the sent uuids recorded, got %v and %v", all[0].Dynamic, all[1].Dynamic)
+	}
+	if len(all[0].Dynamic["$randomString 8"]) != 8 {
+		t.Errorf("expected recorded random string, got %v", all[0].Dynamic)
+	}
+
+	// A loaded entry is sent again with its recorded values.
+	loaded := all[0]
+	if loaded.Dynamic["$uuid"] != first {
+		loaded = all[1]
+	}
+	m, _ = updateModel(m, history.LoadEntryMsg{Entry: loaded})
+	if !strings.Contains(m.statusbar.View(), "Replaying {{$randomString 8}}=") {
+		t.Errorf("expected the replayed values shown, got %q", m.statusbar.View())
+	}
+	run()
+	if key != first || sent["id"] != first {
+		t.Errorf("expected the entry's uuid replayed, got %q and %v", key, sent["id"])
+	}
+	if n := len(m.histStore.AllEntries()); n != 2 {
+		t.Errorf("expected the replay kept with its entry, got %d entries", n)
+	}
+}
+
+func TestExtractChainsIntoHeaders(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"data":{"login":{"token":"tok-1","user":{"id":7}}}}`)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.configStore.Config.Environments = []config.Environment{{
+		Name:     "dev",
+		Endpoint: srv.URL,
+		Values:   map[string]string{"authToken": "none"},
+		Extract:  []extract.Rule{{Path: "data.login.user.id", Name: "userId"}},
+		Headers:  []config.Header{{Key: "Authorization", Value: "Bearer {{authToken}}", Enabled: true}},
+	}}
+	m.configStore.Config.ActiveEnv = "dev"
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("# qla:extract data.login.token -> authToken\nmutation { login { token user { id } } }")
+
+	m, cmd := m.executeQuery()
+	if cmd == nil {
+		t.Fatal("expected query to run")
+	}
+	m, _ = updateModel(m, cmd())
+	if auth != "Bearer none" {
+		t.Errorf("expected the old token on the first request, got %q", auth)
+	}
+	lookup := m.configStore.Config.Lookup()
+	if token, _ := lookup("authToken"); token != "tok-1" {
+		t.Errorf("expected the extracted token kept, got %q", token)
+	}
+	if id, _ := lookup("userId"); id != "7" {
+		t.Errorf("expected the extracted id kept, got %q", id)
+	}
+	// Extracted values last for the session only.
+	saved, _ := json.Marshal(m.configStore.Config)
+	if values := m.configStore.Config.Environments[0].Values; values["authToken"] != "none" || strings.Contains(string(saved), "tok-1") {
+		t.Errorf("expected extracted values kept out of the config, got %v", values)
+	}
+	if !strings.Contains(m.statusbar.View(), "set authToken, userId") {
+		t.Errorf("expected updated variables in status bar, got %q", m.statusbar.View())
+	}
+
+	m, cmd = m.executeQuery()
+	cmd()
+	if auth != "Bearer tok-1" {
+		t.Errorf("expected the extracted token on the next request, got %q", auth)
+	}
+
+	m.querying = false
+	m.editor.SetValue("# qla:extract data.missing -> x\n{ a }")
+	m, cmd = m.executeQuery()
+	m, _ = updateModel(m, cmd())
+	if !strings.Contains(m.statusbar.View(), "data.missing not found") {
+		t.Errorf("expected missing path reported, got %q", m.statusbar.View())
+	}
+}
+
+func TestAuthTokenSentWithQueriesAndSchema(t *testing.T) {
+	var tokenCalls int
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls++
+		fmt.Fprint(w, `{"access_token":"tok","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer tokenSrv.Close()
+	var auths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	t.Setenv("QLA_TEST_SECRET", "s3cr3t")
+
+	m := newTestModel(t)
+	m.configStore.Config.Environments = []config.Environment{{
+		Name:     "dev",
+		Endpoint: srv.URL,
+		Auth: &auth.Config{
+			Type:         auth.TypeClientCredentials,
+			TokenURL:     tokenSrv.URL,
+			ClientID:     "qla",
+			ClientSecret: "{{env.QLA_TEST_SECRET}}",
+		},
+	}}
+	m.configStore.Config.ActiveEnv = "dev"
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("{ a }")
+
+	m, cmd := m.executeQuery()
+	cmd()
+	m, cmd = m.fetchSchema()
+	cmd()
+	if len(auths) != 2 || auths[0] != "Bearer tok" || auths[1] != "Bearer tok" {
+		t.Errorf("expected the token on query and introspection, got %v", auths)
+	}
+	if tokenCalls != 1 {
+		t.Errorf("expected the token cached, got %d token requests", tokenCalls)
+	}
+
+	m.configStore.Config.Environments[0].Auth.ClientSecret = "{{missing}}"
+	m.clients = nil
+	m.querying = false
+	m, _ = m.executeQuery()
+	if m.querying || !strings.Contains(m.statusbar.View(), "auth: unresolved {{missing}}") {
+		t.Errorf("expected auth error, got %q", m.statusbar.View())
+	}
+}
+
+func TestSecretHeadersResolvedInMemory(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	ref, err := m.secrets.Store("dev.authorization", "Bearer s3cr3t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.configStore.Config.Environments = []config.Environment{{
+		Name:     "dev",
+		Endpoint: srv.URL,
+		Headers:  []config.Header{{Key: "Authorization", Value: ref, Enabled: true}},
+	}}
+	m.configStore.Config.ActiveEnv = "dev"
+	if err := m.configStore.Save(); err != nil {
+		t.Fatal(err)
+	}
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("{ a }")
+
+	m, cmd := m.executeQuery()
+	cmd()
+	if auth != "Bearer s3cr3t" {
+		t.Errorf("expected the secret sent, got %q", auth)
+	}
+	path := filepath.Join(m.configStore.Dir(), "config.json")
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "s3cr3t") {
+		t.Error("expected the secret kept out of config.json")
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("expected config.json mode 0600, got %v", info.Mode().Perm())
+	}
+
+	m.querying = false
+	m.configStore.Config.Environments[0].Headers[0].Value = "secret:prod.authorization"
+	m, _ = m.executeQuery()
+	if m.querying || !strings.Contains(m.statusbar.View(), `Secrets: header Authorization: no secret "prod.authorization"`) {
+		t.Errorf("expected secret error, got %q", m.statusbar.View())
+	}
+}
+
+func TestRunFolderSuite(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		fmt.Fprintf(w, `{"data":{"op":%q}}`, req.OperationName)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.configStore.Config.Environments = []config.Environment{{Name: "dev", Endpoint: srv.URL}}
+	m.configStore.Config.ActiveEnv = "dev"
+	_ = m.histStore.CreateFolder("Smoke")
+	now := time.Now()
+	for i, q := range []string{
+		"query A { op }",
+		"# qla:assert data.op == \"A\"\nquery B { op }",
+	} {
+		e := history.Entry{ID: history.GenerateID(), Name: string(rune('A' + i)), Query: q, CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		_ = m.histStore.AddEntry(e)
+		_ = m.histStore.MoveEntry(e.ID, "Smoke")
+	}
+
+	m, cmd := updateModel(m, history.RunFolderMsg{Folder: "Smoke"})
+	if m.rightPanelMode != modeReport || !m.querying || cmd == nil {
+		t.Fatalf("expected the suite to start in the report panel")
+	}
+	for m.querying {
+		m, cmd = updateModel(m, cmd())
+	}
+	if m.querying || m.suiteRunner != nil {
+		t.Error("expected the run to be over")
+	}
+	results := m.report.Results()
+	if len(results) != 2 || !results[0].Passed() || results[1].Passed() {
+		t.Fatalf("expected A to pass and B to fail, got %+v", results)
+	}
+	view := ansi.Strip(m.report.View())
+	for _, want := range []string{"Smoke", "on dev", "✓ A", "✗ B", `data.op == "A": got "B"`, "1 passed, 1 failed"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in report:\n%s", want, view)
+		}
+	}
+	if !strings.Contains(m.statusbar.View(), "Smoke: 1 passed, 1 failed") {
+		t.Errorf("expected the summary in the status bar, got %q", m.statusbar.View())
+	}
+
+	m, cmd = updateModel(m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected enter to load the entry")
+	}
+	m, _ = updateModel(m, cmd())
+	if m.editor.Value() != "# qla:assert data.op == \"A\"\nquery B { op }" {
+		t.Errorf("expected entry B loaded, got %q", m.editor.Value())
+	}
+}
+
+func TestRunFolderAbort(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	_ = m.histStore.CreateFolder("Slow")
+	e := history.Entry{ID: history.GenerateID(), Name: "slow", Query: "{ a }", Endpoint: srv.URL, CreatedAt: time.Now()}
+	_ = m.histStore.AddEntry(e)
+	_ = m.histStore.MoveEntry(e.ID, "Slow")
+
+	m, cmd := updateModel(m, history.RunFolderMsg{Folder: "Slow"})
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl})
+	m, _ = updateModel(m, cmd())
+	if m.querying || len(m.report.Results()) != 0 {
+		t.Errorf("expected the run aborted without results, got %+v", m.report.Results())
+	}
+	if !strings.Contains(m.report.View(), "1 not run") {
+		t.Errorf("expected the entry marked as not run:\n%s", m.report.View())
+	}
+}
+
+func TestBenchPanel(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%4 == 0 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		fmt.Fprint(w, `{"data":{"ping":true}}`)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("query Ping { ping }")
+	m.setFocus(PanelResults)
+
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: 'b', Text: "b"})
+	if m.rightPanelMode != modeBench {
+		t.Fatalf("expected b to open the bench panel, got mode %v", m.rightPanelMode)
+	}
+	for range len(bench.DefaultOptions.String()) {
+		m, _ = updateModel(m, tea.KeyPressMsg{Code: tea.KeyBackspace})
+	}
+	for _, r := range "c=2 n=8" {
+		m, _ = updateModel(m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m, cmd := updateModel(m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected enter to start the benchmark")
+	}
+	start := cmd()
+	if opts := start.(bench.StartMsg).Options; opts != (bench.Options{Concurrency: 2, Requests: 8}) {
+		t.Fatalf("expected the settings typed, got %+v", opts)
+	}
+	m, cmd = updateModel(m, start)
+	if !m.querying || !m.bench.Running() || cmd == nil {
+		t.Fatal("expected the benchmark running")
+	}
+	// The first command of the batch runs the benchmark, the second ticks.
+	m, _ = updateModel(m, cmd().(tea.BatchMsg)[0]())
+	if m.querying || m.bench.Running() || m.benchRun != nil {
+		t.Error("expected the benchmark over")
+	}
+	if s := m.bench.Stats(); s.Requests != 8 || s.Errors["HTTP 502"] != 2 {
+		t.Errorf("expected 8 requests with 2 failures, got %d %v", s.Requests, s.Errors)
+	}
+	view := ansi.Strip(m.bench.View())
+	for _, want := range []string{"Bench  Ping", "8/8", "p50", "2 (25.0%)", "2× HTTP 502"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the panel:\n%s", want, view)
+		}
+	}
+	if !strings.Contains(m.statusbar.View(), "Bench: 8 requests, 2 errors") {
+		t.Errorf("expected the summary in the status bar, got %q", m.statusbar.View())
+	}
+
+	m, cmd = updateModel(m, tea.KeyPressMsg{Code: tea.KeyEscape})
+	m, _ = updateModel(m, cmd())
+	if m.rightPanelMode != modeResults {
+		t.Error("expected esc to return to the results")
+	}
+}
+
+func TestPoll(t *testing.T) {
+	var calls atomic.Int32
+	var mu sync.Mutex
+	ids := make(map[string]bool)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		ids[fmt.Sprint(req.Variables["id"])] = true
+		mu.Unlock()
+		switch calls.Add(1) {
+		case 1, 2:
+			fmt.Fprint(w, `{"data":{"job":{"status":"RUNNING","progress":10}}}`)
+		case 3:
+			fmt.Fprint(w, `{"data":{"job":{"status":"RUNNING","progress":60}}}`)
+		default:
+			fmt.Fprint(w, `{"data":{"job":{"status":"DONE","progress":100}}}`)
+		}
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("query Job($id: ID) { job(id: $id) { status progress } }")
+	m.variables.SetValue(`{"id": "{{$uuid}}"}`)
+	m.setFocus(PanelResults)
+
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: 'w', Text: "w"})
+	if !m.results.Prompting() {
+		t.Fatal("expected w to open the poll prompt")
+	}
+	m, cmd := updateModel(m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if msg, ok := cmd().(results.PollMsg); !ok || msg.Spec != "every 2s" {
+		t.Fatalf("expected the default spec, got %#v", msg)
+	}
+
+	m, cmd = updateModel(m, results.PollMsg{Spec: `every 100ms until data.job.status == "DONE"`})
+	if !m.querying || m.poller == nil {
+		t.Fatal("expected the poll running")
+	}
+	var view string
+	for m.querying {
+		m, cmd = updateModel(m, cmd())
+		if m.poller != nil && m.poller.Count() == 3 {
+			view = m.results.View()
+		}
+	}
+	if calls.Load() != 4 {
+		t.Errorf("expected the poll to stop at DONE after 4 requests, got %d", calls.Load())
+	}
+	mu.Lock()
+	if len(ids) != 4 {
+		t.Errorf("expected a new {{$uuid}} for every request, got %v", ids)
+	}
+	mu.Unlock()
+	if !strings.Contains(ansi.Strip(view), "poll #3 · 1 changed · 1 updates") || !strings.Contains(view, "\x1b[44m") {
+		t.Errorf("expected the progress change highlighted and counted:\n%s", view)
+	}
+	if !strings.Contains(ansi.Strip(m.results.View()), "poll #4 · 2 changed · 2 updates") {
+		t.Errorf("expected the last response counted:\n%s", m.results.View())
+	}
+	if !strings.Contains(m.statusbar.View(), `Poll done after 4 requests: data.job.status == "DONE"`) {
+		t.Errorf("expected the stop reason in the status bar, got %q", m.statusbar.View())
+	}
+	if m.pollSpec != `every 100ms until data.job.status == "DONE"` {
+		t.Errorf("expected the spec remembered for the prompt, got %q", m.pollSpec)
+	}
+
+	// Without a condition the poll runs until aborted.
+	m, cmd = updateModel(m, results.PollMsg{Spec: "every 1m"})
+	m, wait := updateModel(m, cmd())
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl})
+	m, _ = updateModel(m, wait())
+	if m.querying || m.poller != nil {
+		t.Error("expected ctrl+c to stop the poll")
+	}
+	if !strings.Contains(m.statusbar.View(), "Poll stopped after 1 requests") {
+		t.Errorf("expected the poll reported stopped, got %q", m.statusbar.View())
+	}
+}
+
+func TestCompare(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Env") == "prod" {
+			fmt.Fprint(w, `{"data":{"user":{"name":"Anne","email":"a@x","updatedAt":"10:01"}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"user":{"name":"Ann","phone":"123","updatedAt":"10:00"}}}`)
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	env := func(name string) config.Environment {
+		return config.Environment{Name: name, Endpoint: srv.URL, Headers: []config.Header{{Key: "X-Env", Value: name, Enabled: true}}}
+	}
+	m.configStore.Config.Environments = []config.Environment{env("staging"), env("prod")}
+	m.configStore.Config.ActiveEnv = "staging"
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("query User { user { name email phone updatedAt } }")
+	m.setFocus(PanelResults)
+
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: 'c', Text: "c"})
+	if !m.picker.IsOpen() || len(m.pickerEnvs) != 1 || m.pickerEnvs[0] != "prod" {
+		t.Fatalf("expected c to offer the other environments, got %v", m.pickerEnvs)
+	}
+	m, cmd := updateModel(m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m, cmd = updateModel(m, cmd())
+	if !m.querying || m.rightPanelMode != modeCompare {
+		t.Fatal("expected the comparison running in the compare panel")
+	}
+	m, _ = updateModel(m, cmd())
+	if m.querying {
+		t.Fatal("expected the comparison done")
+	}
+	changes, ignored := m.compare.Changes()
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Kind.String()+" "+c.PathString())
+	}
+	want := "added data.user.email, changed data.user.name, removed data.user.phone, changed data.user.updatedAt"
+	if strings.Join(got, ", ") != want || ignored != 0 {
+		t.Errorf("got %q, %d ignored", strings.Join(got, ", "), ignored)
+	}
+	view := ansi.Strip(m.compare.View())
+	for _, s := range []string{"staging  200", "prod  200", "~ data.user.name", `"Ann"`, `"Anne"`, "(missing)", "4 differences"} {
+		if !strings.Contains(view, s) {
+			t.Errorf("expected %q in the compare panel:\n%s", s, view)
+		}
+	}
+	if !strings.Contains(m.statusbar.View(), "Compare: 4 differences") {
+		t.Errorf("expected the differences counted, got %q", m.statusbar.View())
+	}
+
+	// Ignored paths are left out and kept in the config.
+	m, _ = updateModel(m, tea.KeyPressMsg{Code: 'i', Text: "i"})
+	if !m.compare.Prompting() {
+		t.Fatal("expected i to open the ignore prompt")
+	}
+	for _, r := range "updatedAt" {
+		m, _ = updateModel(m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m, cmd = updateModel(m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m, _ = updateModel(m, cmd())
+	if changes, ignored := m.compare.Changes(); len(changes) != 3 || ignored != 1 {
+		t.Errorf("expected updatedAt ignored, got %d changes and %d ignored", len(changes), ignored)
+	}
+	if ig := m.configStore.Config.CompareIgnore; len(ig) != 1 || ig[0] != "updatedAt" {
+		t.Errorf("expected the ignore list saved, got %v", ig)
+	}
+
+	// Enter runs the comparison again with the same environment.
+	m, cmd = updateModel(m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m, cmd = updateModel(m, cmd())
+	if !m.querying || m.compareEnv != "prod" {
+		t.Fatal("expected enter to compare with prod again")
+	}
+	m, _ = updateModel(m, cmd())
+
+	m, cmd = updateModel(m, tea.KeyPressMsg{Code: tea.KeyEscape})
+	m, _ = updateModel(m, cmd())
+	if m.rightPanelMode != modeResults {
+		t.Error("expected esc to leave the compare panel")
+	}
+
+	// Errors preparing the other side name it.
+	m.configStore.Config.Environments[1].Headers[0].Value = "{{missing}}"
+	m, _ = m.startCompare("prod")
+	if m.querying || !strings.Contains(m.statusbar.View(), "Compare: prod: placeholders: unresolved {{missing}}") {
+		t.Errorf("expected the error of prod reported, got %q", m.statusbar.View())
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":{"run":%d}}`, calls.Add(1))
+	}))
+	defer srv.Close()
+
+	m := newTestModel(t)
+	m.endpoint.SetValue(srv.URL)
+	m.editor.SetValue("{ run }")
+	var cmd tea.Cmd
+	for range 2 {
+		m, cmd = m.executeQuery()
+		m, _ = updateModel(m, cmd())
+	}
+	// Re-running the same request keeps both responses with one entry.
+	entry, _ := m.histStore.Newest()
+	if len(m.histStore.AllEntries()) != 1 {
+		t.Fatalf("expected one entry, got %d", len(m.histStore.AllEntries()))
+	}
+	snaps := m.histStore.Snapshots(entry.ID)
+	if len(snaps) != 2 || snaps[0].Status != 200 || snaps[0].Size == 0 {
+		t.Fatalf("expected two snapshots, got %+v", snaps)
+	}
+
+	m.editor.SetValue("{ other }")
+	m, _ = updateModel(m, history.ShowRunsMsg{Entry: entry})
+	if !m.picker.IsOpen() || len(m.pickerSnaps) != 2 {
+		t.Fatal("expected the runs of the entry listed")
+	}
+	m, cmd = updateModel(m, tea.KeyPressMsg{Code: -1, Text: "2"})
+	m, _ = updateModel(m, cmd())
+	view := ansi.Strip(m.results.View())
+	if !strings.Contains(view, `"run": 1`) || !strings.Contains(view, "saved ") {
+		t.Errorf("expected the first response shown as saved:\n%s", view)
+	}
+	if calls.Load() != 2 || m.focus != PanelResults {
+		t.Errorf("expected the snapshot shown without a request, got %d requests", calls.Load())
+	}
+
+	// A loaded entry keeps the responses of its runs, even when it is not
+	// the most recent one.
+	m.editor.SetValue("{ other }")
+	m, cmd = m.executeQuery()
+	m, _ = updateModel(m, cmd())
+	m, _ = updateModel(m, history.LoadEntryMsg{Entry: entry})
+	m, cmd = m.executeQuery()
+	m, _ = updateModel(m, cmd())
+	if n := len(m.histStore.Snapshots(entry.ID)); n != 3 {
+		t.Errorf("expected the run of the loaded entry saved with it, got %d snapshots", n)
+	}
+
+	m.configStore.Config.Snapshots = "0"
+	m, cmd = m.executeQuery()
+	m, _ = updateModel(m, cmd())
+	if n := len(m.histStore.Snapshots(entry.ID)); n != 3 {
+		t.Errorf("expected no snapshot when disabled, got %d", n)
+	}
+
+	m, _ = updateModel(m, history.ShowRunsMsg{Entry: history.Entry{ID: "none", Name: "gone"}})
+	if m.picker.IsOpen() || !strings.Contains(m.statusbar.View(), "No saved responses for gone") {
+		t.Errorf("expected no runs reported, got %q", m.statusbar.View())
+	}
+}
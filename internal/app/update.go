@@ -1,22 +1,41 @@
 package app
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
+	"github.com/qraqula/qla/internal/bench"
 	"github.com/qraqula/qla/internal/builder"
+	"github.com/qraqula/qla/internal/compare"
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/extract"
 	"github.com/qraqula/qla/internal/format"
 	"github.com/qraqula/qla/internal/graphql"
 	"github.com/qraqula/qla/internal/history"
+	"github.com/qraqula/qla/internal/inspector"
+	"github.com/qraqula/qla/internal/interp"
+	"github.com/qraqula/qla/internal/jsondiff"
 	"github.com/qraqula/qla/internal/overlay"
+	"github.com/qraqula/qla/internal/picker"
+	"github.com/qraqula/qla/internal/poll"
+	"github.com/qraqula/qla/internal/request"
+	"github.com/qraqula/qla/internal/results"
 	"github.com/qraqula/qla/internal/schema"
+	"github.com/qraqula/qla/internal/suite"
 	"github.com/qraqula/qla/internal/validate"
 )
 
@@ -39,10 +58,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.cancelQuery = nil
 		r := msg.Result
 
+		m.setErrors(r.Response.Errors)
+		m.inspector.SetResult(r)
 		if r.RawBody != nil {
 			// Non-JSON response (auth error, HTML page, etc.) — show raw body
 			m.results.SetContent(string(r.RawBody))
-			m.statusbar.SetResult(r.StatusCode, r.Duration, r.Size, true)
+			m.statusbar.SetResult(r.StatusCode, r.Duration, r.Timing.TTFB, r.Size, true)
 		} else {
 			hasErrors := r.Response.HasErrors()
 			// Build display content with syntax highlighting
@@ -50,35 +71,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if err := m.results.SetPrettyJSON(raw); err != nil {
 				m.results.SetContent(string(raw))
 			}
-			m.statusbar.SetResult(r.StatusCode, r.Duration, r.Size, hasErrors)
+			m.statusbar.SetResult(r.StatusCode, r.Duration, r.Timing.TTFB, r.Size, hasErrors)
+			m.applyExtract(r.Response.Data)
+		}
+		switch r.PersistedQuery {
+		case graphql.PersistedHit:
+			m.statusbar.AddNote("APQ hit")
+		case graphql.PersistedMiss:
+			m.statusbar.AddNote("APQ miss")
 		}
 
-		// Auto-save to history
-		query := m.editor.Value()
-		vars := m.variables.Value()
-		ep := m.endpoint.Value()
-		if query != "" && !m.histStore.IsDuplicate(query, vars, ep) {
-			entry := history.Entry{
-				ID:        history.GenerateID(),
-				Name:      history.EntryNameFromQuery(query),
-				Query:     query,
-				Variables: vars,
-				Endpoint:  ep,
-				EnvName:   m.configStore.Config.ActiveEnv,
-				CreatedAt: time.Now(),
-			}
-			_ = m.histStore.AddEntry(entry)
-			m.histSidebar.Rebuild()
-			// Re-layout in case sidebar just became visible
-			m.layoutPanels()
+		var cmd tea.Cmd
+		if err := m.saveSnapshot(m.saveToHistory(), r); err != nil {
+			cmd = m.setTimedError("Snapshot: " + err.Error())
 		}
-		return m, nil
+		return m, cmd
 
 	case QueryErrorMsg:
 		m.querying = false
 		m.cancelQuery = nil
 		m.results.SetContent("Error: " + msg.Err.Error())
-		return m, m.setTimedError(msg.Err.Error())
+		return m, m.setTimedError(errorMessage(msg.Err))
 
 	case QueryAbortedMsg:
 		m.querying = false
@@ -87,10 +100,56 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusbar.SetAborted()
 		return m, nil
 
+	case StreamEventMsg:
+		raw, _ := json.Marshal(msg.Event.Response)
+		m.setErrors(msg.Event.Response.Errors)
+		if m.incremental {
+			m.parts++
+			m.partErrors = msg.Event.Response.HasErrors()
+			if err := m.results.SetPrettyJSON(raw); err != nil {
+				m.results.SetContent(string(raw))
+			}
+			m.statusbar.SetReceiving(m.parts)
+			return m, waitForEvent(msg.Stream)
+		}
+		m.results.AppendEvent(msg.Event.Received, raw)
+		m.statusbar.SetStreaming(m.results.EventCount())
+		return m, waitForEvent(msg.Stream)
+
+	case StreamDoneMsg:
+		m.querying = false
+		m.cancelQuery = nil
+		m.saveToHistory()
+		if m.incremental {
+			m.incremental = false
+			if errors.Is(msg.Err, context.Canceled) {
+				m.statusbar.SetAborted()
+				return m, nil
+			}
+			if msg.Err != nil {
+				return m, m.setTimedError("Stream: " + msg.Err.Error())
+			}
+			m.statusbar.SetResult(msg.Stream.StatusCode(), time.Since(m.queryStart), 0, msg.Stream.Size(), m.partErrors)
+			return m, nil
+		}
+		if msg.Err != nil && !errors.Is(msg.Err, context.Canceled) {
+			return m, m.setTimedError("Subscription: " + msg.Err.Error())
+		}
+		if m.results.EventCount() == 0 {
+			m.results.SetContent("Subscription closed without events")
+		}
+		m.statusbar.SetStreamClosed(m.results.EventCount())
+		return m, nil
+
 	case SchemaFetchedMsg:
 		m.browser.SetSchema(msg.Schema)
 		m.schemaAST = validate.LoadSchema(msg.Schema)
 		m.statusbar.SetSchemaLoaded(len(msg.Schema.Types))
+		// Keep showing why the workspace is not used after the first load.
+		if m.workspaceErr != "" {
+			m.statusbar.SetError(m.workspaceErr)
+			m.workspaceErr = ""
+		}
 		// Auto-open builder if Enter was pressed before schema was loaded
 		if m.pendingBuilderOpen {
 			m.pendingBuilderOpen = false
@@ -146,6 +205,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.setTimedInfo("Query built from schema")
 
 	case history.LoadEntryMsg:
+		m.loadedEntry = msg.Entry
 		m.editor.SetValue(msg.Entry.Query)
 		m.variables.SetValue(msg.Entry.Variables)
 		m.endpoint.SetValue(msg.Entry.Endpoint)
@@ -153,7 +213,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.Entry.EnvName != "" {
 			m.configStore.Config.ActiveEnv = msg.Entry.EnvName
 			if env := m.configStore.Config.ActiveEnvironment(); env != nil {
-				m.endpoint.SetValue(env.Endpoint)
+				m.endpoint.SetValue(m.configStore.Config.Endpoint(env.Name))
 				m.endpoint.SetEnvName(env.Name)
 			} else {
 				m.configStore.Config.ActiveEnv = ""
@@ -163,8 +223,84 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.configStore.Config.ActiveEnv = ""
 			m.endpoint.SetEnvName("")
 		}
+		m.syncEnvVariables()
 		m.setFocus(PanelEditor)
-		return m, m.autoFetchSchema()
+		cmd := m.autoFetchSchema()
+		if len(msg.Entry.Dynamic) > 0 {
+			cmd = tea.Batch(cmd, m.setTimedInfo("Replaying "+interp.FormatValues(msg.Entry.Dynamic)))
+		}
+		return m, cmd
+
+	case history.ShowRunsMsg:
+		return m.showRuns(msg.Entry)
+
+	case history.RunFolderMsg:
+		return m.runFolder(msg.Folder)
+
+	case suiteResultMsg:
+		return m.nextSuiteEntry(msg.Result)
+
+	case results.PollMsg:
+		return m.startPoll(msg.Spec)
+	case pollResultMsg:
+		if msg.poller != m.poller {
+			return m, nil
+		}
+		return m.pollResult(msg.Result, msg.Err)
+	case pollTickMsg:
+		if msg.poller != m.poller {
+			return m, nil
+		}
+		// Each poll is a new request with {{$...}} values of its own.
+		next, err := m.pollRequest.Redraw()
+		if err != nil {
+			return m, tea.Batch(m.setTimedError("Poll: "+err.Error()), m.pollAfter())
+		}
+		m.pollRequest, m.dynamic = next, next.Dynamic
+		return m, m.pollOnce()
+	case pollStoppedMsg:
+		if msg.poller != m.poller {
+			return m, nil
+		}
+		n := m.poller.Count()
+		m.endPoll()
+		return m, m.setTimedInfo(fmt.Sprintf("Poll stopped after %d requests", n))
+
+	case bench.StartMsg:
+		return m.startBench(msg.Options)
+	case benchTickMsg:
+		if m.benchRun == nil {
+			return m, nil
+		}
+		m.bench.SetStats(m.benchRun.Stats())
+		return m, benchTick()
+	case benchDoneMsg:
+		return m.finishBench(msg.Stats)
+	case bench.CloseMsg:
+		m.rightPanelMode = modeResults
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		return m, nil
+
+	case compare.RunMsg:
+		if m.compareEnv == "" {
+			return m.openCompare()
+		}
+		return m.startCompare(m.compareEnv)
+	case compareDoneMsg:
+		return m.finishCompare(msg)
+	case compare.IgnoreMsg:
+		m.configStore.Config.CompareIgnore = msg.Patterns
+		_ = m.configStore.Save()
+		return m, nil
+	case compare.CloseMsg:
+		m.rightPanelMode = modeResults
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		return m, nil
+
+	case suite.CloseMsg:
+		m.rightPanelMode = modeResults
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		return m, nil
 
 	case history.SidebarUpdatedMsg:
 		// Re-layout in case sidebar content changed visibility
@@ -175,15 +311,68 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.overlay.Close()
 		return m, nil
 
+	case results.ShowPathMsg:
+		m.rightPanelMode = modeResults
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		if !m.results.ScrollToPath(msg.Path) {
+			return m, m.setTimedError("Path not found in result")
+		}
+		return m, nil
+
+	case results.GotoLocationMsg:
+		m.editor.GotoPosition(msg.Line, msg.Column)
+		m.setFocus(PanelEditor)
+		return m, m.setTimedInfo(fmt.Sprintf("Query line %d, column %d", msg.Line, msg.Column))
+
+	case results.CloseErrorsMsg:
+		m.rightPanelMode = modeResults
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		return m, nil
+
+	case inspector.CloseMsg:
+		m.rightPanelMode = modeResults
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		return m, nil
+
+	case picker.SelectMsg:
+		if snaps := m.pickerSnaps; snaps != nil {
+			m.pickerSnaps = nil
+			if msg.Index >= len(snaps) {
+				return m, nil
+			}
+			return m.showSnapshot(snaps[msg.Index])
+		}
+		if envs := m.pickerEnvs; envs != nil {
+			m.pickerEnvs = nil
+			if msg.Index >= len(envs) {
+				return m, nil
+			}
+			return m.startCompare(envs[msg.Index])
+		}
+		if msg.Index >= len(m.pickerOps) {
+			return m, nil
+		}
+		m.operationName = m.pickerOps[msg.Index].Name
+		m.pickerOps = nil
+		return m.runOperation(m.operationName)
+
+	case picker.CloseMsg:
+		m.pickerOps, m.pickerEnvs, m.pickerSnaps = nil, nil, nil
+		return m, nil
+
 	case overlay.ConfigChangedMsg:
 		m.configStore.Config = msg.Config
 		_ = m.configStore.Save()
+		m.clients = nil // connection settings may have changed
+		m.secrets.Forget()
+		m.compare.SetIgnore(m.configStore.Config.CompareIgnore)
 		if env := m.configStore.Config.ActiveEnvironment(); env != nil {
-			m.endpoint.SetValue(env.Endpoint)
+			m.endpoint.SetValue(m.configStore.Config.Endpoint(env.Name))
 			m.endpoint.SetEnvName(env.Name)
 		} else {
 			m.endpoint.SetEnvName("")
 		}
+		m.syncEnvVariables()
 		m.layoutPanels()
 		return m, m.autoFetchSchema()
 
@@ -255,6 +444,12 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 		return *m, cmd
 	}
 
+	if m.picker.IsOpen() {
+		var cmd tea.Cmd
+		m.picker, cmd = m.picker.Update(msg)
+		return *m, cmd
+	}
+
 	// When builder is open, handle quit keys at app level, route rest to builder
 	if m.builder.IsOpen() {
 		if key.Matches(msg, keys.Quit) || key.Matches(msg, keys.Abort) {
@@ -266,6 +461,21 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 		return *m, cmd
 	}
 
+	// The poll prompt takes every key but quit and abort
+	if m.focus == PanelResults && m.rightPanelMode == modeResults && m.results.Prompting() &&
+		!key.Matches(msg, keys.Quit, keys.Abort) {
+		var cmd tea.Cmd
+		m.results, cmd = m.results.Update(msg)
+		return *m, cmd
+	}
+	// and so does the ignore prompt of the compare panel
+	if m.focus == PanelResults && m.rightPanelMode == modeCompare && m.compare.Prompting() &&
+		!key.Matches(msg, keys.Quit, keys.Abort) {
+		var cmd tea.Cmd
+		m.compare, cmd = m.compare.Update(msg)
+		return *m, cmd
+	}
+
 	showSidebar := m.shouldShowSidebar()
 
 	switch {
@@ -311,6 +521,9 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 		}
 		return *m, tea.Batch(tea.SetClipboard(content), m.setTimedInfo("Copied to clipboard"))
 
+	case key.Matches(msg, keys.SaveResult) && m.rightPanelMode == modeReport && m.report.HasRun():
+		return m.saveReport()
+
 	case key.Matches(msg, keys.SaveResult):
 		content := m.results.Content()
 		if content == "" {
@@ -360,6 +573,36 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 	case msg.String() == "enter" && m.focus == PanelResults && m.rightPanelMode == modeResults && !m.results.Searching():
 		return m.executeQuery()
 
+	case msg.String() == "e" && m.focus == PanelResults && m.rightPanelMode == modeResults && !m.results.Searching():
+		if m.errors.Count() == 0 {
+			return *m, m.setTimedInfo("No errors in the last response")
+		}
+		m.rightPanelMode = modeErrors
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		return *m, nil
+
+	case msg.String() == "w" && m.focus == PanelResults && m.rightPanelMode == modeResults && !m.results.Searching():
+		if m.querying {
+			return *m, m.setTimedInfo("A request is running (ctrl+c aborts it)")
+		}
+		return *m, m.results.StartPollPrompt(cmp.Or(m.pollSpec, poll.Spec{Interval: poll.DefaultInterval}.String()))
+
+	case msg.String() == "c" && m.focus == PanelResults && m.rightPanelMode == modeResults && !m.results.Searching():
+		return m.openCompare()
+
+	case msg.String() == "b" && m.focus == PanelResults && m.rightPanelMode == modeResults && !m.results.Searching():
+		m.rightPanelMode = modeBench
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		return *m, m.bench.Focus()
+
+	case msg.String() == "i" && m.focus == PanelResults && m.rightPanelMode == modeResults && !m.results.Searching():
+		if !m.inspector.HasExchange() {
+			return *m, m.setTimedInfo("No request sent yet")
+		}
+		m.rightPanelMode = modeInspector
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		return *m, nil
+
 	// Escape to stop editing + lint
 	case msg.String() == "esc" && m.focus == PanelEditor && m.editor.Editing():
 		m.editor.StopEditing()
@@ -372,11 +615,12 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 	case msg.String() == "esc" && m.focus == PanelVariables && m.variables.Editing():
 		m.variables.StopEditing()
 		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+		m.syncEnvVariables()
 		var cmd tea.Cmd
-		if v := strings.TrimSpace(m.variables.Value()); v != "" {
-			if err := validate.Variables(v, m.editor.Value(), m.schemaAST); err != nil {
-				cmd = m.setTimedError("Variables: " + err.Error())
-			}
+		if err := m.lintVariables(m.variables.Value()); err != nil {
+			cmd = m.setTimedError("Variables: " + err.Error())
+		} else if err := m.lintPlaceholders(); err != nil {
+			cmd = m.setTimedError("Placeholders: " + err.Error())
 		}
 		return *m, cmd
 
@@ -443,7 +687,7 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 					return *m, m.setTimedError("Variables: invalid JSON")
 				}
 				m.variables.SetValue(formatted)
-				if verr := validate.Variables(formatted, m.editor.Value(), m.schemaAST); verr != nil {
+				if verr := m.lintVariables(formatted); verr != nil {
 					return *m, m.setTimedError("Variables: " + verr.Error())
 				}
 			}
@@ -471,6 +715,129 @@ func (m *Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 	return *m, tea.Batch(cmds...)
 }
 
+// setErrors updates the errors view with the errors of the latest response,
+// leaving it when the response has none.
+func (m *Model) setErrors(errs []graphql.Error) {
+	m.errors.SetErrors(errs)
+	if len(errs) == 0 && m.rightPanelMode == modeErrors {
+		m.rightPanelMode = modeResults
+		m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+	}
+}
+
+// saveToHistory auto-saves the current query to history unless it duplicates
+// the most recent entry. It returns the ID of the entry the run is of: the
+// entry last loaded when the request is still the same, or the new or most
+// recent one.
+func (m *Model) saveToHistory() string {
+	query := m.editor.Value()
+	vars := m.variables.Value()
+	ep := m.endpoint.Value()
+	if query == "" {
+		return ""
+	}
+	runOf := ""
+	if m.loadedUnchanged() {
+		runOf = m.loadedEntry.ID
+		// Replaying the entry's dynamic values reproduces the entry itself.
+		if len(m.dynamic) > 0 && maps.Equal(m.dynamic, m.loadedEntry.Dynamic) {
+			return runOf
+		}
+	}
+	// A run with dynamic values is never a duplicate: its values are what
+	// makes it reproducible.
+	if len(m.dynamic) == 0 && m.histStore.IsDuplicate(query, vars, ep) {
+		newest, _ := m.histStore.Newest()
+		return cmp.Or(runOf, newest.ID)
+	}
+	entry := history.Entry{
+		ID:        history.GenerateID(),
+		Name:      history.EntryNameFromQuery(query),
+		Query:     query,
+		Variables: vars,
+		Endpoint:  ep,
+		EnvName:   m.configStore.Config.ActiveEnv,
+		Dynamic:   m.dynamic,
+		CreatedAt: time.Now(),
+	}
+	_ = m.histStore.AddEntry(entry)
+	m.histSidebar.Rebuild()
+	// Re-layout in case sidebar just became visible
+	m.layoutPanels()
+	return cmp.Or(runOf, entry.ID)
+}
+
+// loadedUnchanged reports whether the query, variables and endpoint are still
+// those of the history entry last loaded.
+func (m *Model) loadedUnchanged() bool {
+	l := m.loadedEntry
+	return l.ID != "" && l.Query == m.editor.Value() && l.Variables == m.variables.Value() && l.Endpoint == m.endpoint.Value()
+}
+
+// saveSnapshot keeps the response of r with the history entry entryID,
+// within the configured size limit.
+func (m *Model) saveSnapshot(entryID string, r *graphql.Result) error {
+	limit, err := m.configStore.Config.SnapshotLimit()
+	if err != nil || limit == 0 || entryID == "" {
+		return err
+	}
+	body := r.RawBody
+	if body == nil {
+		body, _ = json.Marshal(r.Response)
+	}
+	return m.histStore.SaveSnapshot(history.Snapshot{
+		EntryID:  entryID,
+		At:       time.Now(),
+		Status:   r.StatusCode,
+		Duration: r.Duration,
+		Size:     r.Size,
+	}, body, limit)
+}
+
+// showRuns lists the saved responses of entry to pick one to show.
+func (m *Model) showRuns(entry history.Entry) (Model, tea.Cmd) {
+	snaps := m.histStore.Snapshots(entry.ID)
+	if len(snaps) == 0 {
+		return *m, m.setTimedInfo("No saved responses for " + entry.Name)
+	}
+	items := make([]string, len(snaps))
+	for i, s := range snaps {
+		items[i] = fmt.Sprintf("%s  %d  %s  %s", s.At.Format("Jan 2 15:04:05"), s.Status,
+			s.Duration.Round(time.Millisecond), formatSize(s.Size))
+	}
+	m.pickerSnaps = snaps
+	m.picker.Open("Runs of "+entry.Name, items, 0, m.width, m.height)
+	return *m, nil
+}
+
+// showSnapshot shows a saved response in the result viewer without sending
+// the request again.
+func (m *Model) showSnapshot(s history.Snapshot) (Model, tea.Cmd) {
+	body, err := m.histStore.LoadSnapshot(s)
+	if err != nil {
+		return *m, m.setTimedError("Snapshot: " + err.Error())
+	}
+	var resp graphql.Response
+	isJSON := json.Unmarshal(body, &resp) == nil
+	m.setErrors(resp.Errors)
+	if !isJSON || m.results.SetPrettyJSON(body) != nil {
+		m.results.SetContent(string(body))
+	}
+	m.results.SetNote("saved " + s.At.Format("Jan 2 15:04:05"))
+	m.statusbar.SetResult(s.Status, s.Duration, 0, s.Size, !isJSON || resp.HasErrors())
+	m.rightPanelMode = modeResults
+	m.setFocus(PanelResults)
+	return *m, nil
+}
+
+// formatSize formats a size in bytes for display.
+func formatSize(bytes int) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+}
+
 // saveSession persists the current editor state so it can be restored on next launch.
 func (m *Model) saveSession() {
 	m.histStore.Meta.LastQuery = m.editor.Value()
@@ -495,6 +862,17 @@ func (m *Model) setTimedError(msg string) tea.Cmd {
 	})
 }
 
+// errorMessage returns err for the status bar, capitalized: errors of
+// request name the step that failed in lower case, to read mid-sentence.
+func errorMessage(err error) string {
+	msg := err.Error()
+	r, n := utf8.DecodeRuneInString(msg)
+	if n == 0 {
+		return msg
+	}
+	return string(unicode.ToUpper(r)) + msg[n:]
+}
+
 // setTimedInfo shows an info message in the status bar that auto-clears after 3 seconds.
 func (m *Model) setTimedInfo(msg string) tea.Cmd {
 	m.statusbar.SetInfo(msg)
@@ -520,20 +898,21 @@ func (m *Model) runLint() {
 	case PanelEditor:
 		if err := validate.Query(m.editor.Value(), m.schemaAST); err != nil {
 			m.statusbar.SetError("Query: " + err.Error())
+		} else if err := m.lintPlaceholders(); err != nil {
+			m.statusbar.SetError("Placeholders: " + err.Error())
 		} else {
 			m.statusbar.Clear()
 		}
 	case PanelVariables:
-		if v := strings.TrimSpace(m.variables.Value()); v != "" {
-			if err := validate.Variables(v, m.editor.Value(), m.schemaAST); err != nil {
-				m.statusbar.SetError("Variables: " + err.Error())
-			} else {
-				m.statusbar.Clear()
-			}
+		if err := m.lintVariables(m.variables.Value()); err != nil {
+			m.statusbar.SetError("Variables: " + err.Error())
+		} else if err := m.lintPlaceholders(); err != nil {
+			m.statusbar.SetError("Placeholders: " + err.Error())
 		} else {
 			m.statusbar.Clear()
 		}
 	}
+	m.syncEnvVariables()
 }
 
 // switchFocus changes panel focus and auto-fetches schema when leaving the endpoint panel.
@@ -550,10 +929,158 @@ func (m *Model) switchFocus(target Panel) (Model, tea.Cmd) {
 	return *m, nil
 }
 
+// executeQuery runs the operation under the editor cursor. Documents with
+// several operations open a picker when the cursor does not identify one.
 func (m *Model) executeQuery() (Model, tea.Cmd) {
 	if m.querying {
 		return *m, nil
 	}
+	ops := validate.Operations(m.editor.Value())
+	switch {
+	case len(ops) == 0:
+		return m.runOperation("")
+	case len(ops) == 1:
+		return m.runOperation(ops[0].Name)
+	}
+	if m.focus == PanelEditor {
+		if op, ok := validate.OperationAt(ops, m.editor.CursorLine()); ok {
+			m.operationName = op.Name
+			return m.runOperation(op.Name)
+		}
+	}
+
+	items := make([]string, len(ops))
+	selected := 0
+	for i, op := range ops {
+		name := op.Name
+		if name == "" {
+			name = "(anonymous)"
+		}
+		items[i] = op.Type + " " + name
+		if op.Name == m.operationName {
+			selected = i
+		}
+	}
+	m.pickerOps = ops
+	m.picker.Open("Run which operation?", items, selected, m.width, m.height)
+	return *m, nil
+}
+
+// lintOperation returns the operation variables are validated against: the
+// one under the editor cursor, else the one last run.
+func (m *Model) lintOperation() string {
+	ops := validate.Operations(m.editor.Value())
+	if len(ops) == 1 {
+		return ops[0].Name
+	}
+	if op, ok := validate.OperationAt(ops, m.editor.CursorLine()); ok {
+		return op.Name
+	}
+	return m.operationName
+}
+
+// envVariables returns the active environment's variables that the named
+// operation declares and the panel does not set: the panel takes precedence.
+func (m Model) envVariables(panel map[string]any, operationName string) (map[string]any, error) {
+	return request.EnvVariables(&m.configStore.Config, m.editor.Value(), operationName, panel)
+}
+
+// lintVariables validates panel, merged with the environment's variables,
+// against the operation being linted.
+func (m Model) lintVariables(panel string) error {
+	query, op := m.editor.Value(), m.lintOperation()
+	var vars map[string]any
+	if strings.TrimSpace(panel) != "" {
+		if err := json.Unmarshal([]byte(panel), &vars); err != nil {
+			return validate.Variables(panel, query, op, m.schemaAST)
+		}
+	}
+	env, err := m.envVariables(vars, op)
+	if err != nil {
+		return err
+	}
+	if len(env) == 0 {
+		return validate.Variables(panel, query, op, m.schemaAST)
+	}
+	merged, _ := json.Marshal(request.MergeVariables(env, vars))
+	return validate.Variables(string(merged), query, op, m.schemaAST)
+}
+
+// syncEnvVariables shows in the variables panel which variables the active
+// environment supplies to the operation being linted.
+func (m *Model) syncEnvVariables() {
+	panel, _ := m.variables.ParsedVariables()
+	env, _ := m.envVariables(panel, m.lintOperation())
+	m.variables.SetFromEnv(slices.Sorted(maps.Keys(env)))
+}
+
+// lookup resolves placeholders for one request: environment values, the
+// process environment and the built-in dynamic values of dynamic.
+func (m Model) lookup(dynamic *interp.Dynamic) interp.Lookup {
+	return interp.Chain(m.configStore.Config.Lookup(), dynamic.Lookup)
+}
+
+// resolveTarget returns the endpoint and headers with their placeholders and
+// secret references resolved, failing when any of them is unknown. Show
+// errors with errorMessage.
+func (m Model) resolveTarget(lookup interp.Lookup) (string, map[string]string, error) {
+	return request.Target(&m.configStore.Config, m.secrets, m.endpoint.Value(), lookup)
+}
+
+// lintPlaceholders reports placeholders in the endpoint, the headers or the
+// variables that no value is found for.
+func (m Model) lintPlaceholders() error {
+	lookup := m.lookup(interp.NewDynamic())
+	_, names := interp.Expand(m.endpoint.Value(), lookup)
+	_, more := m.configStore.Config.ResolveHeaders(lookup)
+	names = append(names, more...)
+	if panel, err := m.variables.ParsedVariables(); err == nil {
+		env, _ := m.envVariables(panel, m.lintOperation())
+		_, more = interp.ExpandValue(request.MergeVariables(env, panel), lookup)
+		names = append(names, more...)
+	}
+	return request.Unresolved(names)
+}
+
+// extractRules returns the rules of the active environment followed by the
+// # qla:extract rules of query.
+func (m Model) extractRules(query string) ([]extract.Rule, error) {
+	var rules []extract.Rule
+	if env := m.configStore.Config.ActiveEnvironment(); env != nil {
+		rules = append(rules, env.Extract...)
+	}
+	more, err := extract.FromQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, more...), nil
+}
+
+// applyExtract stores the values the pending rules pick out of data in the
+// environment the request was sent with, and notes the outcome in the
+// status bar.
+func (m *Model) applyExtract(data json.RawMessage) {
+	rules := m.extract
+	m.extract = nil
+	if len(rules) == 0 {
+		return
+	}
+	values, errs := extract.Apply(rules, data)
+	if len(values) > 0 {
+		if !slices.Contains(m.configStore.Config.EnvNames(), m.extractEnv) {
+			m.statusbar.AddNote("extract: no environment to store " + strings.Join(slices.Sorted(maps.Keys(values)), ", "))
+			return
+		}
+		m.configStore.Config.SetExtracted(m.extractEnv, values)
+		m.statusbar.AddNote("set " + strings.Join(slices.Sorted(maps.Keys(values)), ", "))
+	}
+	if len(errs) > 0 {
+		m.statusbar.AddNote("extract: " + errors.Join(errs...).Error())
+	}
+}
+
+// runOperation sends the editor document, selecting operationName.
+func (m *Model) runOperation(operationName string) (Model, tea.Cmd) {
 	ep := m.endpoint.Value()
 	if ep == "" {
 		return *m, m.setTimedError("No endpoint configured")
@@ -563,25 +1090,84 @@ func (m *Model) executeQuery() (Model, tea.Cmd) {
 	if err != nil {
 		return *m, m.setTimedError("Invalid variables JSON: " + err.Error())
 	}
-
 	query := m.editor.Value()
 	if query == "" {
 		return *m, nil
 	}
 
+	rules, err := m.extractRules(query)
+	if err != nil {
+		return *m, m.setTimedError("Extract: " + err.Error())
+	}
+
+	client, err := m.client()
+	if err != nil {
+		return *m, m.setTimedError("Connection: " + err.Error())
+	}
+	// A loaded entry is sent again with the {{$...}} values it was sent with.
+	var replay map[string]string
+	if m.loadedUnchanged() {
+		replay = m.loadedEntry.Dynamic
+	}
+	prepared, err := request.Prepare(&m.configStore.Config, m.secrets, request.Spec{
+		Query:         query,
+		OperationName: operationName,
+		Variables:     vars,
+		Endpoint:      ep,
+		Client:        client,
+		Dynamic:       replay,
+	})
+	if err != nil {
+		return *m, m.setTimedError(errorMessage(err))
+	}
+	envVars, _ := m.envVariables(vars, operationName)
+	m.variables.SetFromEnv(slices.Sorted(maps.Keys(envVars)))
+	m.dynamic = prepared.Dynamic
+	ep, headers, req := prepared.Endpoint, prepared.Headers, prepared.Request
+
+	m.extract = rules
+	m.extractEnv = m.configStore.Config.ActiveEnv
 	m.querying = true
+	m.queryStart = time.Now()
 	m.rightPanelMode = modeResults
 	m.statusbar.SetLoading()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	m.cancelQuery = cancel
 
-	req := graphql.Request{
-		Query:     query,
-		Variables: vars,
+	if validate.OperationType(query, operationName) == "subscription" {
+		m.results.SetContent("Waiting for events...")
+		m.statusbar.SetStreaming(0)
+		subscribe := client.Subscribe
+		if m.configStore.Config.SubscriptionTransport() == config.SubscriptionsSSE {
+			subscribe = client.Stream
+		}
+		return *m, func() tea.Msg {
+			stream, err := subscribe(ctx, ep, req, headers)
+			if err != nil {
+				if ctx.Err() != nil {
+					return QueryAbortedMsg{}
+				}
+				return QueryErrorMsg{Err: err}
+			}
+			return waitForEvent(stream)()
+		}
+	}
+
+	if validate.UsesIncrementalDelivery(query) {
+		m.incremental = true
+		m.parts = 0
+		return *m, func() tea.Msg {
+			stream, err := client.Stream(ctx, ep, req, headers)
+			if err != nil {
+				if ctx.Err() != nil {
+					return QueryAbortedMsg{}
+				}
+				return QueryErrorMsg{Err: err}
+			}
+			return waitForEvent(stream)()
+		}
 	}
-	client := m.gqlClient
-	headers := m.configStore.Config.MergedHeaders()
 
 	cmd := func() tea.Msg {
 		result, err := client.Execute(ctx, ep, req, headers)
@@ -597,6 +1183,405 @@ func (m *Model) executeQuery() (Model, tea.Cmd) {
 	return *m, cmd
 }
 
+// runFolder starts a suite run of the entries of folder against the active
+// environment and shows its report.
+func (m *Model) runFolder(folder string) (Model, tea.Cmd) {
+	if m.querying {
+		return *m, m.setTimedInfo("A request is running (ctrl+c aborts it)")
+	}
+	var entries []history.Entry
+	for _, f := range m.histStore.Folders() {
+		if f.Name == folder {
+			entries = suite.Order(f.Entries)
+		}
+	}
+	if len(entries) == 0 {
+		return *m, m.setTimedInfo("No entries in " + folder)
+	}
+	client, err := m.client()
+	if err != nil {
+		return *m, m.setTimedError("Connection: " + err.Error())
+	}
+	runner, err := suite.New(&m.configStore.Config, m.secrets, client)
+	if err != nil {
+		return *m, m.setTimedError(errorMessage(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.suiteRunner, m.suiteCtx, m.cancelQuery = runner, ctx, cancel
+	m.querying = true
+	m.report.Start(folder, m.configStore.Config.ActiveEnv, entries)
+	m.rightPanelMode = modeReport
+	m.setFocus(PanelResults)
+	m.statusbar.SetLoading()
+	return *m, m.runSuiteEntry(entries[0])
+}
+
+func (m *Model) runSuiteEntry(e history.Entry) tea.Cmd {
+	runner, ctx := m.suiteRunner, m.suiteCtx
+	return func() tea.Msg {
+		return suiteResultMsg{Result: runner.RunEntry(ctx, e)}
+	}
+}
+
+// nextSuiteEntry records the result of the current entry and runs the next
+// one, until the folder is done or the run is aborted.
+func (m *Model) nextSuiteEntry(res suite.Result) (Model, tea.Cmd) {
+	if m.suiteRunner == nil {
+		return *m, nil
+	}
+	aborted := m.suiteCtx.Err() != nil
+	if !aborted {
+		m.report.Add(res)
+		if e, ok := m.report.Next(); ok {
+			return *m, m.runSuiteEntry(e)
+		}
+	}
+	m.report.Stop(aborted)
+	m.querying = false
+	m.cancelQuery = nil
+	m.suiteRunner, m.suiteCtx = nil, nil
+	if aborted {
+		m.statusbar.SetAborted()
+		return *m, nil
+	}
+	passed, failed, total := suite.Summary(m.report.Results())
+	summary := fmt.Sprintf("%s: %d passed, %d failed in %s", m.report.Folder(), passed, failed, total.Round(time.Millisecond))
+	if failed > 0 {
+		return *m, m.setTimedError(summary)
+	}
+	return *m, m.setTimedInfo(summary)
+}
+
+// saveReport writes the report of the last suite run as JUnit XML to
+// ~/Downloads.
+func (m *Model) saveReport() (Model, tea.Cmd) {
+	filename := m.report.Folder() + "-" + m.report.Started().Format("20060102-150405") + ".xml"
+	home, _ := os.UserHomeDir()
+	path := filepath.Join(home, "Downloads", filename)
+	f, err := os.Create(path)
+	if err != nil {
+		return *m, m.setTimedError("Save failed: " + err.Error())
+	}
+	err = suite.WriteJUnit(f, m.report.Folder(), m.report.Started(), m.report.Results())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return *m, m.setTimedError("Save failed: " + err.Error())
+	}
+	return *m, m.setTimedInfo("Saved to ~/Downloads/" + filename)
+}
+
+// startBench replays the operation under the editor cursor with opts
+// against the active environment, on a client of its own that keeps a
+// connection per worker.
+func (m *Model) startBench(opts bench.Options) (Model, tea.Cmd) {
+	if m.querying {
+		return *m, m.setTimedInfo("A request is running (ctrl+c aborts it)")
+	}
+	client, err := request.NewClient(&m.configStore.Config, m.secrets, graphql.WithConnections(opts.Concurrency))
+	if err != nil {
+		return *m, m.setTimedError("Connection: " + err.Error())
+	}
+	prepared, err := m.prepareRepeat("benchmark", &m.configStore.Config, m.endpoint.Value(), client)
+	if err != nil {
+		return *m, m.setTimedError(errorMessage(err))
+	}
+
+	target := operationTitle(prepared)
+	if env := m.configStore.Config.ActiveEnv; env != "" {
+		target += " on " + env
+	}
+	run := bench.New(opts, prepared.ExecuteAgain)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.benchRun, m.benchCtx, m.cancelQuery = run, ctx, cancel
+	m.querying = true
+	m.bench.Start(target)
+	m.statusbar.SetLoading()
+	return *m, tea.Batch(
+		func() tea.Msg { return benchDoneMsg{Stats: run.Run(ctx)} },
+		benchTick(),
+	)
+}
+
+// prepareRepeat prepares the operation under the editor cursor, or the one
+// last run, for sending over and over with client to the active environment
+// of cfg at endpoint, its own when empty: what to do with it names the
+// action in errors.
+func (m *Model) prepareRepeat(what string, cfg *config.Config, endpoint string, client *graphql.Client) (*request.Prepared, error) {
+	query := m.editor.Value()
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("no query to %s", what)
+	}
+	op := m.lintOperation()
+	if len(validate.Operations(query)) > 1 && op == "" {
+		return nil, fmt.Errorf("several operations: run the one to %s first", what)
+	}
+	if validate.OperationType(query, op) == "subscription" {
+		return nil, fmt.Errorf("cannot %s a subscription", what)
+	}
+	vars, err := m.variables.ParsedVariables()
+	if err != nil {
+		return nil, fmt.Errorf("invalid variables JSON: %w", err)
+	}
+	return request.Prepare(cfg, m.secrets, request.Spec{
+		Query:         query,
+		OperationName: op,
+		Variables:     vars,
+		Endpoint:      endpoint,
+		Client:        client,
+	})
+}
+
+// operationTitle names the operation of p for a panel title.
+func operationTitle(p *request.Prepared) string {
+	if p.Request.OperationName != "" {
+		return p.Request.OperationName
+	}
+	return history.EntryNameFromQuery(p.Request.Query)
+}
+
+func benchTick() tea.Cmd {
+	return tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg { return benchTickMsg{} })
+}
+
+// finishBench shows the final statistics of a benchmark and sums them up in
+// the status bar.
+func (m *Model) finishBench(s bench.Stats) (Model, tea.Cmd) {
+	aborted := m.benchCtx != nil && m.benchCtx.Err() != nil
+	m.bench.SetStats(s)
+	m.bench.Stop()
+	m.querying = false
+	m.cancelQuery = nil
+	m.benchRun, m.benchCtx = nil, nil
+	if aborted {
+		m.statusbar.SetAborted()
+		return *m, nil
+	}
+	summary := fmt.Sprintf("Bench: %d requests, %d errors, p50 %s, p99 %s", s.Requests, s.Failed(),
+		s.P50.Round(time.Millisecond), s.P99.Round(time.Millisecond))
+	if s.Failed() > 0 {
+		return *m, m.setTimedError(summary)
+	}
+	return *m, m.setTimedInfo(summary)
+}
+
+// startPoll sends the operation under the editor cursor every interval of
+// spec, showing what changed from one response to the next, until it is
+// aborted or the condition of spec holds.
+func (m *Model) startPoll(s string) (Model, tea.Cmd) {
+	if m.querying {
+		return *m, m.setTimedInfo("A request is running (ctrl+c aborts it)")
+	}
+	spec, err := poll.ParseSpec(s)
+	if err != nil {
+		return *m, m.setTimedError("Poll: " + err.Error())
+	}
+	m.pollSpec = spec.String()
+	client, err := m.client()
+	if err != nil {
+		return *m, m.setTimedError("Connection: " + err.Error())
+	}
+	prepared, err := m.prepareRepeat("poll", &m.configStore.Config, m.endpoint.Value(), client)
+	if err != nil {
+		return *m, m.setTimedError(errorMessage(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.poller, m.pollCtx, m.pollRequest, m.cancelQuery = poll.New(spec), ctx, prepared, cancel
+	m.dynamic = prepared.Dynamic
+	m.querying = true
+	m.queryStart = time.Now()
+	m.statusbar.SetLoading()
+	return *m, m.pollOnce()
+}
+
+// pollOnce sends the next request of the poll.
+func (m *Model) pollOnce() tea.Cmd {
+	poller, ctx, prepared := m.poller, m.pollCtx, m.pollRequest
+	return func() tea.Msg {
+		result, err := prepared.Execute(ctx)
+		if ctx.Err() != nil {
+			return pollStoppedMsg{poller: poller}
+		}
+		return pollResultMsg{poller: poller, Result: result, Err: err}
+	}
+}
+
+// pollAfter waits for the interval of the poll, or for it to be aborted.
+func (m *Model) pollAfter() tea.Cmd {
+	poller, ctx := m.poller, m.pollCtx
+	return func() tea.Msg {
+		t := time.NewTimer(poller.Spec().Interval)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			return pollTickMsg{poller: poller}
+		case <-ctx.Done():
+			return pollStoppedMsg{poller: poller}
+		}
+	}
+}
+
+// pollResult shows a response of the poll with the values that changed
+// since the previous one highlighted, then waits for the next one.
+func (m *Model) pollResult(r *graphql.Result, err error) (Model, tea.Cmd) {
+	if err != nil {
+		return *m, tea.Batch(m.setTimedError("Poll: "+err.Error()), m.pollAfter())
+	}
+	m.setErrors(r.Response.Errors)
+	m.inspector.SetResult(r)
+	var v any
+	if r.RawBody != nil {
+		m.results.SetContent(string(r.RawBody))
+		v = string(r.RawBody)
+	} else {
+		raw, _ := json.Marshal(r.Response)
+		if err := m.results.SetPrettyJSON(raw); err != nil {
+			m.results.SetContent(string(raw))
+		}
+		_ = json.Unmarshal(raw, &v)
+	}
+	changes, done := m.poller.Observe(v)
+	var paths [][]any
+	for _, c := range changes {
+		if c.Kind != jsondiff.Removed {
+			paths = append(paths, c.Path)
+		}
+	}
+	m.results.MarkChanges(paths)
+	m.results.SetNote(fmt.Sprintf("poll #%d · %d changed · %d updates", m.poller.Count(), len(changes), m.poller.Updates()))
+	m.statusbar.SetResult(r.StatusCode, r.Duration, r.Timing.TTFB, r.Size, r.RawBody == nil && r.Response.HasErrors())
+	if m.poller.Count() == 1 {
+		m.saveToHistory()
+	}
+	if !done {
+		return *m, m.pollAfter()
+	}
+	n, until := m.poller.Count(), m.poller.Spec().Until.String()
+	m.endPoll()
+	return *m, m.setTimedInfo(fmt.Sprintf("Poll done after %d requests: %s", n, until))
+}
+
+// endPoll ends the poll in progress.
+func (m *Model) endPoll() {
+	if m.cancelQuery != nil {
+		m.cancelQuery()
+	}
+	m.querying = false
+	m.cancelQuery = nil
+	m.poller, m.pollCtx, m.pollRequest = nil, nil, nil
+}
+
+// openCompare asks which environment to compare the active one with.
+func (m *Model) openCompare() (Model, tea.Cmd) {
+	if m.querying {
+		return *m, m.setTimedInfo("A request is running (ctrl+c aborts it)")
+	}
+	active := m.configStore.Config.ActiveEnv
+	var others []string
+	for _, name := range m.configStore.Config.EnvNames() {
+		if name != active {
+			others = append(others, name)
+		}
+	}
+	if len(others) == 0 {
+		return *m, m.setTimedError("No other environment to compare with (ctrl+e to add)")
+	}
+	title := "Compare with which environment?"
+	if active != "" {
+		title = "Compare " + active + " with?"
+	}
+	m.pickerEnvs = others
+	m.picker.Open(title, others, slices.Index(others, m.compareEnv), m.width, m.height)
+	return *m, nil
+}
+
+// startCompare sends the operation under the editor cursor to the active
+// environment and to other at the same time, each with its own client,
+// headers and variables, and shows how the responses differ.
+func (m *Model) startCompare(other string) (Model, tea.Cmd) {
+	if m.querying {
+		return *m, m.setTimedInfo("A request is running (ctrl+c aborts it)")
+	}
+	active := m.configStore.Config.ActiveEnv
+	clientA, err := m.envClient(active)
+	if err != nil {
+		return *m, m.setTimedError("Connection: " + err.Error())
+	}
+	a, err := m.prepareRepeat("compare", &m.configStore.Config, m.endpoint.Value(), clientA)
+	if err != nil {
+		return *m, m.setTimedError(errorMessage(err))
+	}
+	cfg := m.configStore.Config
+	cfg.ActiveEnv = other
+	clientB, err := m.envClient(other)
+	if err != nil {
+		return *m, m.setTimedError(errorMessage(fmt.Errorf("compare: %s: connection: %w", other, err)))
+	}
+	b, err := m.prepareRepeat("compare", &cfg, "", clientB)
+	if err != nil {
+		return *m, m.setTimedError(errorMessage(fmt.Errorf("compare: %s: %w", other, err)))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelQuery = cancel
+	m.compareEnv = other
+	m.querying = true
+	m.queryStart = time.Now()
+	m.compare.Start(operationTitle(a))
+	m.rightPanelMode = modeCompare
+	m.statusbar.SetLoading()
+	m.statusbar.SetHints(hintsForFocus(m.focus, m.rightPanelMode))
+	return *m, func() tea.Msg {
+		sa, sb := compare.Run(ctx, active, a.Execute, other, b.Execute)
+		if ctx.Err() != nil {
+			return compareDoneMsg{Aborted: true}
+		}
+		return compareDoneMsg{A: sa, B: sb}
+	}
+}
+
+// finishCompare shows the differences between the responses of a
+// comparison and counts them in the status bar.
+func (m *Model) finishCompare(msg compareDoneMsg) (Model, tea.Cmd) {
+	m.querying = false
+	m.cancelQuery = nil
+	if msg.Aborted {
+		m.compare.Stop()
+		m.statusbar.SetAborted()
+		return *m, nil
+	}
+	c := compare.New(msg.A, msg.B)
+	m.compare.SetComparison(c)
+	for _, s := range []compare.Side{msg.A, msg.B} {
+		if s.Err != nil {
+			return *m, m.setTimedError(fmt.Sprintf("Compare: %s: %v", cmp.Or(s.Env, "no environment"), s.Err))
+		}
+	}
+	changes, ignored := m.compare.Changes()
+	summary := fmt.Sprintf("Compare: %d differences", len(changes))
+	if len(changes) == 1 {
+		summary = "Compare: 1 difference"
+	}
+	if ignored > 0 {
+		summary += fmt.Sprintf(", %d ignored", ignored)
+	}
+	return *m, m.setTimedInfo(summary)
+}
+
+// waitForEvent blocks on the next stream event and delivers it as a message.
+func waitForEvent(stream *graphql.Stream) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := stream.Next()
+		if !ok {
+			return StreamDoneMsg{Err: stream.Err(), Stream: stream}
+		}
+		return StreamEventMsg{Event: ev, Stream: stream}
+	}
+}
+
 func (m *Model) cycleEnvironment() (Model, tea.Cmd) {
 	names := m.configStore.Config.EnvNames()
 	if len(names) == 0 {
@@ -621,15 +1606,41 @@ func (m *Model) cycleEnvironment() (Model, tea.Cmd) {
 		m.configStore.Config.ActiveEnv = names[nextIdx]
 		env := m.configStore.Config.ActiveEnvironment()
 		if env != nil {
-			m.endpoint.SetValue(env.Endpoint)
+			m.endpoint.SetValue(m.configStore.Config.Endpoint(env.Name))
 			m.endpoint.SetEnvName(env.Name)
 		}
 	}
 	_ = m.configStore.Save()
+	m.syncEnvVariables()
 	m.layoutPanels()
 	return *m, m.autoFetchSchema()
 }
 
+// client returns the GraphQL client of the active environment, building it on
+// first use. The cache is dropped whenever the configuration changes.
+func (m *Model) client() (*graphql.Client, error) {
+	return m.envClient(m.configStore.Config.ActiveEnv)
+}
+
+// envClient returns the GraphQL client of the environment name, as client
+// does for the active one.
+func (m *Model) envClient(name string) (*graphql.Client, error) {
+	if c, ok := m.clients[name]; ok {
+		return c, nil
+	}
+	cfg := m.configStore.Config
+	cfg.ActiveEnv = name
+	c, err := request.NewClient(&cfg, m.secrets)
+	if err != nil {
+		return nil, err
+	}
+	if m.clients == nil {
+		m.clients = make(map[string]*graphql.Client)
+	}
+	m.clients[name] = c
+	return c, nil
+}
+
 // autoFetchSchema fetches the schema if the endpoint changed, silently skipping
 // when no endpoint is set. Use this for automatic triggers (startup, env cycle, history load).
 func (m *Model) autoFetchSchema() tea.Cmd {
@@ -646,12 +1657,18 @@ func (m *Model) fetchSchema() (Model, tea.Cmd) {
 	if ep == "" {
 		return *m, m.setTimedError("No endpoint configured")
 	}
+	client, err := m.client()
+	if err != nil {
+		return *m, m.setTimedError("Connection: " + err.Error())
+	}
+	target, headers, err := m.resolveTarget(m.lookup(interp.NewDynamic()))
+	if err != nil {
+		return *m, m.setTimedError(errorMessage(err))
+	}
 	m.lastEndpoint = ep
 	m.statusbar.SetSchemaLoading()
-	client := m.gqlClient
-	headers := m.configStore.Config.MergedHeaders()
 	cmd := func() tea.Msg {
-		s, err := schema.FetchSchema(context.Background(), client, ep, headers)
+		s, err := schema.FetchSchema(context.Background(), client, target, headers)
 		if err != nil {
 			return SchemaFetchErrorMsg{Err: err}
 		}
@@ -712,9 +1729,20 @@ func (m *Model) updateFocused(msg tea.Msg) []tea.Cmd {
 			cmds = append(cmds, m.scheduleLint())
 		}
 	case PanelResults:
-		if m.rightPanelMode == modeSchema {
+		switch m.rightPanelMode {
+		case modeSchema:
 			m.browser, cmd = m.browser.Update(msg)
-		} else {
+		case modeErrors:
+			m.errors, cmd = m.errors.Update(msg)
+		case modeInspector:
+			m.inspector, cmd = m.inspector.Update(msg)
+		case modeReport:
+			m.report, cmd = m.report.Update(msg)
+		case modeBench:
+			m.bench, cmd = m.bench.Update(msg)
+		case modeCompare:
+			m.compare, cmd = m.compare.Update(msg)
+		default:
 			m.results, cmd = m.results.Update(msg)
 		}
 	case PanelHistory:
@@ -766,6 +1794,11 @@ func (m *Model) layoutPanels() {
 		m.editor.SetSize(m.midW-2, m.editorH-2)
 		m.variables.SetSize(m.midW-2, m.varsH-2)
 		m.results.SetSize(m.rightW-2, m.contentH-2)
+		m.errors.SetSize(m.rightW-2, m.contentH-2)
+		m.inspector.SetSize(m.rightW-2, m.contentH-2)
+		m.report.SetSize(m.rightW-2, m.contentH-2)
+		m.bench.SetSize(m.rightW-2, m.contentH-2)
+		m.compare.SetSize(m.rightW-2, m.contentH-2)
 		m.browser.SetSize(m.rightW-2, m.contentH-2)
 	} else {
 		// Each panel border = 2 chars wide, 2 panels = 4
@@ -780,6 +1813,11 @@ func (m *Model) layoutPanels() {
 		m.editor.SetSize(m.leftW-2, m.editorH-2)
 		m.variables.SetSize(m.leftW-2, m.varsH-2)
 		m.results.SetSize(m.rightW-2, m.contentH-2)
+		m.errors.SetSize(m.rightW-2, m.contentH-2)
+		m.inspector.SetSize(m.rightW-2, m.contentH-2)
+		m.report.SetSize(m.rightW-2, m.contentH-2)
+		m.bench.SetSize(m.rightW-2, m.contentH-2)
+		m.compare.SetSize(m.rightW-2, m.contentH-2)
 		m.browser.SetSize(m.rightW-2, m.contentH-2)
 	}
 
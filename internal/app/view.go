@@ -52,9 +52,20 @@ func (m Model) renderView() string {
 
 	// Right column content
 	var rightContent string
-	if m.rightPanelMode == modeSchema {
+	switch m.rightPanelMode {
+	case modeSchema:
 		rightContent = m.browser.View()
-	} else {
+	case modeErrors:
+		rightContent = m.errors.View()
+	case modeInspector:
+		rightContent = m.inspector.View()
+	case modeReport:
+		rightContent = m.report.View()
+	case modeBench:
+		rightContent = m.bench.View()
+	case modeCompare:
+		rightContent = m.compare.View()
+	default:
 		rightContent = m.results.View()
 	}
 
@@ -88,6 +99,9 @@ func (m Model) renderView() string {
 	if m.overlay.IsOpen() {
 		return m.overlay.RenderOver(base)
 	}
+	if m.picker.IsOpen() {
+		return m.picker.RenderOver(base)
+	}
 	return base
 }
 
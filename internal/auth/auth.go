@@ -0,0 +1,115 @@
+// Package auth produces the Authorization header of an environment: static
+// Basic credentials or OAuth2 access tokens that are fetched from a token
+// endpoint, cached and refreshed before they expire.
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/qraqula/qla/internal/interp"
+)
+
+// Auth types.
+const (
+	TypeBasic             = "basic"              // username and password
+	TypeClientCredentials = "client_credentials" // OAuth2 client-credentials grant
+	TypeRefreshToken      = "refresh_token"      // OAuth2 refresh-token grant
+)
+
+// Types lists the auth types in the order the overlay cycles through them.
+var Types = []string{TypeBasic, TypeClientCredentials, TypeRefreshToken}
+
+// Config is the auth section of an environment. Every field may contain
+// {{name}} placeholders.
+type Config struct {
+	Type         string `json:"type"`
+	Username     string `json:"username,omitempty"`     // TypeBasic
+	Password     string `json:"password,omitempty"`     // TypeBasic
+	TokenURL     string `json:"tokenUrl,omitempty"`     // OAuth2 token endpoint
+	ClientID     string `json:"clientId,omitempty"`     // OAuth2 client
+	ClientSecret string `json:"clientSecret,omitempty"` // OAuth2 client; sent with HTTP Basic
+	Scope        string `json:"scope,omitempty"`        // space-separated OAuth2 scopes
+	RefreshToken string `json:"refreshToken,omitempty"` // TypeRefreshToken
+}
+
+// Expand returns c with its placeholders resolved and the names of those
+// no value was found for.
+func (c Config) Expand(lookup interp.Lookup) (Config, []string) {
+	var unresolved []string
+	for _, f := range []*string{&c.Username, &c.Password, &c.TokenURL, &c.ClientID, &c.ClientSecret, &c.Scope, &c.RefreshToken} {
+		var names []string
+		*f, names = interp.Expand(*f, lookup)
+		unresolved = append(unresolved, names...)
+	}
+	slices.Sort(unresolved)
+	return c, slices.Compact(unresolved)
+}
+
+// ResolveSecrets returns c with the secret references in its credentials
+// resolved by resolve.
+func (c Config) ResolveSecrets(resolve func(string) (string, error)) (Config, error) {
+	for _, f := range []*string{&c.Password, &c.ClientSecret, &c.RefreshToken} {
+		v, err := resolve(*f)
+		if err != nil {
+			return Config{}, err
+		}
+		*f = v
+	}
+	return c, nil
+}
+
+// Provider returns the value of the Authorization header for a request.
+type Provider interface {
+	Authorization(ctx context.Context) (string, error)
+}
+
+// Rotations keeps the refresh tokens a server issues in place of the
+// configured one, so that a restart does not send a revoked token.
+type Rotations interface {
+	// Rotated returns the refresh token last issued for configured.
+	Rotated(configured string) (string, bool)
+	// SaveRotated keeps token as the refresh token issued for configured.
+	SaveRotated(configured, token string) error
+}
+
+// New returns the provider for cfg. Token requests are sent with client.
+// Rotated refresh tokens are kept in rotations when not nil.
+func New(cfg Config, client *http.Client, rotations Rotations) (Provider, error) {
+	switch cfg.Type {
+	case TypeBasic:
+		if cfg.Username == "" {
+			return nil, errors.New("basic auth needs a username")
+		}
+		creds := base64.StdEncoding.EncodeToString([]byte(cfg.Username + ":" + cfg.Password))
+		return basic("Basic " + creds), nil
+	case TypeClientCredentials:
+		if cfg.TokenURL == "" || cfg.ClientID == "" {
+			return nil, errors.New("client credentials need a token URL and client ID")
+		}
+	case TypeRefreshToken:
+		if cfg.TokenURL == "" || cfg.RefreshToken == "" {
+			return nil, errors.New("refresh token auth needs a token URL and refresh token")
+		}
+	default:
+		return nil, fmt.Errorf("unknown auth type %q", cfg.Type)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	s := &tokenSource{cfg: cfg, client: client, rotations: rotations, refresh: cfg.RefreshToken}
+	if rotations != nil && cfg.Type == TypeRefreshToken {
+		if rt, ok := rotations.Rotated(cfg.RefreshToken); ok {
+			s.refresh = rt
+		}
+	}
+	return s, nil
+}
+
+type basic string
+
+func (b basic) Authorization(context.Context) (string, error) { return string(b), nil }
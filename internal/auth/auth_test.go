@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBasic(t *testing.T) {
+	p, err := New(Config{Type: TypeBasic, Username: "ada", Password: "s3cr3t"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := p.Authorization(context.Background())
+	if got != "Basic YWRhOnMzY3IzdA==" {
+		t.Errorf("unexpected header %q", got)
+	}
+}
+
+func TestNewValidates(t *testing.T) {
+	for _, cfg := range []Config{
+		{Type: TypeBasic},
+		{Type: TypeClientCredentials, TokenURL: "http://x/token"},
+		{Type: TypeRefreshToken, TokenURL: "http://x/token"},
+		{Type: "digest"},
+	} {
+		if _, err := New(cfg, nil, nil); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestClientCredentialsCachedUntilExpiry(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		user, pass, _ := r.BasicAuth()
+		_ = r.ParseForm()
+		if user != "cli" || pass != "sec" || r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "read" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client","error_description":"bad credentials"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"bearer","expires_in":3600}`, calls)
+	}))
+	defer srv.Close()
+
+	p, err := New(Config{Type: TypeClientCredentials, TokenURL: srv.URL, ClientID: "cli", ClientSecret: "sec", Scope: "read"}, srv.Client(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	src := p.(*tokenSource)
+	src.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for range 2 {
+		if got, err := p.Authorization(ctx); err != nil || got != "Bearer tok-1" {
+			t.Fatalf("unexpected %q, %v", got, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the token cached, got %d token requests", calls)
+	}
+
+	now = now.Add(time.Hour - expiryMargin)
+	if got, _ := p.Authorization(ctx); got != "Bearer tok-2" {
+		t.Errorf("expected a new token at expiry, got %q", got)
+	}
+
+	src.Invalidate()
+	if got, _ := p.Authorization(ctx); got != "Bearer tok-3" {
+		t.Errorf("expected a new token after invalidation, got %q", got)
+	}
+
+	bad, _ := New(Config{Type: TypeClientCredentials, TokenURL: srv.URL, ClientID: "cli", ClientSecret: "nope"}, srv.Client(), nil)
+	if _, err := bad.Authorization(ctx); err == nil || !strings.Contains(err.Error(), "invalid_client: bad credentials") {
+		t.Errorf("expected OAuth error, got %v", err)
+	}
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		seen = append(seen, r.Form.Get("refresh_token"))
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("client_id") != "app" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `{"access_token":"at-%d","expires_in":60,"refresh_token":"rt-%d"}`, len(seen), len(seen))
+	}))
+	defer srv.Close()
+
+	cfg := Config{Type: TypeRefreshToken, TokenURL: srv.URL, ClientID: "app", RefreshToken: "rt-0"}
+	rotations := memRotations{}
+	p, err := New(cfg, srv.Client(), rotations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	p.(*tokenSource).now = func() time.Time { return now }
+
+	if got, err := p.Authorization(context.Background()); err != nil || got != "Bearer at-1" {
+		t.Fatalf("unexpected %q, %v", got, err)
+	}
+	now = now.Add(time.Minute)
+	if got, _ := p.Authorization(context.Background()); got != "Bearer at-2" {
+		t.Errorf("expected a refreshed token, got %q", got)
+	}
+	if len(seen) != 2 || seen[0] != "rt-0" || seen[1] != "rt-1" {
+		t.Errorf("expected the rotated refresh token to be used, got %v", seen)
+	}
+
+	// A new session starts from the last rotated token.
+	if rotations["rt-0"] != "rt-2" {
+		t.Errorf("expected the rotated token saved, got %v", rotations)
+	}
+	p, _ = New(cfg, srv.Client(), rotations)
+	if _, err := p.Authorization(context.Background()); err != nil || seen[2] != "rt-2" {
+		t.Errorf("expected the saved token used after a restart, got %v (%v)", seen, err)
+	}
+}
+
+type memRotations map[string]string
+
+func (m memRotations) Rotated(configured string) (string, bool) {
+	token, ok := m[configured]
+	return token, ok
+}
+
+func (m memRotations) SaveRotated(configured, token string) error {
+	m[configured] = token
+	return nil
+}
+
+func TestShortLivedTokenCached(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"access_token":"tok","expires_in":20}`)
+	}))
+	defer srv.Close()
+
+	p, _ := New(Config{Type: TypeClientCredentials, TokenURL: srv.URL, ClientID: "cli"}, srv.Client(), nil)
+	now := time.Now()
+	p.(*tokenSource).now = func() time.Time { return now }
+	ctx := context.Background()
+	_, _ = p.Authorization(ctx)
+	now = now.Add(9 * time.Second)
+	_, _ = p.Authorization(ctx)
+	if calls != 1 {
+		t.Errorf("expected a 20s token cached, got %d token requests", calls)
+	}
+	now = now.Add(time.Second)
+	_, _ = p.Authorization(ctx)
+	if calls != 2 {
+		t.Errorf("expected a new token halfway through its lifetime, got %d token requests", calls)
+	}
+}
+
+func TestConfigExpand(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "secret" {
+			return "s3cr3t", true
+		}
+		return "", false
+	}
+	cfg, unresolved := Config{Type: TypeClientCredentials, ClientSecret: "{{secret}}", ClientID: "{{id}}"}.Expand(lookup)
+	if cfg.ClientSecret != "s3cr3t" || len(unresolved) != 1 || unresolved[0] != "id" {
+		t.Errorf("unexpected %+v, %v", cfg, unresolved)
+	}
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryMargin renews tokens this long before they expire so a request
+// never leaves with a token that lapses in flight. Short-lived tokens are
+// renewed halfway through their lifetime instead.
+const expiryMargin = 30 * time.Second
+
+// tokenSource fetches OAuth2 access tokens and caches them until shortly
+// before they expire.
+type tokenSource struct {
+	cfg       Config
+	client    *http.Client
+	rotations Rotations        // nil keeps rotated refresh tokens in memory only
+	now       func() time.Time // for tests; nil means time.Now
+
+	mu      sync.Mutex
+	token   string // "Bearer abc"
+	expiry  time.Time
+	refresh string // current refresh token; servers may rotate it
+}
+
+func (s *tokenSource) Authorization(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && (s.expiry.IsZero() || s.clock().Before(s.expiry)) {
+		return s.token, nil
+	}
+	if err := s.fetch(ctx); err != nil {
+		return "", err
+	}
+	return s.token, nil
+}
+
+// Invalidate drops the cached token, e.g. after the server rejected it.
+func (s *tokenSource) Invalidate() {
+	s.mu.Lock()
+	s.token = ""
+	s.mu.Unlock()
+}
+
+func (s *tokenSource) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (s *tokenSource) fetch(ctx context.Context) error {
+	form := url.Values{"grant_type": {s.cfg.Type}}
+	if s.cfg.Type == TypeRefreshToken {
+		form.Set("refresh_token", s.refresh)
+	}
+	if s.cfg.Scope != "" {
+		form.Set("scope", s.cfg.Scope)
+	}
+	// Confidential clients authenticate with HTTP Basic; public clients
+	// only identify themselves.
+	if s.cfg.ClientSecret == "" && s.cfg.ClientID != "" {
+		form.Set("client_id", s.cfg.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.cfg.ClientID), url.QueryEscape(s.cfg.ClientSecret))
+	}
+
+	start := s.clock()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("token request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("token response: %w", err)
+	}
+
+	var tok tokenResponse
+	jsonErr := json.Unmarshal(body, &tok)
+	switch {
+	case tok.Error != "" && tok.ErrorDescription != "":
+		return fmt.Errorf("token request: %s: %s", tok.Error, tok.ErrorDescription)
+	case tok.Error != "":
+		return fmt.Errorf("token request: %s", tok.Error)
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	case jsonErr != nil:
+		return fmt.Errorf("token response: %w", jsonErr)
+	case tok.AccessToken == "":
+		return fmt.Errorf("token response has no access_token")
+	}
+
+	tokenType := tok.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	s.token = tokenType + " " + tok.AccessToken
+	s.expiry = time.Time{}
+	if tok.ExpiresIn > 0 {
+		lifetime := time.Duration(tok.ExpiresIn) * time.Second
+		s.expiry = start.Add(lifetime - min(expiryMargin, lifetime/2))
+	}
+	if tok.RefreshToken != "" && tok.RefreshToken != s.refresh {
+		s.refresh = tok.RefreshToken
+		if s.rotations != nil && s.cfg.Type == TypeRefreshToken {
+			if err := s.rotations.SaveRotated(s.cfg.RefreshToken, s.refresh); err != nil {
+				return fmt.Errorf("save refresh token: %w", err)
+			}
+		}
+	}
+	return nil
+}
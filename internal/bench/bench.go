@@ -0,0 +1,222 @@
+// Package bench replays a request with several workers at once to measure
+// the latency and throughput of an endpoint.
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qraqula/qla/internal/graphql"
+)
+
+// Options are the settings of a benchmark. It sends Requests requests, or
+// as many as it can for Duration, whichever ends first.
+type Options struct {
+	Concurrency int           // workers sending at once
+	Requests    int           // total requests, 0 for no limit
+	Duration    time.Duration // how long to send, 0 for no limit
+	Rate        float64       // requests per second across workers, 0 for no limit
+}
+
+// DefaultOptions are the settings used when none are given.
+var DefaultOptions = Options{Concurrency: 10, Requests: 100}
+
+// Validate reports settings that cannot run.
+func (o Options) Validate() error {
+	switch {
+	case o.Concurrency < 1:
+		return errors.New("concurrency must be at least 1")
+	case o.Requests < 0 || o.Duration < 0 || o.Rate < 0:
+		return errors.New("requests, duration and rate cannot be negative")
+	case math.IsNaN(o.Rate) || math.IsInf(o.Rate, 0):
+		return errors.New("rate must be a finite number")
+	case o.Rate > 0 && o.interval() <= 0:
+		return fmt.Errorf("rate cannot exceed %d/s", time.Second)
+	case o.Requests == 0 && o.Duration == 0:
+		return errors.New("set a number of requests or a duration")
+	}
+	return nil
+}
+
+// interval returns the time between two requests at the rate limit.
+func (o Options) interval() time.Duration {
+	return time.Duration(float64(time.Second) / o.Rate)
+}
+
+// String formats o as ParseOptions reads it.
+func (o Options) String() string {
+	parts := []string{"concurrency=" + strconv.Itoa(o.Concurrency)}
+	if o.Requests > 0 {
+		parts = append(parts, "requests="+strconv.Itoa(o.Requests))
+	}
+	if o.Duration > 0 {
+		parts = append(parts, "duration="+o.Duration.String())
+	}
+	if o.Rate > 0 {
+		parts = append(parts, "rate="+strconv.FormatFloat(o.Rate, 'f', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParseOptions parses space-separated settings such as
+// "concurrency=20 duration=30s rate=50", with c, n and d short for
+// concurrency, requests and duration. Settings left out keep their default;
+// a duration alone lifts the default request limit.
+func ParseOptions(s string) (Options, error) {
+	o := DefaultOptions
+	requestsSet := false
+	for _, field := range strings.Fields(s) {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return Options{}, fmt.Errorf("invalid setting %q (use name=value)", field)
+		}
+		var err error
+		switch name {
+		case "concurrency", "c":
+			o.Concurrency, err = strconv.Atoi(value)
+		case "requests", "n":
+			o.Requests, err = strconv.Atoi(value)
+			requestsSet = true
+		case "duration", "d":
+			o.Duration, err = time.ParseDuration(value)
+		case "rate":
+			o.Rate, err = strconv.ParseFloat(strings.TrimSuffix(value, "/s"), 64)
+		default:
+			return Options{}, fmt.Errorf("unknown setting %q (have concurrency, requests, duration, rate)", name)
+		}
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid %s %q", name, value)
+		}
+	}
+	if o.Duration > 0 && !requestsSet {
+		o.Requests = 0
+	}
+	return o, o.Validate()
+}
+
+// Func sends one request.
+type Func func(ctx context.Context) (*graphql.Result, error)
+
+// Bench is one benchmark run. Its statistics can be read while it runs.
+type Bench struct {
+	opts Options
+	send Func
+
+	mu        sync.Mutex
+	start     time.Time
+	elapsed   time.Duration // set when the run is over
+	latencies []time.Duration
+	requests  int
+	errors    map[string]int
+	perSecond []int
+}
+
+// New returns a benchmark of send with opts, which must be valid.
+func New(opts Options, send Func) *Bench {
+	return &Bench{opts: opts, send: send, errors: make(map[string]int)}
+}
+
+// Run sends the requests and returns the statistics once the last one has
+// been answered. Cancelling ctx stops the run; requests it interrupts are
+// not counted.
+func (b *Bench) Run(ctx context.Context) Stats {
+	b.mu.Lock()
+	b.start = time.Now()
+	b.mu.Unlock()
+
+	// The duration only stops new requests: those in flight are waited for.
+	sched := ctx
+	if b.opts.Duration > 0 {
+		var cancel context.CancelFunc
+		sched, cancel = context.WithTimeout(ctx, b.opts.Duration)
+		defer cancel()
+	}
+	jobs := make(chan struct{})
+	go b.schedule(sched, jobs)
+
+	var wg sync.WaitGroup
+	for range b.opts.Concurrency {
+		wg.Go(func() {
+			for range jobs {
+				b.sendOne(ctx)
+			}
+		})
+	}
+	wg.Wait()
+
+	b.mu.Lock()
+	b.elapsed = time.Since(b.start)
+	b.mu.Unlock()
+	return b.Stats()
+}
+
+// schedule hands out the requests to send, paced by the rate limit, until
+// the count is reached or ctx is done.
+func (b *Bench) schedule(ctx context.Context, jobs chan<- struct{}) {
+	defer close(jobs)
+	var tick <-chan time.Time
+	if b.opts.Rate > 0 {
+		t := time.NewTicker(b.opts.interval())
+		defer t.Stop()
+		tick = t.C
+	}
+	for i := 0; b.opts.Requests == 0 || i < b.opts.Requests; i++ {
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case jobs <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Bench) sendOne(ctx context.Context) {
+	start := time.Now()
+	result, err := b.send(ctx)
+	end := time.Now()
+	if ctx.Err() != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests++
+	if err == nil {
+		b.latencies = append(b.latencies, end.Sub(start))
+	}
+	if failure := failure(result, err); failure != "" {
+		b.errors[failure]++
+	}
+	sec := int(end.Sub(b.start) / time.Second)
+	for len(b.perSecond) <= sec {
+		b.perSecond = append(b.perSecond, 0)
+	}
+	b.perSecond[sec]++
+}
+
+// failure describes why a request counts as an error, or returns "".
+func failure(r *graphql.Result, err error) string {
+	switch {
+	case err != nil:
+		return err.Error()
+	case r.StatusCode < 200 || r.StatusCode > 299:
+		return "HTTP " + strconv.Itoa(r.StatusCode)
+	case r.RawBody != nil:
+		return "response is not JSON"
+	case len(r.Response.Errors) > 0:
+		return r.Response.Errors[0].Message
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qraqula/qla/internal/graphql"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Options
+		err  string
+	}{
+		{in: "", want: DefaultOptions},
+		{in: "c=4 n=20 rate=10/s", want: Options{Concurrency: 4, Requests: 20, Rate: 10}},
+		{in: "concurrency=2 duration=30s", want: Options{Concurrency: 2, Duration: 30 * time.Second}},
+		{in: "d=5s requests=50", want: Options{Concurrency: 10, Requests: 50, Duration: 5 * time.Second}},
+		{in: "c=0", err: "concurrency must be at least 1"},
+		{in: "n=0", err: "set a number of requests or a duration"},
+		{in: "workers=3", err: `unknown setting "workers"`},
+		{in: "d=soon", err: `invalid d "soon"`},
+		{in: "fast", err: "use name=value"},
+		{in: "rate=Inf", err: "rate must be a finite number"},
+		{in: "rate=NaN", err: "rate must be a finite number"},
+		{in: "rate=1e10", err: "rate cannot exceed 1000000000/s"},
+	}
+	for _, tt := range tests {
+		got, err := ParseOptions(tt.in)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseOptions(%q): expected error %q, got %v", tt.in, tt.err, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseOptions(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+		if again, _ := ParseOptions(got.String()); again != got {
+			t.Errorf("ParseOptions(%q) does not round-trip: %+v", got.String(), again)
+		}
+	}
+}
+
+// sender sends { ping } to url with client.
+func sender(client *graphql.Client, url string) Func {
+	return func(ctx context.Context) (*graphql.Result, error) {
+		return client.Execute(ctx, url, graphql.Request{Query: "{ ping }"}, nil)
+	}
+}
+
+func TestRunCountsAndConcurrency(t *testing.T) {
+	var inFlight, peak, calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		switch calls.Add(1) % 10 {
+		case 0:
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"errors":[{"message":"overloaded"}]}`)
+		case 5:
+			fmt.Fprint(w, `{"data":null,"errors":[{"message":"timeout"}]}`)
+		default:
+			fmt.Fprint(w, `{"data":{"ping":true}}`)
+		}
+	}))
+	defer srv.Close()
+
+	client := graphql.NewClient(graphql.WithConnections(4))
+	s := New(Options{Concurrency: 4, Requests: 40}, sender(client, srv.URL)).Run(context.Background())
+
+	if s.Requests != 40 || calls.Load() != 40 || !s.Done {
+		t.Fatalf("expected 40 requests, got %d (server saw %d)", s.Requests, calls.Load())
+	}
+	if peak.Load() > 4 || peak.Load() < 2 {
+		t.Errorf("expected up to 4 requests at once, saw %d", peak.Load())
+	}
+	if s.Failed() != 8 || s.Errors["HTTP 500"] != 4 || s.Errors["timeout"] != 4 {
+		t.Errorf("expected 4 HTTP 500 and 4 GraphQL errors, got %v", s.Errors)
+	}
+	if s.Min < 5*time.Millisecond || s.P50 < s.Min || s.P90 < s.P50 || s.P99 < s.P90 || s.Max < s.P99 {
+		t.Errorf("expected ordered latencies, got min %s p50 %s p90 %s p99 %s max %s", s.Min, s.P50, s.P90, s.P99, s.Max)
+	}
+	sum := 0
+	for _, n := range s.Throughput {
+		sum += n
+	}
+	if sum != 40 || s.PerSecond() <= 0 {
+		t.Errorf("expected 40 requests over the throughput buckets, got %v", s.Throughput)
+	}
+}
+
+func TestRunDurationAndRate(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"data":{"ping":true}}`)
+	}))
+	defer srv.Close()
+
+	opts := Options{Concurrency: 5, Duration: 300 * time.Millisecond, Rate: 50}
+	s := New(opts, sender(graphql.NewClient(), srv.URL)).Run(context.Background())
+	// 50/s for 0.3s is 15 requests, the first sent at once.
+	if s.Requests < 10 || s.Requests > 17 {
+		t.Errorf("expected about 15 requests at 50/s for 300ms, got %d", s.Requests)
+	}
+	if s.Failed() != 0 || s.Elapsed < opts.Duration {
+		t.Errorf("expected no errors over the whole duration, got %v in %s", s.Errors, s.Elapsed)
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := New(Options{Concurrency: 3, Requests: 100}, sender(graphql.NewClient(), srv.URL))
+	time.AfterFunc(50*time.Millisecond, cancel)
+	s := b.Run(ctx)
+	if s.Requests != 0 || s.Failed() != 0 || !s.Done {
+		t.Errorf("expected interrupted requests not counted, got %d requests and %v", s.Requests, s.Errors)
+	}
+}
+
+func TestStats(t *testing.T) {
+	b := New(DefaultOptions, nil)
+	for i := 1; i <= 100; i++ {
+		b.latencies = append(b.latencies, time.Duration(101-i)*time.Millisecond)
+	}
+	b.errors = map[string]int{"HTTP 502": 2, "timeout": 5, "boom": 2}
+	s := b.Stats()
+	if s.P50 != 50*time.Millisecond || s.P90 != 90*time.Millisecond || s.P99 != 99*time.Millisecond {
+		t.Errorf("expected nearest-rank percentiles, got %s %s %s", s.P50, s.P90, s.P99)
+	}
+	if s.Min != time.Millisecond || s.Max != 100*time.Millisecond || s.Mean != 50500*time.Microsecond {
+		t.Errorf("unexpected min/mean/max %s %s %s", s.Min, s.Mean, s.Max)
+	}
+	got := fmt.Sprint(s.ErrorCounts())
+	if got != "[{timeout 5} {HTTP 502 2} {boom 2}]" {
+		t.Errorf("expected errors by count, got %s", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]int{0, 1, 2, 4, 8}, 0); got != "▁▁▂▄█" {
+		t.Errorf("got %q", got)
+	}
+	if got := Sparkline([]int{8, 0, 8}, 2); got != "▁█" {
+		t.Errorf("expected the last 2 values, got %q", got)
+	}
+	if got := Sparkline([]int{0, 0}, 5); got != "▁▁" {
+		t.Errorf("expected flat bars, got %q", got)
+	}
+}
@@ -0,0 +1,181 @@
+package bench
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// StartMsg asks the parent app to start a benchmark with Options.
+type StartMsg struct{ Options Options }
+
+// CloseMsg is returned to the parent app when the panel is left.
+type CloseMsg struct{}
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	dimStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	labelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	valueStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	sparkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+)
+
+// Model is the benchmark panel: the settings of the next run, edited in
+// place and started with enter, and the statistics of the current or last
+// run.
+type Model struct {
+	input   textinput.Model
+	target  string
+	stats   Stats
+	running bool
+	hasRun  bool
+	err     string
+	width   int
+	height  int
+}
+
+// NewModel returns a panel with the default settings.
+func NewModel() Model {
+	ti := textinput.New()
+	ti.Prompt = "settings "
+	ti.CharLimit = 100
+	ti.SetValue(DefaultOptions.String())
+	styles := ti.Styles()
+	styles.Focused.Prompt = labelStyle
+	styles.Blurred.Prompt = labelStyle
+	ti.SetStyles(styles)
+	return Model{input: ti}
+}
+
+// Focus lets the settings be edited.
+func (m *Model) Focus() tea.Cmd {
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// Start resets the statistics for a run of target, such as the operation
+// and the environment it is sent to.
+func (m *Model) Start(target string) {
+	m.target = target
+	m.stats = Stats{}
+	m.running = true
+	m.hasRun = true
+	m.err = ""
+	m.input.Blur()
+}
+
+// SetStats shows the statistics of the run so far, ending it when they are
+// final.
+func (m *Model) SetStats(s Stats) {
+	m.stats = s
+	if s.Done {
+		m.running = false
+	}
+}
+
+// Stop ends the run, as when it is aborted.
+func (m *Model) Stop() {
+	m.running = false
+}
+
+// Running reports whether a run is in progress.
+func (m Model) Running() bool { return m.running }
+
+// Stats returns the statistics of the current or last run.
+func (m Model) Stats() Stats { return m.stats }
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.input.SetWidth(max(w-len(m.input.Prompt)-1, 10))
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	kmsg, ok := msg.(tea.KeyPressMsg)
+	if !ok || m.running {
+		return m, nil
+	}
+	switch kmsg.String() {
+	case "enter":
+		opts, err := ParseOptions(m.input.Value())
+		if err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		m.err = ""
+		return m, func() tea.Msg { return StartMsg{Options: opts} }
+	case "esc":
+		return m, func() tea.Msg { return CloseMsg{} }
+	}
+	if !m.input.Focused() {
+		return m, m.Focus()
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	w := m.width - 2
+	title := titleStyle.Render(" Bench ")
+	if m.target != "" {
+		title += " " + valueStyle.Render(m.target)
+	}
+	lines := []string{ansi.Truncate(title, w, "…"), m.input.View()}
+	if m.err != "" {
+		lines = append(lines, ansi.Truncate(errorStyle.Render(m.err), w, "…"))
+	}
+	lines = append(lines, "")
+	if !m.hasRun {
+		lines = append(lines, dimStyle.Render("  ↵ to send the query with these settings"))
+		return strings.Join(lines, "\n")
+	}
+
+	s := m.stats
+	row := func(label, value string) string {
+		return ansi.Truncate(labelStyle.Render(fmt.Sprintf("%-11s", label))+value, w, "…")
+	}
+	progress := fmt.Sprint(s.Requests)
+	if s.Options.Requests > 0 {
+		progress += fmt.Sprintf("/%d", s.Options.Requests)
+	}
+	summary := fmt.Sprintf("%s · %.1f req/s · %s", progress, s.PerSecond(), s.Elapsed.Round(100*time.Millisecond))
+	if m.running {
+		summary += dimStyle.Render(" · running")
+	}
+	lines = append(lines, row("requests", valueStyle.Render(summary)))
+	errors := dimStyle.Render("none")
+	if n := s.Failed(); n > 0 {
+		errors = errorStyle.Render(fmt.Sprintf("%d (%.1f%%)", n, 100*float64(n)/float64(s.Requests)))
+	}
+	lines = append(lines,
+		row("errors", errors),
+		row("latency", valueStyle.Render(fmt.Sprintf("p50 %s  p90 %s  p99 %s", roundLatency(s.P50), roundLatency(s.P90), roundLatency(s.P99)))),
+		row("", dimStyle.Render(fmt.Sprintf("min %s  mean %s  max %s", roundLatency(s.Min), roundLatency(s.Mean), roundLatency(s.Max)))),
+		row("req/s", sparkStyle.Render(Sparkline(s.Throughput, w-11))),
+	)
+	if counts := s.ErrorCounts(); len(counts) > 0 {
+		lines = append(lines, "", labelStyle.Render("failures"))
+		for _, c := range counts {
+			lines = append(lines, ansi.Truncate(fmt.Sprintf("  %s %s", errorStyle.Render(fmt.Sprintf("%d×", c.Count)), c.Reason), w, "…"))
+		}
+	}
+	if m.height > 0 && len(lines) > m.height {
+		lines = lines[:m.height]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// roundLatency rounds d for display.
+func roundLatency(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond).String()
+	}
+	return d.Round(100 * time.Microsecond).String()
+}
@@ -0,0 +1,121 @@
+package bench
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Stats summarizes the requests of a benchmark answered so far. Latencies
+// are those of the requests that got a response, errors included.
+type Stats struct {
+	Options  Options
+	Requests int            // requests answered or failed
+	Errors   map[string]int // failed requests by reason
+	Elapsed  time.Duration
+	Done     bool
+
+	Min, Mean, Max time.Duration
+	P50, P90, P99  time.Duration
+
+	// Throughput counts the requests completed in each second of the run.
+	Throughput []int
+}
+
+// Stats returns the statistics of the run so far.
+func (b *Bench) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := Stats{
+		Options:    b.opts,
+		Requests:   b.requests,
+		Errors:     maps.Clone(b.errors),
+		Elapsed:    b.elapsed,
+		Done:       b.elapsed > 0,
+		Throughput: slices.Clone(b.perSecond),
+	}
+	if !s.Done && !b.start.IsZero() {
+		s.Elapsed = time.Since(b.start)
+	}
+	if len(b.latencies) == 0 {
+		return s
+	}
+	sorted := slices.Clone(b.latencies)
+	slices.Sort(sorted)
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	s.Min, s.Max = sorted[0], sorted[len(sorted)-1]
+	s.Mean = sum / time.Duration(len(sorted))
+	s.P50 = percentile(sorted, 50)
+	s.P90 = percentile(sorted, 90)
+	s.P99 = percentile(sorted, 99)
+	return s
+}
+
+// percentile returns the nearest-rank percentile p of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+// Failed returns the number of failed requests.
+func (s Stats) Failed() int {
+	n := 0
+	for _, c := range s.Errors {
+		n += c
+	}
+	return n
+}
+
+// PerSecond returns the average number of requests completed per second.
+func (s Stats) PerSecond() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Requests) / s.Elapsed.Seconds()
+}
+
+// ErrorCount is how often requests failed for a reason.
+type ErrorCount struct {
+	Reason string
+	Count  int
+}
+
+// ErrorCounts returns the failure reasons, most frequent first.
+func (s Stats) ErrorCounts() []ErrorCount {
+	out := make([]ErrorCount, 0, len(s.Errors))
+	for reason, n := range s.Errors {
+		out = append(out, ErrorCount{reason, n})
+	}
+	slices.SortFunc(out, func(a, b ErrorCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Reason, b.Reason))
+	})
+	return out
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws the last width values as bars scaled to the largest.
+func Sparkline(values []int, width int) string {
+	if width > 0 && len(values) > width {
+		values = values[len(values)-width:]
+	}
+	top := 0
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if top > 0 {
+			i = v * (len(sparks) - 1) / top
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/qraqula/qla/internal/bench"
+	"github.com/qraqula/qla/internal/graphql"
+)
+
+const benchUsage = `Usage: qla bench [flags] <file.graphql | ->
+       qla bench [flags] --entry <id or name>
+
+Sends a query or mutation over and over from several workers at once, with
+an environment's endpoint, headers, variables and auth, and reports the
+latency percentiles, the errors and the requests completed per second.
+Runs --requests requests, or as many as fit in --duration. Exits with 1
+when any request fails.
+
+Flags:
+`
+
+// Bench implements qla bench.
+func Bench(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, benchUsage)
+		fs.PrintDefaults()
+	}
+	cf := addConfigFlags(fs)
+	rf := addRequestFlags(fs)
+	opts := bench.DefaultOptions
+	fs.IntVar(&opts.Concurrency, "concurrency", opts.Concurrency, "`number` of requests sent at once")
+	fs.IntVar(&opts.Requests, "requests", opts.Requests, "total `number` of requests, 0 for no limit (the default with --duration)")
+	fs.DurationVar(&opts.Duration, "duration", 0, "send requests for this long, e.g. 30s")
+	fs.Float64Var(&opts.Rate, "rate", 0, "at most this many requests per `second` across workers")
+	asJSON := fs.Bool("json", false, "print the statistics as JSON")
+
+	files, err := parse(fs, args)
+	if err != nil {
+		return usageError(err)
+	}
+	fail := func(code int, err error) int {
+		fmt.Fprintln(stderr, "qla bench:", err)
+		return code
+	}
+	if (len(files) == 1) == (rf.entry != "") || len(files) > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	requestsSet := false
+	fs.Visit(func(f *flag.Flag) { requestsSet = requestsSet || f.Name == "requests" })
+	if opts.Duration > 0 && !requestsSet {
+		opts.Requests = 0
+	}
+	if err := opts.Validate(); err != nil {
+		return fail(ExitUsage, err)
+	}
+
+	prepared, err := rf.prepare(cf, files, graphql.WithConnections(opts.Concurrency))
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	s := bench.New(opts, prepared.ExecuteAgain).Run(ctx)
+	if *asJSON {
+		writeBenchJSON(stdout, s)
+	} else {
+		writeBench(stdout, s)
+	}
+	if ctx.Err() != nil {
+		return fail(ExitFailed, fmt.Errorf("interrupted after %d requests", s.Requests))
+	}
+	if s.Failed() > 0 {
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+func writeBench(w io.Writer, s bench.Stats) {
+	fmt.Fprintf(w, "requests   %d in %s · %.1f req/s\n", s.Requests, s.Elapsed.Round(time.Millisecond), s.PerSecond())
+	if n := s.Failed(); n > 0 {
+		fmt.Fprintf(w, "errors     %d (%.1f%%)\n", n, 100*float64(n)/float64(s.Requests))
+	} else {
+		fmt.Fprintln(w, "errors     none")
+	}
+	fmt.Fprintf(w, "latency    p50 %s  p90 %s  p99 %s\n", round(s.P50), round(s.P90), round(s.P99))
+	fmt.Fprintf(w, "           min %s  mean %s  max %s\n", round(s.Min), round(s.Mean), round(s.Max))
+	fmt.Fprintf(w, "req/s      %s\n", bench.Sparkline(s.Throughput, 60))
+	if counts := s.ErrorCounts(); len(counts) > 0 {
+		fmt.Fprintln(w, "\nfailures")
+		for _, c := range counts {
+			fmt.Fprintf(w, "  %d× %s\n", c.Count, c.Reason)
+		}
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(100 * time.Microsecond)
+}
+
+func writeBenchJSON(w io.Writer, s bench.Stats) {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	out := struct {
+		Requests   int                `json:"requests"`
+		Errors     int                `json:"errors"`
+		Failures   map[string]int     `json:"failures"`
+		ElapsedMs  float64            `json:"elapsedMs"`
+		PerSecond  float64            `json:"perSecond"`
+		LatencyMs  map[string]float64 `json:"latencyMs"`
+		Throughput []int              `json:"throughput"`
+	}{
+		Requests:  s.Requests,
+		Errors:    s.Failed(),
+		Failures:  s.Errors,
+		ElapsedMs: ms(s.Elapsed),
+		PerSecond: s.PerSecond(),
+		LatencyMs: map[string]float64{
+			"min": ms(s.Min), "mean": ms(s.Mean), "max": ms(s.Max),
+			"p50": ms(s.P50), "p90": ms(s.P90), "p99": ms(s.P99),
+		},
+		Throughput: s.Throughput,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func benchCmd(args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := Bench(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestBench(t *testing.T) {
+	srv := testServer(t)
+	dir := testConfigDir(t, srv.URL)
+	query := writeFile(t, "q.graphql", `query Q($fail: Boolean) { user }`)
+
+	code, out, errOut := benchCmd(query, "--config", dir, "--env", "dev", "--concurrency", "3", "--requests", "12")
+	if code != ExitOK {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	for _, want := range []string{"requests   12 in", "errors     none", "latency    p50 ", "req/s      "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got %q", want, out)
+		}
+	}
+
+	code, out, _ = benchCmd(query, "--config", dir, "--env", "dev", "--requests", "5", "--var", "fail=true", "--json")
+	var stats struct {
+		Requests  int
+		Errors    int
+		Failures  map[string]int
+		LatencyMs map[string]float64
+	}
+	if err := json.Unmarshal([]byte(out), &stats); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, out)
+	}
+	if code != ExitFailed || stats.Requests != 5 || stats.Errors != 5 || stats.Failures["boom"] != 5 {
+		t.Errorf("expected 5 failed requests and exit 1, got %d %+v", code, stats)
+	}
+	if _, ok := stats.LatencyMs["p99"]; !ok {
+		t.Errorf("expected latency percentiles, got %v", stats.LatencyMs)
+	}
+
+	if code, _, errOut := benchCmd(query, "--config", dir, "--concurrency", "0"); code != ExitUsage || !strings.Contains(errOut, "concurrency must be at least 1") {
+		t.Errorf("expected invalid settings rejected, got %d %q", code, errOut)
+	}
+}
@@ -0,0 +1,120 @@
+// Package cli implements the headless qla subcommands for scripts and CI.
+// They read the same configuration, workspace and history as the TUI.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/qraqula/qla/internal/config"
+)
+
+// Command runs a subcommand with its arguments and returns the exit code.
+type Command func(args []string, stdout, stderr io.Writer) int
+
+// Commands are the subcommands by name.
+var Commands = map[string]Command{
+	"bench":   Bench,
+	"compare": Compare,
+	"lint":    Lint,
+	"run":     Run,
+	"schema":  Schema,
+	"trust":   Trust,
+}
+
+// Exit codes shared by the subcommands.
+const (
+	ExitOK     = 0
+	ExitFailed = 1 // the request failed or the result has errors
+	ExitUsage  = 2 // invalid arguments or configuration
+)
+
+// parse parses args with fs, allowing flags after positional arguments, and
+// returns the positional ones.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// usageError reports a flag parsing error: asking for help is not one.
+func usageError(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	return ExitUsage
+}
+
+// listFlag collects the values of a repeatable flag.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ", ") }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// configFlags are the flags selecting the configuration and environment.
+type configFlags struct {
+	dir string
+	env string
+
+	warn io.Writer // told once about an untrusted workspace; nil for never
+}
+
+func addConfigFlags(fs *flag.FlagSet) *configFlags {
+	f := configFlags{warn: fs.Output()}
+	fs.StringVar(&f.dir, "config", config.DefaultDir(), "configuration `directory`")
+	fs.StringVar(&f.env, "env", "", "`environment` to use (default: the active one)")
+	return &f
+}
+
+// open loads the configuration with the workspace of the working directory
+// layered over it and the environment of the flags made active. An
+// untrusted workspace is left out with a warning.
+func (f *configFlags) open() (*config.Store, error) {
+	store := config.NewStore(f.dir)
+	if err := store.Load(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if ws := config.FindWorkspace("."); ws != "" {
+		err := store.UseWorkspace(ws)
+		if errors.Is(err, config.ErrUntrusted) {
+			if f.warn != nil {
+				fmt.Fprintln(f.warn, "qla: workspace", err)
+				f.warn = nil
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("workspace: %w", err)
+		}
+	}
+	if f.env != "" {
+		if err := useEnv(&store.Config, f.env); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// useEnv makes the environment name active.
+func useEnv(cfg *config.Config, name string) error {
+	if !slices.Contains(cfg.EnvNames(), name) {
+		return fmt.Errorf("no environment %q (have %s)", name, strings.Join(cfg.EnvNames(), ", "))
+	}
+	cfg.ActiveEnv = name
+	return nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/qraqula/qla/internal/compare"
+	"github.com/qraqula/qla/internal/jsondiff"
+)
+
+const compareUsage = `Usage: qla compare [flags] --with <env> <file.graphql | ->
+       qla compare [flags] --with <env> --entry <id or name>
+
+Sends a query to two environments at once, each with its own endpoint,
+headers, variables and auth, and lists the fields whose values differ
+between the responses: + only in the --with environment, - only in the
+first one, ~ changed. Paths matching an --ignore pattern or the config's
+compareIgnore list are left out. Exits with 1 when the responses differ or
+a request fails.
+
+Flags:
+`
+
+// Compare implements qla compare.
+func Compare(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, compareUsage)
+		fs.PrintDefaults()
+	}
+	cf := addConfigFlags(fs)
+	rf := addRequestFlags(fs)
+	with := fs.String("with", "", "`environment` to compare with")
+	var ignore listFlag
+	fs.Var(&ignore, "ignore", "leave out the `path`, e.g. data.items[*].updatedAt or updatedAt (repeatable)")
+	asJSON := fs.Bool("json", false, "print the differences as JSON")
+
+	files, err := parse(fs, args)
+	if err != nil {
+		return usageError(err)
+	}
+	fail := func(code int, err error) int {
+		fmt.Fprintln(stderr, "qla compare:", err)
+		return code
+	}
+	if *with == "" || (len(files) == 1) == (rf.entry != "") || len(files) > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	store, err := cf.open()
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	a, err := rf.prepare(cf, files)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	// The endpoint flag only overrides the first environment's.
+	rb := *rf
+	rb.endpoint = ""
+	b, err := rb.prepare(&configFlags{dir: cf.dir, env: *with}, files)
+	if err != nil {
+		return fail(ExitUsage, fmt.Errorf("%s: %w", *with, err))
+	}
+
+	// The first environment is the entry's when it is not chosen.
+	envA := cmp.Or(cf.env, store.Config.ActiveEnv)
+	if rf.entry != "" && cf.env == "" {
+		if e, err := findEntry(store, rf.entry); err == nil {
+			envA = e.EnvName
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	sa, sb := compare.Run(ctx, cmp.Or(envA, "(no environment)"), a.Execute, *with, b.Execute)
+	if ctx.Err() != nil {
+		return fail(ExitFailed, errors.New("interrupted"))
+	}
+	c := compare.New(sa, sb)
+	for _, s := range []compare.Side{sa, sb} {
+		if s.Err != nil {
+			return fail(ExitFailed, fmt.Errorf("%s: %w", s.Env, s.Err))
+		}
+	}
+	changes, ignored := c.Changes(append(ignore, store.Config.CompareIgnore...))
+	if *asJSON {
+		writeCompareJSON(stdout, changes, ignored)
+	} else {
+		writeCompare(stdout, sa.Env, sb.Env, changes, ignored)
+	}
+	if len(changes) > 0 {
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+func writeCompare(w io.Writer, envA, envB string, changes []jsondiff.Change, ignored int) {
+	for _, c := range changes {
+		switch c.Kind {
+		case jsondiff.Added:
+			fmt.Fprintf(w, "+ %s  %s\n", c.PathString(), compare.FormatValue(c.New))
+		case jsondiff.Removed:
+			fmt.Fprintf(w, "- %s  %s\n", c.PathString(), compare.FormatValue(c.Old))
+		default:
+			fmt.Fprintf(w, "~ %s  %s → %s\n", c.PathString(), compare.FormatValue(c.Old), compare.FormatValue(c.New))
+		}
+	}
+	if len(changes) > 0 {
+		fmt.Fprintln(w)
+	}
+	summary := fmt.Sprintf("%s → %s: %d differences", envA, envB, len(changes))
+	if len(changes) == 1 {
+		summary = fmt.Sprintf("%s → %s: 1 difference", envA, envB)
+	}
+	if ignored > 0 {
+		summary += fmt.Sprintf(", %d ignored", ignored)
+	}
+	fmt.Fprintln(w, summary)
+}
+
+func writeCompareJSON(w io.Writer, changes []jsondiff.Change, ignored int) {
+	type change struct {
+		Path string `json:"path"`
+		Kind string `json:"kind"`
+		Old  any    `json:"old,omitempty"`
+		New  any    `json:"new,omitempty"`
+	}
+	out := struct {
+		Changes []change `json:"changes"`
+		Ignored int      `json:"ignored"`
+	}{Changes: []change{}, Ignored: ignored}
+	for _, c := range changes {
+		out.Changes = append(out.Changes, change{Path: c.PathString(), Kind: c.Kind.String(), Old: c.Old, New: c.New})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
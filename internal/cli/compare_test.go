@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/qraqula/qla/internal/config"
+)
+
+func compareCmd(args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := Compare(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCompare(t *testing.T) {
+	srv := testServer(t)
+	dir := testConfigDir(t, srv.URL)
+	store := config.NewStore(dir)
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	prod := store.Config.Environments[0]
+	prod.Name = "prod"
+	prod.Values = map[string]string{"tenant": "t-2"}
+	store.Config.Environments = append(store.Config.Environments, prod)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	query := writeFile(t, "q.graphql", `query Q($limit: Int) { user }`)
+
+	code, out, errOut := compareCmd(query, "--config", dir, "--env", "dev", "--with", "prod")
+	if code != ExitFailed {
+		t.Fatalf("expected exit 1 on differences, got %d: %s", code, errOut)
+	}
+	want := "~ data.tenant  \"t-1\" → \"t-2\"\n\ndev → prod: 1 difference\n"
+	if out != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+
+	code, out, _ = compareCmd(query, "--config", dir, "--env", "dev", "--with", "prod", "--ignore", "tenant", "--json")
+	var res struct {
+		Changes []any
+		Ignored int
+	}
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, out)
+	}
+	if code != ExitOK || len(res.Changes) != 0 || res.Ignored != 1 {
+		t.Errorf("expected the tenant ignored and exit 0, got %d %+v", code, res)
+	}
+
+	if code, _, errOut := compareCmd(query, "--config", dir, "--env", "dev", "--with", "qa"); code != ExitUsage || !strings.Contains(errOut, `no environment "qa"`) {
+		t.Errorf("expected an unknown environment rejected, got %d %q", code, errOut)
+	}
+	if code, _, _ := compareCmd(query, "--config", dir); code != ExitUsage {
+		t.Errorf("expected --with required, got %d", code)
+	}
+}
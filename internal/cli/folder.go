@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/qraqula/qla/internal/history"
+	"github.com/qraqula/qla/internal/secret"
+	"github.com/qraqula/qla/internal/suite"
+)
+
+// runFolder implements qla run --folder: it runs the entries of a history
+// folder as a suite, printing a line per entry and the failed checks.
+func runFolder(cf *configFlags, name, junit string, stdout, stderr io.Writer) int {
+	fail := func(code int, err error) int {
+		fmt.Fprintln(stderr, "qla run:", err)
+		return code
+	}
+	store, err := cf.open()
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	hist, err := openHistory(store)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	i := slices.IndexFunc(hist.Folders(), func(f history.Folder) bool { return f.Name == name })
+	if i < 0 {
+		return fail(ExitUsage, fmt.Errorf("no history folder %q", name))
+	}
+	entries := suite.Order(hist.Folders()[i].Entries)
+	if len(entries) == 0 {
+		return fail(ExitUsage, fmt.Errorf("no entries in %s", name))
+	}
+	runner, err := suite.New(&store.Config, secret.NewResolver(cf.dir), nil)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	start := time.Now()
+	results := runner.Run(ctx, entries, func(r suite.Result) { writeSuiteResult(stdout, r) })
+	passed, failed, total := suite.Summary(results)
+	fmt.Fprintf(stdout, "\n%d passed, %d failed in %s\n", passed, failed, total.Round(time.Millisecond))
+
+	if junit != "" {
+		f, err := os.Create(junit)
+		if err != nil {
+			return fail(ExitFailed, err)
+		}
+		err = suite.WriteJUnit(f, name, start, results)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fail(ExitFailed, err)
+		}
+	}
+	if ctx.Err() != nil {
+		return fail(ExitFailed, fmt.Errorf("interrupted, %d of %d entries run", len(results), len(entries)))
+	}
+	if failed > 0 {
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+func writeSuiteResult(w io.Writer, r suite.Result) {
+	mark := "✓"
+	if !r.Passed() {
+		mark = "✗"
+	}
+	status := "---"
+	if r.Status != 0 {
+		status = fmt.Sprint(r.Status)
+	}
+	fmt.Fprintf(w, "%s %s  %s  %s\n", mark, r.Entry.Name, status, r.Duration.Round(time.Millisecond))
+	for _, f := range r.Failures() {
+		fmt.Fprintln(w, "    "+f)
+	}
+}
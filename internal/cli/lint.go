@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/qraqula/qla/internal/secret"
+	"github.com/qraqula/qla/internal/validate"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const lintUsage = `Usage: qla lint [flags] <file, directory or glob>...
+
+Checks the operations of .graphql files against a schema and reports every
+error with its file, line and column. Directories are searched for .graphql
+and .gql files. Fragments may be defined in any of the files. Exits with 1
+when there are errors.
+
+The schema is --schema, an http(s) URL, an environment or a file
+(introspection JSON when it ends in .json, SDL otherwise), and the active
+environment's endpoint by default.
+
+Flags:
+`
+
+// Lint implements qla lint.
+func Lint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, lintUsage)
+		flags.PrintDefaults()
+	}
+	cf := addConfigFlags(flags)
+	schemaFrom := flags.String("schema", "", "`URL, environment or file` of the schema")
+	format := flags.String("format", "text", "output `format`: text, json or github (workflow annotations)")
+	var headers listFlag
+	flags.Var(&headers, "header", "header `\"Key: Value\"` sent when introspecting (repeatable)")
+	patterns, err := parse(flags, args)
+	if err != nil {
+		return usageError(err)
+	}
+	fail := func(code int, err error) int {
+		fmt.Fprintln(stderr, "qla lint:", err)
+		return code
+	}
+	if len(patterns) == 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+	write, ok := map[string]func(io.Writer, []validate.Problem){
+		"text":   writeProblems,
+		"json":   writeProblemsJSON,
+		"github": writeAnnotations,
+	}[*format]
+	if !ok {
+		return fail(ExitUsage, fmt.Errorf("--format %q: want text, json or github", *format))
+	}
+	files, err := expandFiles(patterns)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	sources := make([]*ast.Source, len(files))
+	for i, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return fail(ExitUsage, err)
+		}
+		sources[i] = &ast.Source{Name: f, Input: string(data)}
+	}
+
+	store, err := cf.open()
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	extra, err := parseHeaders(headers)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	src := *schemaFrom
+	if src == "" {
+		src = store.Config.ActiveEnv
+	}
+	if src == "" {
+		return fail(ExitUsage, errors.New("no schema: set --schema or --env"))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	s, err := schemaSource{cfg: &store.Config, secrets: secret.NewResolver(cf.dir), headers: extra}.load(ctx, src)
+	if err != nil {
+		return fail(ExitFailed, err)
+	}
+
+	problems := validate.Lint(sources, s)
+	write(stdout, problems)
+	if len(problems) > 0 {
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+// expandFiles resolves the file, directory and glob arguments to the files
+// they name, in order and without duplicates.
+func expandFiles(patterns []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	for _, p := range patterns {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: no such file", p)
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(m)
+				continue
+			}
+			err = filepath.WalkDir(m, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if ext := filepath.Ext(path); !d.IsDir() && (ext == ".graphql" || ext == ".gql") {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+func writeProblems(w io.Writer, problems []validate.Problem) {
+	files := make(map[string]bool)
+	for _, p := range problems {
+		files[p.File] = true
+		if p.Line == 0 {
+			fmt.Fprintf(w, "%s: %s\n", p.File, p.Message)
+		} else {
+			fmt.Fprintf(w, "%s:%d:%d: %s\n", p.File, p.Line, p.Column, p.Message)
+		}
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(w, "\n%s in %s\n", count(len(problems), "error"), count(len(files), "file"))
+	}
+}
+
+// count formats n with noun, in the plural unless n is 1.
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func writeProblemsJSON(w io.Writer, problems []validate.Problem) {
+	type problem struct {
+		File    string `json:"file"`
+		Line    int    `json:"line"`
+		Column  int    `json:"column"`
+		Message string `json:"message"`
+		Rule    string `json:"rule,omitempty"`
+	}
+	out := make([]problem, len(problems))
+	for i, p := range problems {
+		out[i] = problem(p)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
+
+// writeAnnotations writes the problems as GitHub Actions workflow commands,
+// which annotate the lines in the checks and pull request views.
+func writeAnnotations(w io.Writer, problems []validate.Problem) {
+	data := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	property := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	for _, p := range problems {
+		props := "file=" + property.Replace(filepath.ToSlash(p.File))
+		if p.Line > 0 {
+			props += fmt.Sprintf(",line=%d,col=%d", p.Line, p.Column)
+		}
+		if p.Rule != "" {
+			props += ",title=" + property.Replace(p.Rule)
+		}
+		fmt.Fprintf(w, "::error %s::%s\n", props, data.Replace(p.Message))
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func lintCmd(args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := Lint(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// lintTree writes operations into a directory tree and returns its root.
+func lintTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"ops/users.graphql":     "query User($id: ID!) {\n  user(id: $id, extra: 1)\n  ...Name\n  missing\n}\n",
+		"ops/fragments.graphql": "fragment Name on Query {\n  user(id: 1)\n}\n",
+		"ops/ok.gql":            "query Ok { user(id: 1) }\n",
+		"ops/readme.md":         "not GraphQL",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLintReportsEveryError(t *testing.T) {
+	srv := introspectionServer(t)
+	dir := testConfigDir(t, srv.URL)
+	root := lintTree(t)
+	users := filepath.Join(root, "ops", "users.graphql")
+
+	code, out, errOut := lintCmd("--config", dir, "--env", "dev", filepath.Join(root, "ops"))
+	if code != ExitFailed {
+		t.Fatalf("expected exit 1, got %d: %s", code, errOut)
+	}
+	for _, want := range []string{
+		users + `:2:3: Unknown argument "extra" on field "Query.user".`,
+		users + `:4:3: Cannot query field "missing" on type "Query".`,
+		"2 errors in 1 file\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	code, out, _ = lintCmd("--config", dir, "--env", "dev", "--format", "github", users)
+	if code != ExitFailed || !strings.Contains(out, "::error file="+users+",line=2,col=3,title=KnownArgumentNames::Unknown argument") {
+		t.Errorf("expected annotations, got %d:\n%s", code, out)
+	}
+
+	// Without fragments.graphql the spread is unknown
+	code, out, _ = lintCmd("--config", dir, "--env", "dev", "--format", "json", users)
+	var problems []map[string]any
+	if err := json.Unmarshal([]byte(out), &problems); err != nil || len(problems) != 3 || problems[1]["rule"] != "KnownFragmentNames" {
+		t.Errorf("expected JSON problems, got %v %q", err, out)
+	}
+}
+
+func TestLintSchemaFile(t *testing.T) {
+	root := lintTree(t)
+	sdl := writeFile(t, "schema.graphql", "type Query { user(id: ID!): String }\n")
+
+	code, out, errOut := lintCmd("--config", t.TempDir(), "--schema", sdl, filepath.Join(root, "ops", "*.gql"))
+	if code != ExitOK || out != "" {
+		t.Errorf("expected no errors, got %d %q %q", code, out, errOut)
+	}
+	one := writeFile(t, "one.graphql", "{ missing }\n")
+	if code, out, _ := lintCmd("--config", t.TempDir(), "--schema", sdl, one); code != ExitFailed || !strings.HasSuffix(out, "\n1 error in 1 file\n") {
+		t.Errorf("expected the singular for one error, got %d %q", code, out)
+	}
+	if code, _, errOut := lintCmd("--config", t.TempDir(), filepath.Join(root, "ops")); code != ExitUsage || !strings.Contains(errOut, "no schema") {
+		t.Errorf("expected a missing schema refused, got %d %q", code, errOut)
+	}
+	if code, _, errOut := lintCmd("--config", t.TempDir(), "--schema", sdl, "missing/*.graphql"); code != ExitUsage || !strings.Contains(errOut, "no such file") {
+		t.Errorf("expected an empty glob refused, got %d %q", code, errOut)
+	}
+}
@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/history"
+	"github.com/qraqula/qla/internal/interp"
+	"github.com/qraqula/qla/internal/request"
+	"github.com/qraqula/qla/internal/secret"
+	"github.com/qraqula/qla/internal/validate"
+)
+
+const runUsage = `Usage: qla run [flags] <file.graphql | ->
+       qla run [flags] --entry <id or name>
+       qla run [--env name] [--junit file] --folder <name>
+
+Runs a query or mutation with an environment's endpoint, headers, variables
+and auth, as the TUI would, and prints the JSON response. Exits with 1 when
+the request fails, the HTTP status is not 2xx or the response has errors.
+
+With --folder, runs the entries of a history folder in the order they were
+saved and checks their # qla:assert comments. Exits with 1 when any fails.
+
+Flags:
+`
+
+// Run implements qla run.
+func Run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, runUsage)
+		fs.PrintDefaults()
+	}
+	cf := addConfigFlags(fs)
+	rf := addRequestFlags(fs)
+	folder := fs.String("folder", "", "run the entries of the history folder `name` as a suite")
+	junit := fs.String("junit", "", "with --folder, also write the results as JUnit XML to `file`")
+
+	files, err := parse(fs, args)
+	if err != nil {
+		return usageError(err)
+	}
+	fail := func(code int, err error) int {
+		fmt.Fprintln(stderr, "qla run:", err)
+		return code
+	}
+	if *folder != "" {
+		if len(files) > 0 || rf.set() {
+			fs.Usage()
+			return ExitUsage
+		}
+		return runFolder(cf, *folder, *junit, stdout, stderr)
+	}
+	if *junit != "" || (len(files) == 1) == (rf.entry != "") || len(files) > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+
+	prepared, err := rf.prepare(cf, files)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	if rf.entry != "" && len(prepared.Dynamic) > 0 {
+		fmt.Fprintln(stderr, "qla run: replaying", interp.FormatValues(prepared.Dynamic))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := prepared.Execute(ctx)
+	if err != nil {
+		return fail(ExitFailed, err)
+	}
+	return writeResult(stdout, stderr, result)
+}
+
+// requestFlags select the request qla run, bench and compare send: a query
+// file or a saved history entry, and what to override of it.
+type requestFlags struct {
+	entry     string
+	endpoint  string
+	operation string
+	varsFile  string
+	vars      listFlag
+	headers   listFlag
+
+	query string // of the file, once read: stdin cannot be read twice
+}
+
+func addRequestFlags(fs *flag.FlagSet) *requestFlags {
+	var f requestFlags
+	fs.StringVar(&f.entry, "entry", "", "run the saved history entry with this `ID or name`")
+	fs.StringVar(&f.endpoint, "endpoint", "", "endpoint `URL`, overriding the environment's")
+	fs.StringVar(&f.operation, "operation", "", "`name` of the operation to run in a document with several")
+	fs.StringVar(&f.varsFile, "vars-file", "", "JSON `file` of variables")
+	fs.Var(&f.vars, "var", "variable `name=value`, the value parsed as JSON when valid (repeatable)")
+	fs.Var(&f.headers, "header", "header `\"Key: Value\"` set over the configured ones (repeatable)")
+	return &f
+}
+
+// set reports whether any of the flags is set.
+func (f *requestFlags) set() bool {
+	return f.entry != "" || f.endpoint != "" || f.operation != "" || f.varsFile != "" ||
+		len(f.vars) > 0 || len(f.headers) > 0
+}
+
+// prepare resolves the query of files, or the history entry, with the
+// configuration of cf. The client options extra apply to a client of its
+// own; without any the environment's is used.
+func (f *requestFlags) prepare(cf *configFlags, files []string, extra ...graphql.Option) (*request.Prepared, error) {
+	store, err := cf.open()
+	if err != nil {
+		return nil, err
+	}
+	cfg := &store.Config
+
+	var query string
+	var variables map[string]any
+	var replay map[string]string
+	endpoint := f.endpoint
+	if f.entry != "" {
+		e, err := findEntry(store, f.entry)
+		if err != nil {
+			return nil, err
+		}
+		query = e.Query
+		replay = e.Dynamic // sent again with the {{$...}} values it was sent with
+		// The entry's environment, as when it is loaded in the TUI
+		if cf.env == "" {
+			cfg.ActiveEnv = ""
+			if slices.Contains(cfg.EnvNames(), e.EnvName) {
+				cfg.ActiveEnv = e.EnvName
+			}
+		}
+		if endpoint == "" && cfg.ActiveEnv == "" {
+			endpoint = e.Endpoint
+		}
+		if strings.TrimSpace(e.Variables) != "" {
+			if err := json.Unmarshal([]byte(e.Variables), &variables); err != nil {
+				return nil, fmt.Errorf("entry variables: %w", err)
+			}
+		}
+	} else if f.query != "" {
+		query = f.query
+	} else {
+		query, err = readQuery(files[0])
+		if err != nil {
+			return nil, err
+		}
+		f.query = query
+	}
+	variables, err = runVariables(variables, f.varsFile, f.vars)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := parseHeaders(f.headers)
+	if err != nil {
+		return nil, err
+	}
+	op, err := selectOperation(query, f.operation)
+	if err != nil {
+		return nil, err
+	}
+	if validate.OperationType(query, op) == "subscription" {
+		return nil, errors.New("subscriptions are not supported; use the TUI")
+	}
+
+	secrets := secret.NewResolver(cf.dir)
+	var client *graphql.Client
+	if len(extra) > 0 {
+		if client, err = request.NewClient(cfg, secrets, extra...); err != nil {
+			return nil, fmt.Errorf("connection: %w", err)
+		}
+	}
+	return request.Prepare(cfg, secrets, request.Spec{
+		Query:         query,
+		OperationName: op,
+		Variables:     variables,
+		Endpoint:      endpoint,
+		Headers:       headers,
+		Client:        client,
+		Dynamic:       replay,
+	})
+}
+
+// writeResult prints the response and returns the exit code it warrants.
+func writeResult(stdout, stderr io.Writer, result *graphql.Result) int {
+	if result.RawBody != nil {
+		stdout.Write(result.RawBody)
+		fmt.Fprintf(stderr, "qla run: HTTP %d: response is not JSON\n", result.StatusCode)
+		return ExitFailed
+	}
+	out, err := json.MarshalIndent(result.Response, "", "  ")
+	if err != nil {
+		fmt.Fprintln(stderr, "qla run:", err)
+		return ExitFailed
+	}
+	fmt.Fprintln(stdout, string(out))
+
+	code := ExitOK
+	if result.StatusCode < 200 || result.StatusCode > 299 {
+		fmt.Fprintf(stderr, "qla run: HTTP %d\n", result.StatusCode)
+		code = ExitFailed
+	}
+	for _, e := range result.Response.Errors {
+		msg := e.Message
+		if path := e.PathString(); path != "" {
+			msg = path + ": " + msg
+		}
+		fmt.Fprintln(stderr, "qla run: error:", msg)
+		code = ExitFailed
+	}
+	return code
+}
+
+// readQuery reads the query document from path, or stdin for "-".
+func readQuery(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("%s: empty query", path)
+	}
+	return string(data), nil
+}
+
+// findEntry returns the saved history entry with the given ID or, failing
+// that, the newest one of that name.
+func findEntry(cfg *config.Store, name string) (history.Entry, error) {
+	store, err := openHistory(cfg)
+	if err != nil {
+		return history.Entry{}, err
+	}
+	var found *history.Entry
+	for _, e := range store.AllEntries() {
+		if e.ID == name {
+			return e, nil
+		}
+		if e.Name == name && (found == nil || e.CreatedAt.After(found.CreatedAt)) {
+			found = &e
+		}
+	}
+	if found == nil {
+		return history.Entry{}, fmt.Errorf("no history entry %q", name)
+	}
+	return *found, nil
+}
+
+// openHistory loads the history kept next to the configuration of cfg with
+// the collections of the working directory's workspace, once trusted.
+func openHistory(cfg *config.Store) (*history.Store, error) {
+	store := history.NewStore(filepath.Join(cfg.Dir(), "history"))
+	if ws := config.FindWorkspace("."); ws != "" && cfg.Trusts(ws) {
+		store.SetCollections(filepath.Join(ws, "collections"))
+	}
+	if err := store.Load(); err != nil {
+		return nil, fmt.Errorf("history: %w", err)
+	}
+	return store, nil
+}
+
+// runVariables returns base overlaid with the variables of file and then
+// those of the --var flags.
+func runVariables(base map[string]any, file string, flags []string) (map[string]any, error) {
+	vars := maps.Clone(base)
+	if vars == nil {
+		vars = make(map[string]any)
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var fromFile map[string]any
+		if err := json.Unmarshal(data, &fromFile); err != nil {
+			return nil, fmt.Errorf("%s: invalid JSON: %w", file, err)
+		}
+		maps.Copy(vars, fromFile)
+	}
+	for _, f := range flags {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("--var %q: want name=value", f)
+		}
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			v = value
+		}
+		vars[name] = v
+	}
+	return vars, nil
+}
+
+// parseHeaders parses "Key: Value" flags.
+func parseHeaders(flags []string) (map[string]string, error) {
+	headers := make(map[string]string, len(flags))
+	for _, f := range flags {
+		k, v, ok := strings.Cut(f, ":")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("--header %q: want \"Key: Value\"", f)
+		}
+		headers[k] = strings.TrimSpace(v)
+	}
+	return headers, nil
+}
+
+// selectOperation returns the operation of query to run: name, or the only
+// one the document has.
+func selectOperation(query, name string) (string, error) {
+	ops := validate.Operations(query)
+	if name != "" {
+		if !slices.ContainsFunc(ops, func(op validate.Operation) bool { return op.Name == name }) {
+			return "", fmt.Errorf("no operation %q in the document", name)
+		}
+		return name, nil
+	}
+	switch len(ops) {
+	case 0:
+		return "", nil
+	case 1:
+		return ops[0].Name, nil
+	}
+	names := make([]string, len(ops))
+	for i, op := range ops {
+		names[i] = op.Name
+	}
+	return "", fmt.Errorf("the document has several operations; choose one with --operation (%s)", strings.Join(names, ", "))
+}
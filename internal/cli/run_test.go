@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/history"
+)
+
+// testServer answers every request with an echo of its variables and the
+// X-Tenant header, or errors when the variable fail is set.
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["fail"] == true {
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"boom","path":["user"]}]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+			"vars":      req.Variables,
+			"tenant":    r.Header.Get("X-Tenant"),
+			"operation": req.OperationName,
+		}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testConfigDir writes a configuration with a dev environment for url.
+func testConfigDir(t *testing.T, url string) string {
+	t.Helper()
+	dir := t.TempDir()
+	store := config.NewStore(dir)
+	store.Config = config.Config{Environments: []config.Environment{{
+		Name:      "dev",
+		Endpoint:  url,
+		Variables: `{"limit": 5}`,
+		Values:    map[string]string{"tenant": "t-1"},
+		Headers:   []config.Header{{Key: "X-Tenant", Value: "{{tenant}}", Enabled: true}},
+	}}}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runCmd(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRunUsesEnvironment(t *testing.T) {
+	srv := testServer(t)
+	dir := testConfigDir(t, srv.URL)
+	query := writeFile(t, "q.graphql", `query Users($limit: Int, $name: String, $id: ID) { users }`)
+	vars := writeFile(t, "vars.json", `{"name": "ada", "id": "x"}`)
+
+	code, out, errOut := runCmd(query, "--config", dir, "--env", "dev",
+		"--vars-file", vars, "--var", "id=7", "--header", "X-Extra: 1")
+	if code != ExitOK {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	var resp struct {
+		Data struct {
+			Vars      map[string]any
+			Tenant    string
+			Operation string
+		}
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, out)
+	}
+	d := resp.Data
+	if d.Tenant != "t-1" || d.Operation != "Users" {
+		t.Errorf("expected the environment's header and the operation, got %+v", d)
+	}
+	if d.Vars["limit"] != float64(5) || d.Vars["name"] != "ada" || d.Vars["id"] != float64(7) {
+		t.Errorf("expected env, file and flag variables, got %v", d.Vars)
+	}
+}
+
+func TestRunFailsOnErrors(t *testing.T) {
+	srv := testServer(t)
+	dir := testConfigDir(t, srv.URL)
+	query := writeFile(t, "q.graphql", `query Q($fail: Boolean) { user }`)
+
+	code, out, errOut := runCmd(query, "--config", dir, "--env", "dev", "--var", "fail=true")
+	if code != ExitFailed || !strings.Contains(errOut, "user: boom") || !strings.Contains(out, `"errors"`) {
+		t.Errorf("expected exit 1 with the error, got %d %q %q", code, out, errOut)
+	}
+
+	code, _, errOut = runCmd(query, "--config", dir, "--env", "nope")
+	if code != ExitUsage || !strings.Contains(errOut, `no environment "nope"`) {
+		t.Errorf("expected an unknown environment rejected, got %d %q", code, errOut)
+	}
+
+	multi := writeFile(t, "m.graphql", `query A { a } query B { b }`)
+	code, _, errOut = runCmd(multi, "--config", dir, "--env", "dev")
+	if code != ExitUsage || !strings.Contains(errOut, "--operation (A, B)") {
+		t.Errorf("expected an operation required, got %d %q", code, errOut)
+	}
+	code, out, _ = runCmd(multi, "--config", dir, "--env", "dev", "--operation", "B")
+	if code != ExitOK || !strings.Contains(out, `"operation": "B"`) {
+		t.Errorf("expected operation B run, got %d %q", code, out)
+	}
+}
+
+func TestRunHistoryEntry(t *testing.T) {
+	srv := testServer(t)
+	dir := testConfigDir(t, srv.URL)
+	store := history.NewStore(filepath.Join(dir, "history"))
+	_ = store.Load()
+	e := history.Entry{
+		ID:        history.GenerateID(),
+		Name:      "Saved",
+		Query:     `query Saved($name: String) { users }`,
+		Variables: `{"name": "grace"}`,
+		EnvName:   "dev",
+		CreatedAt: time.Now(),
+	}
+	if err := store.AddEntry(e); err != nil {
+		t.Fatal(err)
+	}
+
+	code, out, errOut := runCmd("--config", dir, "--entry", "Saved")
+	if code != ExitOK || !strings.Contains(out, `"grace"`) || !strings.Contains(out, `"t-1"`) {
+		t.Errorf("expected the entry run in its environment, got %d %q %q", code, out, errOut)
+	}
+	if code, _, _ := runCmd("--config", dir, "--entry", "missing"); code != ExitUsage {
+		t.Errorf("expected a missing entry rejected, got %d", code)
+	}
+
+	// The {{$...}} values the entry was sent with are sent again.
+	e.ID, e.Name = history.GenerateID(), "Replayed"
+	e.Variables = `{"name": "{{$uuid}}"}`
+	e.Dynamic = map[string]string{"$uuid": "5f0c1c9e-0000-4000-8000-000000000000"}
+	_ = store.AddEntry(e)
+	code, out, errOut = runCmd("--config", dir, "--entry", "Replayed")
+	if code != ExitOK || !strings.Contains(out, `"5f0c1c9e-0000-4000-8000-000000000000"`) || !strings.Contains(errOut, "replaying {{$uuid}}=5f0c1c9e") {
+		t.Errorf("expected the entry's values replayed, got %d %q %q", code, out, errOut)
+	}
+}
+
+func TestRunFolder(t *testing.T) {
+	srv := testServer(t)
+	dir := testConfigDir(t, srv.URL)
+	store := history.NewStore(filepath.Join(dir, "history"))
+	_ = store.Load()
+	if err := store.CreateFolder("Smoke"); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, e := range []history.Entry{
+		{Name: "Tenant", Query: "# qla:assert data.tenant == \"t-1\"\nquery Tenant { tenant }"},
+		{Name: "Broken", Query: `query Broken($fail: Boolean) { user }`, Variables: `{"fail": true}`},
+	} {
+		e.ID = history.GenerateID()
+		e.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		if err := store.AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.MoveEntry(e.ID, "Smoke"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	junit := filepath.Join(t.TempDir(), "report.xml")
+	code, out, errOut := runCmd("--config", dir, "--env", "dev", "--folder", "Smoke", "--junit", junit)
+	if code != ExitFailed {
+		t.Fatalf("expected exit 1 for the failing entry, got %d %q %q", code, out, errOut)
+	}
+	for _, want := range []string{"✓ Tenant  200", "✗ Broken  200", "    status 2xx, noerrors: 1 errors: boom", "1 passed, 1 failed in"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got %q", want, out)
+		}
+	}
+	if strings.Index(out, "Tenant") > strings.Index(out, "Broken") {
+		t.Errorf("expected the entries run oldest first, got %q", out)
+	}
+	data, err := os.ReadFile(junit)
+	if err != nil || !bytes.Contains(data, []byte(`<testsuite name="Smoke" tests="2" failures="1"`)) {
+		t.Errorf("expected a JUnit report, got %v %s", err, data)
+	}
+
+	if code, _, errOut := runCmd("--config", dir, "--folder", "Nope"); code != ExitUsage || !strings.Contains(errOut, `no history folder "Nope"`) {
+		t.Errorf("expected a missing folder rejected, got %d %q", code, errOut)
+	}
+	if code, _, _ := runCmd("--config", dir, "--folder", "Smoke", "--var", "a=1"); code != ExitUsage {
+		t.Errorf("expected request flags rejected with --folder, got %d", code)
+	}
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/qraqula/qla/internal/schema"
+	"github.com/qraqula/qla/internal/schemadiff"
+	"github.com/qraqula/qla/internal/secret"
+	"github.com/qraqula/qla/internal/validate"
+)
+
+const schemaUsage = `Usage: qla schema pull [flags]
+       qla schema diff [flags] <old> <new>
+
+pull  introspects an environment's endpoint and writes the schema as SDL,
+      or as introspection JSON with --json
+diff  compares two schemas and lists breaking, dangerous and safe changes.
+      Each side is an http(s) URL, an environment or a file: introspection
+      JSON when it ends in .json, SDL otherwise. Exits with 1 on changes at
+      the --fail-on level or above.
+
+Run qla schema <command> -h for the flags of a command.
+`
+
+// Schema implements qla schema.
+func Schema(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, schemaUsage)
+		return ExitUsage
+	}
+	switch args[0] {
+	case "pull":
+		return schemaPull(args[1:], stdout, stderr)
+	case "diff":
+		return schemaDiff(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, schemaUsage)
+		return ExitOK
+	}
+	fmt.Fprintf(stderr, "qla schema: unknown command %q\n\n%s", args[0], schemaUsage)
+	return ExitUsage
+}
+
+func schemaPull(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("schema pull", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := addConfigFlags(fs)
+	endpoint := fs.String("endpoint", "", "endpoint `URL`, overriding the environment's")
+	output := fs.String("o", "", "write to `file` instead of stdout")
+	asJSON := fs.Bool("json", false, "write the introspection JSON instead of SDL")
+	var headers listFlag
+	fs.Var(&headers, "header", "header `\"Key: Value\"` set over the configured ones (repeatable)")
+	rest, err := parse(fs, args)
+	if err != nil {
+		return usageError(err)
+	}
+	fail := func(code int, err error) int {
+		fmt.Fprintln(stderr, "qla schema pull:", err)
+		return code
+	}
+	if len(rest) > 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	store, err := cf.open()
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	extra, err := parseHeaders(headers)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	src := schemaSource{cfg: &store.Config, secrets: secret.NewResolver(cf.dir), headers: extra}
+	data, err := src.introspect(ctx, &store.Config, *endpoint)
+	if err != nil {
+		return fail(ExitFailed, err)
+	}
+
+	var out []byte
+	if *asJSON {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return fail(ExitFailed, err)
+		}
+		buf.WriteByte('\n')
+		out = buf.Bytes()
+	} else {
+		s, err := schema.ParseIntrospection(data)
+		if err != nil {
+			return fail(ExitFailed, err)
+		}
+		out = []byte(validate.IntrospectionToSDL(s))
+	}
+
+	if *output == "" {
+		stdout.Write(out)
+		return ExitOK
+	}
+	if err := os.WriteFile(*output, out, 0o644); err != nil {
+		return fail(ExitFailed, err)
+	}
+	return ExitOK
+}
+
+func schemaDiff(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("schema diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := addConfigFlags(fs)
+	failOn := fs.String("fail-on", "breaking", "exit with 1 on changes at this `level`: breaking, dangerous or none")
+	asJSON := fs.Bool("json", false, "write the changes as JSON")
+	var headers listFlag
+	fs.Var(&headers, "header", "header `\"Key: Value\"` sent to URLs and environments (repeatable)")
+	sides, err := parse(fs, args)
+	if err != nil {
+		return usageError(err)
+	}
+	fail := func(code int, err error) int {
+		fmt.Fprintln(stderr, "qla schema diff:", err)
+		return code
+	}
+	if len(sides) != 2 {
+		fs.Usage()
+		return ExitUsage
+	}
+	threshold, ok := map[string]schemadiff.Level{
+		"breaking":  schemadiff.Breaking,
+		"dangerous": schemadiff.Dangerous,
+		"none":      schemadiff.Breaking + 1,
+	}[*failOn]
+	if !ok {
+		return fail(ExitUsage, fmt.Errorf("--fail-on %q: want breaking, dangerous or none", *failOn))
+	}
+	store, err := cf.open()
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+	extra, err := parseHeaders(headers)
+	if err != nil {
+		return fail(ExitUsage, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	src := schemaSource{cfg: &store.Config, secrets: secret.NewResolver(cf.dir), headers: extra}
+	before, err := src.load(ctx, sides[0])
+	if err != nil {
+		return fail(ExitFailed, err)
+	}
+	after, err := src.load(ctx, sides[1])
+	if err != nil {
+		return fail(ExitFailed, err)
+	}
+
+	changes := schemadiff.Diff(before.AST(), after.AST())
+	if *asJSON {
+		writeChangesJSON(stdout, changes)
+	} else {
+		writeChanges(stdout, changes)
+	}
+	if len(changes) > 0 && schemadiff.Max(changes) >= threshold {
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+func writeChanges(w io.Writer, changes []schemadiff.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No changes")
+		return
+	}
+	counts := make(map[schemadiff.Level]int)
+	for _, c := range changes {
+		fmt.Fprintf(w, "%-9s  %s\n", c.Level, c)
+		counts[c.Level]++
+	}
+	fmt.Fprintf(w, "\n%d breaking, %d dangerous, %d safe\n",
+		counts[schemadiff.Breaking], counts[schemadiff.Dangerous], counts[schemadiff.Safe])
+}
+
+func writeChangesJSON(w io.Writer, changes []schemadiff.Change) {
+	type change struct {
+		Level   string `json:"level"`
+		Path    string `json:"path"`
+		Message string `json:"message"`
+	}
+	out := make([]change, len(changes))
+	for i, c := range changes {
+		out[i] = change{Level: c.Level.String(), Path: c.Path, Message: c.Message}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(out)
+}
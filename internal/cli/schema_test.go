@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qraqula/qla/internal/schema"
+)
+
+// introspectionServer serves a schema with a Query.user(id: ID!): String
+// field, checking the X-Tenant header of the dev environment.
+func introspectionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	name := func(s string) *string { return &s }
+	s := schema.Schema{
+		QueryType: &schema.TypeRef{Name: name("Query")},
+		Types: []schema.FullType{{
+			Kind: "OBJECT",
+			Name: "Query",
+			Fields: []schema.Field{{
+				Name: "user",
+				Args: []schema.InputValue{{
+					Name: "id",
+					Type: schema.TypeRef{Kind: "NON_NULL", OfType: &schema.TypeRef{Kind: "SCALAR", Name: name("ID")}},
+				}},
+				Type: schema.TypeRef{Kind: "SCALAR", Name: name("String")},
+			}},
+		}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Tenant") != "t-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"__schema": s}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func schemaCmd(args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := Schema(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestSchemaPull(t *testing.T) {
+	srv := introspectionServer(t)
+	dir := testConfigDir(t, srv.URL)
+
+	code, out, errOut := schemaCmd("pull", "--config", dir, "--env", "dev")
+	if code != ExitOK || !strings.Contains(out, "user(id: ID!): String") {
+		t.Fatalf("expected SDL, got %d %q %q", code, out, errOut)
+	}
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if code, _, errOut := schemaCmd("pull", "--config", dir, "--env", "dev", "--json", "-o", path); code != ExitOK {
+		t.Fatalf("exit %d: %s", code, errOut)
+	}
+	data, _ := os.ReadFile(path)
+	if s, err := schema.ParseIntrospection(data); err != nil || s.TypeByName("Query") == nil {
+		t.Errorf("expected introspection JSON, got %v: %s", err, data)
+	}
+
+	if code, _, errOut := schemaCmd("pull", "--config", dir, "--endpoint", srv.URL); code != ExitFailed || !strings.Contains(errOut, "401") {
+		t.Errorf("expected the request without the environment's headers refused, got %d %q", code, errOut)
+	}
+}
+
+func TestSchemaDiff(t *testing.T) {
+	srv := introspectionServer(t)
+	dir := testConfigDir(t, srv.URL)
+	next := writeFile(t, "next.graphql", "type Query { user(id: ID!, tenant: String!): String, users: [String] }\n")
+	same := writeFile(t, "same.graphql", "type Query { user(id: ID!): String }\n")
+
+	code, out, errOut := schemaCmd("diff", "--config", dir, "dev", next)
+	if code != ExitFailed {
+		t.Errorf("expected exit 1 on breaking changes, got %d: %s", code, errOut)
+	}
+	for _, want := range []string{
+		"breaking   Query.user(tenant): required argument added (String!)",
+		"safe       Query.users: field added",
+		"1 breaking, 0 dangerous, 1 safe",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	if code, _, _ := schemaCmd("diff", "--config", dir, "--fail-on", "none", "dev", next); code != ExitOK {
+		t.Errorf("expected --fail-on none to pass, got %d", code)
+	}
+	if code, out, _ := schemaCmd("diff", "--config", dir, same, "dev"); code != ExitOK || !strings.Contains(out, "No changes") {
+		t.Errorf("expected no changes, got %d %q", code, out)
+	}
+	code, out, _ = schemaCmd("diff", "--config", dir, "--json", same, next)
+	var changes []map[string]string
+	if err := json.Unmarshal([]byte(out), &changes); err != nil || len(changes) != 2 || changes[0]["level"] != "breaking" {
+		t.Errorf("expected JSON changes, got %v %q", err, out)
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/request"
+	"github.com/qraqula/qla/internal/schema"
+	"github.com/qraqula/qla/internal/secret"
+	"github.com/qraqula/qla/internal/validate"
+)
+
+// schemaSource loads the schemas the subcommands check against.
+type schemaSource struct {
+	cfg     *config.Config
+	secrets *secret.Resolver
+	headers map[string]string // set over the configured headers
+}
+
+// introspect fetches the introspection JSON of the active environment's
+// endpoint, or of endpoint when set.
+func (s schemaSource) introspect(ctx context.Context, cfg *config.Config, endpoint string) (json.RawMessage, error) {
+	p, err := request.Prepare(cfg, s.secrets, request.Spec{
+		Query:    schema.IntrospectionQuery,
+		Endpoint: endpoint,
+		Headers:  s.headers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schema.FetchIntrospection(ctx, p.Client, p.Endpoint, p.Headers)
+}
+
+// load loads the schema src names: an http(s) URL, an environment or a
+// file, introspection JSON when it ends in .json and SDL otherwise.
+func (s schemaSource) load(ctx context.Context, src string) (*validate.SchemaAST, error) {
+	var data []byte
+	var err error
+	switch {
+	case strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://"):
+		cfg := *s.cfg
+		cfg.ActiveEnv = ""
+		data, err = s.introspect(ctx, &cfg, src)
+	case slices.Contains(s.cfg.EnvNames(), src):
+		cfg := *s.cfg
+		cfg.ActiveEnv = src
+		data, err = s.introspect(ctx, &cfg, "")
+	default:
+		data, err = os.ReadFile(src)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(filepath.Ext(src), ".json") {
+			// gqlparser names the file in its errors
+			return validate.ParseSDL(src, string(data))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	introspected, err := schema.ParseIntrospection(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	parsed, err := validate.ParseSchema(introspected)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src, err)
+	}
+	return parsed, nil
+}
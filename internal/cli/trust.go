@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/qraqula/qla/internal/config"
+)
+
+const trustUsage = `Usage: qla trust [flags] [dir]
+
+Trusts the .qraqula workspace at or above dir, the working directory by
+default, so that qla uses its config.json and collections. Until then
+none of it is used: a workspace config can run commands through cmd:
+secret references and send credentials to any endpoint, and collection
+entries bring endpoints and file uploads of their own, so only trust the
+workspaces of repositories you trust.
+
+Flags:
+`
+
+// Trust implements qla trust.
+func Trust(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("trust", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, trustUsage)
+		fs.PrintDefaults()
+	}
+	dir := fs.String("config", config.DefaultDir(), "configuration `directory`")
+
+	args, err := parse(fs, args)
+	if err != nil {
+		return usageError(err)
+	}
+	if len(args) > 1 {
+		fs.Usage()
+		return ExitUsage
+	}
+	start := "."
+	if len(args) == 1 {
+		start = args[0]
+	}
+	fail := func(code int, err error) int {
+		fmt.Fprintln(stderr, "qla trust:", err)
+		return code
+	}
+
+	ws := config.FindWorkspace(start)
+	if ws == "" {
+		return fail(ExitUsage, fmt.Errorf("no %s workspace at or above %s", config.WorkspaceDir, start))
+	}
+	store := config.NewStore(*dir)
+	if err := store.Load(); err != nil {
+		return fail(ExitUsage, fmt.Errorf("config: %w", err))
+	}
+	if err := store.TrustWorkspace(ws); err != nil {
+		return fail(ExitUsage, err)
+	}
+	fmt.Fprintln(stdout, "Trusted", ws)
+	return ExitOK
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrust(t *testing.T) {
+	srv := testServer(t)
+	dir := testConfigDir(t, srv.URL)
+	project := t.TempDir()
+	ws := filepath.Join(project, ".qraqula")
+	shared := `{"environments":[{"name":"shared","endpoint":"` + srv.URL + `",` +
+		`"headers":[{"key":"X-Tenant","value":"cmd:echo t-ws","enabled":true}]}]}`
+	if err := os.MkdirAll(ws, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ws, "config.json"), []byte(shared), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	collection := filepath.Join(ws, "collections", "Shared")
+	if err := os.MkdirAll(collection, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	entry := `{"id":"shared-1","name":"users","query":"{ users }","endpoint":"` + srv.URL + `"}`
+	if err := os.WriteFile(filepath.Join(collection, "shared-1.json"), []byte(entry), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+	query := writeFile(t, "q.graphql", `{ users }`)
+
+	// An untrusted workspace is left out, with a warning.
+	code, _, errOut := runCmd(query, "--config", dir, "--env", "shared")
+	if code != ExitUsage || strings.Count(errOut, "is not trusted; run qla trust") != 1 {
+		t.Errorf("expected the workspace left out with a warning, got %d %q", code, errOut)
+	}
+	// Its collections too.
+	if code, _, errOut := runCmd("--config", dir, "--folder", "Shared"); code != ExitUsage || !strings.Contains(errOut, `no history folder "Shared"`) {
+		t.Errorf("expected the collection left out, got %d %q", code, errOut)
+	}
+	if code, _, errOut := runCmd("--config", dir, "--entry", "shared-1"); code != ExitUsage || !strings.Contains(errOut, `no history entry "shared-1"`) {
+		t.Errorf("expected the collection entry left out, got %d %q", code, errOut)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Trust([]string{"--config", dir}, &stdout, &stderr); code != ExitOK || !strings.Contains(stdout.String(), ws) {
+		t.Fatalf("expected the workspace trusted, got %d %q %q", code, stdout.String(), stderr.String())
+	}
+	code, out, errOut := runCmd(query, "--config", dir, "--env", "shared")
+	if code != ExitOK || !strings.Contains(out, `"t-ws"`) || errOut != "" {
+		t.Errorf("expected the trusted workspace used, got %d %q %q", code, out, errOut)
+	}
+	if code, _, errOut := runCmd("--config", dir, "--folder", "Shared"); code != ExitOK {
+		t.Errorf("expected the trusted collection run, got %d %q", code, errOut)
+	}
+
+	if code := Trust([]string{"--config", dir, t.TempDir()}, &stdout, &stderr); code != ExitUsage {
+		t.Errorf("expected no workspace found, got %d", code)
+	}
+}
@@ -0,0 +1,85 @@
+// Package compare sends an operation to two environments at once and lists
+// how their responses differ, field by field.
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/jsondiff"
+)
+
+// Func sends the request to one environment.
+type Func func(ctx context.Context) (*graphql.Result, error)
+
+// Side is the response of one environment, or why the request failed.
+type Side struct {
+	Env    string
+	Result *graphql.Result
+	Err    error
+}
+
+// Value returns the response as decoded by encoding/json, or its body as a
+// string when it is not JSON.
+func (s Side) Value() any {
+	if s.Result == nil {
+		return nil
+	}
+	if s.Result.RawBody != nil {
+		return string(s.Result.RawBody)
+	}
+	raw, _ := json.Marshal(s.Result.Response)
+	var v any
+	_ = json.Unmarshal(raw, &v)
+	return v
+}
+
+// Run sends a to envA and b to envB at the same time.
+func Run(ctx context.Context, envA string, a Func, envB string, b Func) (Side, Side) {
+	sa, sb := Side{Env: envA}, Side{Env: envB}
+	var wg sync.WaitGroup
+	wg.Go(func() { sa.Result, sa.Err = a(ctx) })
+	wg.Go(func() { sb.Result, sb.Err = b(ctx) })
+	wg.Wait()
+	return sa, sb
+}
+
+// Comparison is the difference between the responses of two environments,
+// A the old side and B the new one.
+type Comparison struct {
+	A, B    Side
+	changes []jsondiff.Change
+}
+
+// New compares the responses of a and b. Nothing is compared when either
+// request failed.
+func New(a, b Side) *Comparison {
+	c := &Comparison{A: a, B: b}
+	if !c.Failed() {
+		c.changes = jsondiff.Diff(a.Value(), b.Value())
+	}
+	return c
+}
+
+// Failed reports whether either request failed.
+func (c *Comparison) Failed() bool {
+	return c.A.Err != nil || c.B.Err != nil
+}
+
+// Changes returns the differences outside the ignore patterns, in path
+// order, and how many were left out. See jsondiff.Ignored for the patterns.
+func (c *Comparison) Changes(ignore []string) ([]jsondiff.Change, int) {
+	return jsondiff.Filter(c.changes, ignore)
+}
+
+// FormatValue writes a value of a change as compact JSON.
+func FormatValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,69 @@
+package compare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/qraqula/qla/internal/graphql"
+)
+
+func respond(data string) Func {
+	return func(context.Context) (*graphql.Result, error) {
+		return &graphql.Result{StatusCode: 200, Response: graphql.Response{Data: json.RawMessage(data)}}, nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	a, b := Run(context.Background(), "staging", respond(`{"n":1,"ts":1}`), "prod", respond(`{"n":2,"ts":2}`))
+	if a.Env != "staging" || b.Env != "prod" {
+		t.Fatalf("expected the sides named, got %q and %q", a.Env, b.Env)
+	}
+	c := New(a, b)
+	if c.Failed() {
+		t.Fatal("expected both requests to succeed")
+	}
+	changes, ignored := c.Changes(nil)
+	if len(changes) != 2 || changes[0].PathString() != "data.n" || ignored != 0 {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+	if changes, ignored := c.Changes([]string{"ts"}); len(changes) != 1 || ignored != 1 {
+		t.Errorf("expected ts ignored, got %+v", changes)
+	}
+
+	failing := func(context.Context) (*graphql.Result, error) { return nil, errors.New("connection refused") }
+	a, b = Run(context.Background(), "staging", respond(`{}`), "prod", failing)
+	c = New(a, b)
+	if !c.Failed() {
+		t.Fatal("expected the comparison failed")
+	}
+	if changes, _ := c.Changes(nil); len(changes) != 0 {
+		t.Errorf("expected nothing compared, got %+v", changes)
+	}
+	m := NewModel()
+	m.SetSize(80, 20)
+	m.SetComparison(c)
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "connection refused") || !strings.Contains(view, "Not compared") {
+		t.Errorf("expected the failure shown:\n%s", view)
+	}
+}
+
+func TestSideValue(t *testing.T) {
+	s := Side{Result: &graphql.Result{RawBody: []byte("Bad Gateway")}}
+	if v := s.Value(); v != "Bad Gateway" {
+		t.Errorf("expected the body of a non-JSON response, got %v", v)
+	}
+	if v := (Side{}).Value(); v != nil {
+		t.Errorf("expected nil without a response, got %v", v)
+	}
+}
+
+func TestParseIgnore(t *testing.T) {
+	got := ParseIgnore(" updatedAt, data.items[*].cursor extensions,,")
+	if strings.Join(got, "|") != "updatedAt|data.items[*].cursor|extensions" {
+		t.Errorf("got %q", got)
+	}
+}
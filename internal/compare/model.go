@@ -0,0 +1,282 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/qraqula/qla/internal/jsondiff"
+)
+
+// RunMsg asks the parent app to send the operation to both environments
+// again.
+type RunMsg struct{}
+
+// IgnoreMsg is sent when the ignore patterns are edited, for the parent app
+// to keep them.
+type IgnoreMsg struct{ Patterns []string }
+
+// CloseMsg is returned to the parent app when the panel is left.
+type CloseMsg struct{}
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	labelStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	envStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true)
+	pathStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	addedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	removedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	changedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+)
+
+// Model is the compare panel: the responses of two environments side by
+// side, one row per field that differs, without the paths the ignore
+// patterns cover.
+type Model struct {
+	cmp     *Comparison
+	op      string
+	running bool
+	ignore  []string
+	changes []jsondiff.Change
+	ignored int
+	input   textinput.Model
+	offset  int
+	width   int
+	height  int
+}
+
+// NewModel returns an empty panel.
+func NewModel() Model {
+	ti := textinput.New()
+	ti.Prompt = "ignore "
+	ti.Placeholder = "updatedAt, data.items[*].cursor"
+	ti.CharLimit = 500
+	styles := ti.Styles()
+	styles.Focused.Prompt = labelStyle
+	styles.Blurred.Prompt = labelStyle
+	ti.SetStyles(styles)
+	return Model{input: ti}
+}
+
+// Start clears the panel for a comparison of op, the operation sent.
+func (m *Model) Start(op string) {
+	m.cmp = nil
+	m.op = op
+	m.running = true
+	m.offset = 0
+}
+
+// SetComparison shows c, ending the run.
+func (m *Model) SetComparison(c *Comparison) {
+	m.cmp = c
+	m.running = false
+	m.offset = 0
+	m.filter()
+}
+
+// Stop ends the run, as when it is aborted.
+func (m *Model) Stop() {
+	m.running = false
+}
+
+// SetIgnore sets the patterns of the paths left out.
+func (m *Model) SetIgnore(patterns []string) {
+	m.ignore = patterns
+	m.filter()
+}
+
+// Comparison returns the comparison shown, nil before the first one.
+func (m Model) Comparison() *Comparison { return m.cmp }
+
+// Changes returns the differences shown and how many were ignored.
+func (m Model) Changes() ([]jsondiff.Change, int) { return m.changes, m.ignored }
+
+// Prompting reports whether the ignore patterns are being edited.
+func (m Model) Prompting() bool { return m.input.Focused() }
+
+func (m *Model) filter() {
+	m.changes, m.ignored = nil, 0
+	if m.cmp != nil {
+		m.changes, m.ignored = m.cmp.Changes(m.ignore)
+	}
+	m.offset = min(m.offset, max(len(m.changes)-1, 0))
+}
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.input.SetWidth(max(w-len(m.input.Prompt)-1, 10))
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	kmsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.input.Focused() {
+		switch kmsg.String() {
+		case "enter":
+			m.input.Blur()
+			patterns := ParseIgnore(m.input.Value())
+			m.SetIgnore(patterns)
+			return m, func() tea.Msg { return IgnoreMsg{Patterns: patterns} }
+		case "esc":
+			m.input.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+	switch kmsg.String() {
+	case "j", "down":
+		if m.offset < len(m.changes)-1 {
+			m.offset++
+		}
+	case "k", "up":
+		if m.offset > 0 {
+			m.offset--
+		}
+	case "i":
+		m.input.SetValue(strings.Join(m.ignore, ", "))
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	case "enter":
+		if !m.running {
+			return m, func() tea.Msg { return RunMsg{} }
+		}
+	case "esc":
+		return m, func() tea.Msg { return CloseMsg{} }
+	}
+	return m, nil
+}
+
+// ParseIgnore splits patterns separated by commas or spaces.
+func ParseIgnore(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+func (m Model) View() string {
+	w := m.width - 2
+	title := titleStyle.Render(" Compare ")
+	if m.op != "" {
+		title += " " + pathStyle.Render(m.op)
+	}
+	lines := []string{ansi.Truncate(title, w, "…")}
+	if m.input.Focused() {
+		lines = append(lines, m.input.View())
+	}
+	if m.cmp == nil {
+		msg := "  (no comparison yet)"
+		if m.running {
+			msg = "  Sending to both environments..."
+		}
+		return strings.Join(append(lines, dimStyle.Render(msg)), "\n")
+	}
+
+	colW := (w - 3) / 2
+	lines = append(lines,
+		column(side(m.cmp.A), colW)+dimStyle.Render(" │ ")+column(side(m.cmp.B), colW),
+		dimStyle.Render(strings.Repeat("─", max(w, 0))),
+	)
+	var rows []string
+	switch {
+	case m.cmp.Failed():
+		rows = append(rows, column(failure(m.cmp.A), colW)+dimStyle.Render(" │ ")+column(failure(m.cmp.B), colW))
+	case len(m.changes) == 0:
+		rows = append(rows, addedStyle.Render("  The responses match"))
+	default:
+		for _, c := range m.changes[m.offset:] {
+			rows = append(rows, m.renderChange(c, w, colW)...)
+		}
+	}
+	footer := m.summary()
+	visible := m.height - len(lines) - 1
+	if visible < 1 {
+		visible = 1
+	}
+	if len(rows) > visible {
+		rows = rows[:visible]
+	}
+	lines = append(lines, rows...)
+	return strings.Join(lines, "\n") + "\n" + ansi.Truncate(footer, w, "…")
+}
+
+// renderChange renders the path of c and the values of both sides below it.
+func (m Model) renderChange(c jsondiff.Change, w, colW int) []string {
+	path := c.PathString()
+	if path == "" {
+		path = "(response)"
+	}
+	var marker, left, right string
+	switch c.Kind {
+	case jsondiff.Added:
+		marker = addedStyle.Render("+ ")
+		left = dimStyle.Render("(missing)")
+		right = addedStyle.Render(FormatValue(c.New))
+	case jsondiff.Removed:
+		marker = removedStyle.Render("- ")
+		left = removedStyle.Render(FormatValue(c.Old))
+		right = dimStyle.Render("(missing)")
+	default:
+		marker = changedStyle.Render("~ ")
+		left = changedStyle.Render(FormatValue(c.Old))
+		right = changedStyle.Render(FormatValue(c.New))
+	}
+	return []string{
+		ansi.Truncate(marker+pathStyle.Render(path), w, "…"),
+		column("  "+left, colW) + dimStyle.Render(" │ ") + column("  "+right, colW),
+	}
+}
+
+func (m Model) summary() string {
+	if m.cmp.Failed() {
+		return removedStyle.Render("Not compared: a request failed")
+	}
+	s := fmt.Sprintf("%d differences", len(m.changes))
+	if len(m.changes) == 1 {
+		s = "1 difference"
+	}
+	if m.ignored > 0 {
+		s += fmt.Sprintf(" · %d ignored", m.ignored)
+	}
+	if len(m.ignore) > 0 {
+		s += " · ignore " + strings.Join(m.ignore, ", ")
+	}
+	if len(m.changes) > 0 {
+		return changedStyle.Render(s)
+	}
+	return dimStyle.Render(s)
+}
+
+// side renders the environment of s with the status and timing of its
+// response.
+func side(s Side) string {
+	env := s.Env
+	if env == "" {
+		env = "(no environment)"
+	}
+	out := envStyle.Render(env)
+	if r := s.Result; r != nil {
+		out += dimStyle.Render(fmt.Sprintf("  %d · %s", r.StatusCode, r.Duration.Round(time.Millisecond)))
+	}
+	return out
+}
+
+func failure(s Side) string {
+	if s.Err == nil {
+		return dimStyle.Render("ok")
+	}
+	return removedStyle.Render(s.Err.Error())
+}
+
+// column truncates s and pads it to w cells.
+func column(s string, w int) string {
+	s = ansi.Truncate(s, w, "…")
+	return s + strings.Repeat(" ", max(w-ansi.StringWidth(s), 0))
+}
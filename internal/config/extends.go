@@ -0,0 +1,163 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// InheritedHeader is a header an environment takes from one it extends.
+type InheritedHeader struct {
+	Header
+	From string // name of the environment that defines it
+}
+
+// Chain returns the environment named name preceded by the environments it
+// extends, the root first. On a missing parent or a cycle it returns the
+// chain up to that point with an error.
+func (c *Config) Chain(name string) ([]*Environment, error) {
+	var chain []*Environment
+	var seen []string
+	for name != "" {
+		if slices.Contains(seen, name) {
+			return chain, fmt.Errorf("extends cycle: %s", strings.Join(append(seen, name), " → "))
+		}
+		env := c.env(name)
+		if env == nil {
+			if len(seen) == 0 {
+				return nil, nil
+			}
+			return chain, fmt.Errorf("%s extends unknown environment %q", seen[len(seen)-1], name)
+		}
+		seen = append(seen, name)
+		chain = append([]*Environment{env}, chain...)
+		name = env.Extends
+	}
+	return chain, nil
+}
+
+// CheckExtends reports whether the environment named name could extend
+// parent without creating a cycle or naming an unknown environment.
+func (c *Config) CheckExtends(name, parent string) error {
+	if parent == "" {
+		return nil
+	}
+	if parent == name {
+		return fmt.Errorf("%s cannot extend itself", name)
+	}
+	if c.env(parent) == nil {
+		return fmt.Errorf("no environment %q", parent)
+	}
+	chain, err := c.Chain(parent)
+	if err != nil {
+		return err
+	}
+	for _, env := range chain {
+		if env.Name == name {
+			return fmt.Errorf("extends cycle: %s → %s", name, parent)
+		}
+	}
+	return nil
+}
+
+// Endpoint returns the endpoint of the environment named name, inherited
+// from the nearest environment it extends when it sets none.
+func (c *Config) Endpoint(name string) string {
+	chain, _ := c.Chain(name)
+	for _, env := range slices.Backward(chain) {
+		if env.Endpoint != "" {
+			return env.Endpoint
+		}
+	}
+	return ""
+}
+
+// InheritedHeaders returns the headers the environment named name takes
+// from the environments it extends and does not override itself, sorted by
+// key.
+func (c *Config) InheritedHeaders(name string) []InheritedHeader {
+	chain, _ := c.Chain(name)
+	if len(chain) < 2 {
+		return nil
+	}
+	own := chain[len(chain)-1].Headers
+	byKey := make(map[string]InheritedHeader)
+	for _, env := range chain[:len(chain)-1] {
+		for _, h := range env.Headers {
+			if !hasHeader(own, h.Key) {
+				byKey[strings.ToLower(h.Key)] = InheritedHeader{Header: h, From: env.Name}
+			}
+		}
+	}
+	var out []InheritedHeader
+	for _, k := range slices.Sorted(maps.Keys(byKey)) {
+		out = append(out, byKey[k])
+	}
+	return out
+}
+
+// envHeaders returns the headers of the environment named name and those it
+// inherits: a header overrides a parent's of the same key in any case, even
+// to disable it.
+func (c *Config) envHeaders(name string) []Header {
+	chain, _ := c.Chain(name)
+	var headers []Header
+	for _, env := range chain {
+		for _, h := range env.Headers {
+			headers = slices.DeleteFunc(headers, func(p Header) bool { return strings.EqualFold(p.Key, h.Key) })
+		}
+		headers = append(headers, env.Headers...)
+	}
+	return headers
+}
+
+// envValues returns the placeholder values of the environment named name
+// over those it inherits, each environment's extracted values over its own.
+func (c *Config) envValues(name string) map[string]string {
+	chain, _ := c.Chain(name)
+	if len(chain) == 1 && len(c.extracted[name]) == 0 {
+		return chain[0].Values
+	}
+	values := make(map[string]string)
+	for _, env := range chain {
+		maps.Copy(values, env.Values)
+		maps.Copy(values, c.extracted[env.Name])
+	}
+	return values
+}
+
+// envVariables returns the JSON variables of the environment named name
+// over those it inherits, variable by variable.
+func (c *Config) envVariables(name string) (map[string]any, error) {
+	chain, err := c.Chain(name)
+	if err != nil {
+		return nil, err
+	}
+	var vars map[string]any
+	for _, env := range chain {
+		if strings.TrimSpace(env.Variables) == "" {
+			continue
+		}
+		var own map[string]any
+		if err := json.Unmarshal([]byte(env.Variables), &own); err != nil {
+			return nil, fmt.Errorf("%s variables: invalid JSON", env.Name)
+		}
+		if vars == nil {
+			vars = own
+			continue
+		}
+		maps.Copy(vars, own)
+	}
+	return vars, nil
+}
+
+func (c *Config) env(name string) *Environment {
+	for i := range c.Environments {
+		if c.Environments[i].Name == name {
+			return &c.Environments[i]
+		}
+	}
+	return nil
+}
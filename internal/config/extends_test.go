@@ -0,0 +1,128 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtendsInheritsAlongChain(t *testing.T) {
+	cfg := Config{
+		ActiveEnv: "prod-eu",
+		Environments: []Environment{
+			{
+				Name:      "base",
+				Endpoint:  "https://api.example.com/graphql",
+				Variables: `{"region": "us", "limit": 10}`,
+				Values:    map[string]string{"tenant": "t-1"},
+				Headers: []Header{
+					{Key: "X-Client", Value: "qla", Enabled: true},
+					{Key: "X-Debug", Value: "1", Enabled: true},
+					{Key: "X-Tenant", Value: "{{tenant}}", Enabled: true},
+				},
+			},
+			{
+				Name:    "prod",
+				Extends: "base",
+				Headers: []Header{{Key: "Authorization", Value: "Bearer prod", Enabled: true}},
+			},
+			{
+				Name:      "prod-eu",
+				Extends:   "prod",
+				Endpoint:  "https://eu.example.com/graphql",
+				Variables: `{"region": "eu"}`,
+				Values:    map[string]string{"tenant": "t-eu"},
+				Headers:   []Header{{Key: "X-Debug", Value: "1", Enabled: false}},
+			},
+		},
+	}
+
+	headers := cfg.MergedHeaders()
+	want := map[string]string{"X-Client": "qla", "X-Tenant": "t-eu", "Authorization": "Bearer prod"}
+	if len(headers) != len(want) {
+		t.Errorf("expected %v, got %v", want, headers)
+	}
+	for k, v := range want {
+		if headers[k] != v {
+			t.Errorf("%s = %q, want %q", k, headers[k], v)
+		}
+	}
+	vars, err := cfg.EnvVariables()
+	if err != nil || vars["region"] != "eu" || vars["limit"] != float64(10) {
+		t.Errorf("expected variables merged, got %v (%v)", vars, err)
+	}
+	if ep := cfg.Endpoint("prod-eu"); ep != "https://eu.example.com/graphql" {
+		t.Errorf("expected own endpoint, got %q", ep)
+	}
+	if ep := cfg.Endpoint("prod"); ep != "https://api.example.com/graphql" {
+		t.Errorf("expected inherited endpoint, got %q", ep)
+	}
+
+	inherited := cfg.InheritedHeaders("prod-eu")
+	if len(inherited) != 3 || inherited[0].Key != "Authorization" || inherited[0].From != "prod" {
+		t.Errorf("unexpected inherited headers %+v", inherited)
+	}
+}
+
+func TestExtendsOverridesHeadersInAnyCase(t *testing.T) {
+	cfg := Config{
+		ActiveEnv:     "prod",
+		GlobalHeaders: []Header{{Key: "X-CLIENT", Value: "global", Enabled: true}},
+		Environments: []Environment{
+			{
+				Name: "base",
+				Headers: []Header{
+					{Key: "Authorization", Value: "Bearer base", Enabled: true},
+					{Key: "X-Client", Value: "qla", Enabled: true},
+				},
+			},
+			{Name: "prod", Extends: "base", Headers: []Header{{Key: "authorization", Value: "Bearer prod", Enabled: true}}},
+		},
+	}
+	headers := cfg.MergedHeaders()
+	want := map[string]string{"authorization": "Bearer prod", "X-Client": "qla"}
+	if len(headers) != len(want) {
+		t.Errorf("expected %v, got %v", want, headers)
+	}
+	for k, v := range want {
+		if headers[k] != v {
+			t.Errorf("%s = %q, want %q", k, headers[k], v)
+		}
+	}
+	if inherited := cfg.InheritedHeaders("prod"); len(inherited) != 1 || inherited[0].Key != "X-Client" {
+		t.Errorf("expected only X-Client inherited, got %+v", inherited)
+	}
+}
+
+func TestExtendsCycle(t *testing.T) {
+	cfg := Config{
+		ActiveEnv: "a",
+		Environments: []Environment{
+			{Name: "a", Extends: "b", Headers: []Header{{Key: "X-A", Value: "a", Enabled: true}}},
+			{Name: "b", Extends: "a", Headers: []Header{{Key: "X-B", Value: "b", Enabled: true}}},
+			{Name: "c", Extends: "missing"},
+		},
+	}
+	if _, err := cfg.Chain("a"); err == nil || !strings.Contains(err.Error(), "a → b → a") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+	if h := cfg.MergedHeaders(); h["X-A"] != "a" || h["X-B"] != "b" {
+		t.Errorf("expected the chain up to the cycle, got %v", h)
+	}
+	if _, err := cfg.EnvVariables(); err == nil {
+		t.Error("expected EnvVariables to fail on a cycle")
+	}
+	if _, err := cfg.Chain("c"); err == nil || !strings.Contains(err.Error(), "unknown environment") {
+		t.Errorf("expected an unknown parent error, got %v", err)
+	}
+
+	cfg.Environments[1].Extends = ""
+	if err := cfg.CheckExtends("b", "a"); err == nil {
+		t.Error("expected b extending a to be a cycle")
+	}
+	if err := cfg.CheckExtends("c", "a"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := cfg.CheckExtends("a", "a"); err == nil {
+		t.Error("expected extending itself to fail")
+	}
+}
@@ -2,8 +2,10 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 const configFile = "config.json"
@@ -12,6 +14,18 @@ const configFile = "config.json"
 type Store struct {
 	dir    string
 	Config Config
+
+	personal Config // as last read from or written to config.json
+}
+
+// DefaultDir returns ~/.config/qraqula, where the configuration, history and
+// secrets are kept.
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	return filepath.Join(home, ".config", "qraqula")
 }
 
 // NewStore creates a Store rooted at dir.
@@ -19,6 +33,11 @@ func NewStore(dir string) *Store {
 	return &Store{dir: dir}
 }
 
+// Dir returns the directory config.json is stored in.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
 // Load reads config.json from disk. If the file doesn't exist, Config stays zero-valued.
 func (s *Store) Load() error {
 	if err := os.MkdirAll(s.dir, 0o755); err != nil {
@@ -32,7 +51,44 @@ func (s *Store) Load() error {
 		}
 		return err
 	}
-	return json.Unmarshal(data, &s.Config)
+	if err := json.Unmarshal(data, &s.personal); err != nil {
+		return err
+	}
+	s.Config = layer(s.personal, s.Config.workspace)
+	return nil
+}
+
+// UseWorkspace layers the workspace in dir over the loaded config. A
+// workspace the user has not trusted is left out with an error wrapping
+// ErrUntrusted; see TrustWorkspace.
+func (s *Store) UseWorkspace(dir string) error {
+	if !s.Trusts(dir) {
+		return fmt.Errorf("%s is %w; run qla trust to use its config", dir, ErrUntrusted)
+	}
+	ws, err := LoadWorkspace(dir)
+	if err != nil {
+		return err
+	}
+	s.Config = layer(s.personal, ws)
+	return nil
+}
+
+// Trusts reports whether the user trusts the workspace in dir. Nothing of
+// an untrusted workspace is used, collections included.
+func (s *Store) Trusts(dir string) bool {
+	return slices.Contains(s.personal.TrustedWorkspaces, dir)
+}
+
+// TrustWorkspace adds the workspace in dir to the trusted ones in
+// config.json and layers it over the config.
+func (s *Store) TrustWorkspace(dir string) error {
+	if !slices.Contains(s.Config.TrustedWorkspaces, dir) {
+		s.Config.TrustedWorkspaces = append(s.Config.TrustedWorkspaces, dir)
+	}
+	if err := s.Save(); err != nil {
+		return err
+	}
+	return s.UseWorkspace(dir)
 }
 
 // Save writes config.json atomically (write .tmp then rename).
@@ -40,14 +96,20 @@ func (s *Store) Save() error {
 	if err := os.MkdirAll(s.dir, 0o755); err != nil {
 		return err
 	}
-	data, err := json.MarshalIndent(s.Config, "", "  ")
+	personal := s.Config.personal(s.personal)
+	data, err := json.MarshalIndent(personal, "", "  ")
 	if err != nil {
 		return err
 	}
 	path := filepath.Join(s.dir, configFile)
 	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+	// Header values may hold tokens, so keep the file private.
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
 		return err
 	}
-	return os.Rename(tmp, path)
+	s.personal = personal
+	return nil
 }
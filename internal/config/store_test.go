@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/qraqula/qla/internal/interp"
 )
 
 func TestLoadMissingFile(t *testing.T) {
@@ -234,3 +237,140 @@ func TestEnvNamesEmpty(t *testing.T) {
 		t.Errorf("expected empty names, got %v", names)
 	}
 }
+
+func TestSubscriptionTransport(t *testing.T) {
+	c := Config{
+		ActiveEnv: "dev",
+		Environments: []Environment{
+			{Name: "dev"},
+			{Name: "yoga", Subscriptions: SubscriptionsSSE},
+		},
+	}
+	if got := c.SubscriptionTransport(); got != SubscriptionsWS {
+		t.Errorf("expected ws default, got %q", got)
+	}
+	c.ActiveEnv = "yoga"
+	if got := c.SubscriptionTransport(); got != SubscriptionsSSE {
+		t.Errorf("expected sse, got %q", got)
+	}
+	c.ActiveEnv = ""
+	if got := c.SubscriptionTransport(); got != SubscriptionsWS {
+		t.Errorf("expected ws without env, got %q", got)
+	}
+	if got := c.Transport(); got != TransportPOST {
+		t.Errorf("expected post default, got %q", got)
+	}
+	c.Environments[0].Transport = TransportAPQ
+	c.ActiveEnv = "dev"
+	if got := c.Transport(); got != TransportAPQ {
+		t.Errorf("expected apq, got %q", got)
+	}
+}
+
+func TestEnvVariables(t *testing.T) {
+	cfg := Config{
+		ActiveEnv: "dev",
+		Environments: []Environment{
+			{Name: "dev", Variables: `{"tenantId": "t-1"}`},
+			{Name: "broken", Variables: `{"tenantId": `},
+			{Name: "empty"},
+		},
+	}
+	vars, err := cfg.EnvVariables()
+	if err != nil || vars["tenantId"] != "t-1" {
+		t.Errorf("expected tenantId from dev, got %v (%v)", vars, err)
+	}
+
+	cfg.ActiveEnv = "broken"
+	if _, err := cfg.EnvVariables(); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+
+	for _, name := range []string{"empty", ""} {
+		cfg.ActiveEnv = name
+		if vars, err := cfg.EnvVariables(); vars != nil || err != nil {
+			t.Errorf("expected no variables for %q, got %v (%v)", name, vars, err)
+		}
+	}
+}
+
+func TestMergedHeadersResolvesPlaceholders(t *testing.T) {
+	t.Setenv("QLA_TEST_TOKEN", "s3cr3t")
+	cfg := Config{
+		ActiveEnv: "dev",
+		GlobalHeaders: []Header{
+			{Key: "Authorization", Value: "Bearer {{env.QLA_TEST_TOKEN}}", Enabled: true},
+			{Key: "X-Missing", Value: "{{nope}}", Enabled: true},
+		},
+		Environments: []Environment{{
+			Name:    "dev",
+			Values:  map[string]string{"tenant": "t-1"},
+			Headers: []Header{{Key: "X-Tenant", Value: "{{tenant}}", Enabled: true}},
+		}},
+	}
+	headers, unresolved := cfg.ResolveHeaders(cfg.Lookup())
+	if headers["Authorization"] != "Bearer s3cr3t" || headers["X-Tenant"] != "t-1" {
+		t.Errorf("expected placeholders resolved, got %v", headers)
+	}
+	if headers["X-Missing"] != "{{nope}}" || len(unresolved) != 1 || unresolved[0] != "nope" {
+		t.Errorf("expected nope unresolved, got %v %v", headers["X-Missing"], unresolved)
+	}
+	if ep, _ := interp.Expand("https://{{tenant}}.api.dev", cfg.Lookup()); ep != "https://t-1.api.dev" {
+		t.Errorf("unexpected endpoint %q", ep)
+	}
+}
+
+func TestSetExtracted(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir)
+	s.Config = Config{
+		ActiveEnv: "dev",
+		Environments: []Environment{
+			{Name: "base", Values: map[string]string{"token": "base", "tenant": "t-1"}},
+			{Name: "dev", Extends: "base", Values: map[string]string{"token": "none"}},
+		},
+	}
+	s.Config.SetExtracted("base", map[string]string{"tenant": "t-2"})
+	s.Config.SetExtracted("dev", map[string]string{"token": "tok-1"})
+
+	lookup := s.Config.Lookup()
+	if v, _ := lookup("token"); v != "tok-1" {
+		t.Errorf("expected the extracted token over the environment's, got %q", v)
+	}
+	if v, _ := lookup("tenant"); v != "t-2" {
+		t.Errorf("expected the inherited extracted value, got %q", v)
+	}
+
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "config.json"))
+	if strings.Contains(string(data), "tok-1") || strings.Contains(string(data), "t-2") {
+		t.Errorf("expected extracted values not saved:\n%s", data)
+	}
+}
+
+func TestSnapshotLimit(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  int64
+	}{
+		{"", DefaultSnapshotLimit},
+		{"0", 0},
+		{"4096", 4096},
+		{"512KB", 512 << 10},
+		{"50mb", 50 << 20},
+		{"1.5 GB", 3 << 29},
+	} {
+		c := Config{Snapshots: tc.value}
+		if got, err := c.SnapshotLimit(); err != nil || got != tc.want {
+			t.Errorf("SnapshotLimit(%q) = %d, %v; want %d", tc.value, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"lots", "-1MB", "MB"} {
+		c := Config{Snapshots: bad}
+		if _, err := c.SnapshotLimit(); err == nil {
+			t.Errorf("expected %q rejected", bad)
+		}
+	}
+}
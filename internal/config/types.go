@@ -1,5 +1,18 @@
 package config
 
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/qraqula/qla/internal/auth"
+	"github.com/qraqula/qla/internal/extract"
+	"github.com/qraqula/qla/internal/interp"
+	"github.com/qraqula/qla/internal/secret"
+)
+
 // Header is a single key-value pair with an Enabled toggle.
 type Header struct {
 	Key     string `json:"key"`
@@ -7,39 +20,149 @@ type Header struct {
 	Enabled bool   `json:"enabled"`
 }
 
+// Subscription transports an environment can use.
+const (
+	SubscriptionsWS  = "ws"  // graphql-transport-ws over WebSocket (default)
+	SubscriptionsSSE = "sse" // HTTP streaming: Server-Sent Events or multipart/mixed
+)
+
+// HTTP transports for queries and mutations.
+const (
+	TransportPOST = "post" // JSON body via POST (default)
+	TransportGET  = "get"  // query string via GET; mutations still use POST
+	TransportAPQ  = "apq"  // Automatic Persisted Queries: sha256 hash via GET
+)
+
 // Environment represents a named environment (dev, staging, prod, etc.).
 type Environment struct {
-	Name      string   `json:"name"`
-	Endpoint  string   `json:"endpoint"`
-	Headers   []Header `json:"headers"`
-	Variables string   `json:"variables"`
+	Name          string            `json:"name"`
+	Extends       string            `json:"extends,omitempty"` // inherit endpoint, headers, variables and values
+	Endpoint      string            `json:"endpoint"`
+	Headers       []Header          `json:"headers"`
+	Variables     string            `json:"variables"`
+	Values        map[string]string `json:"values,omitempty"`        // resolve {{name}} placeholders
+	Extract       []extract.Rule    `json:"extract,omitempty"`       // store response values in Values
+	Transport     string            `json:"transport,omitempty"`     // TransportPOST (default), TransportGET or TransportAPQ
+	Subscriptions string            `json:"subscriptions,omitempty"` // SubscriptionsWS (default) or SubscriptionsSSE
+
+	// Connection settings
+	Timeout    string `json:"timeout,omitempty"`    // Go duration such as "2m"; "0" disables; default 30s
+	CACert     string `json:"caCert,omitempty"`     // PEM bundle trusted in addition to the system roots
+	ClientCert string `json:"clientCert,omitempty"` // PEM certificate for mutual TLS
+	ClientKey  string `json:"clientKey,omitempty"`  // PEM private key for mutual TLS
+	Insecure   bool   `json:"insecure,omitempty"`   // skip TLS certificate verification
+	Proxy      string `json:"proxy,omitempty"`      // proxy URL; default honours HTTP(S)_PROXY
+
+	Auth *auth.Config `json:"auth,omitempty"` // sets the Authorization header
 }
 
 // Config is the top-level configuration persisted to disk.
 type Config struct {
-	ActiveEnv     string        `json:"activeEnv"`
-	Environments  []Environment `json:"environments"`
-	GlobalHeaders []Header      `json:"globalHeaders"`
+	ActiveEnv         string        `json:"activeEnv"`
+	Environments      []Environment `json:"environments"`
+	GlobalHeaders     []Header      `json:"globalHeaders"`
+	CompareIgnore     []string      `json:"compareIgnore,omitempty"`     // paths left out when comparing environments; see jsondiff.Ignored
+	Snapshots         string        `json:"snapshots,omitempty"`         // total size of the responses kept with history, such as "50MB"; "0" disables; default 20MB
+	TrustedWorkspaces []string      `json:"trustedWorkspaces,omitempty"` // .qraqula directories whose config is used; see Store.TrustWorkspace
+
+	workspace *Workspace                   // layered over the fields above; see Workspace
+	extracted map[string]map[string]string // by environment; see SetExtracted
 }
 
 // MergedHeaders returns global + active environment headers merged.
-// Environment headers override global headers with the same key.
-// Only enabled headers are included.
+// Environment headers override global headers with the same key, and those
+// the environment inherits through Extends. Only enabled headers are
+// included, with placeholders resolved.
 func (c *Config) MergedHeaders() map[string]string {
-	result := make(map[string]string)
+	headers, _ := c.ResolveHeaders(c.Lookup())
+	return headers
+}
+
+// ResolveHeaders is MergedHeaders resolving placeholders with lookup, which
+// also returns the placeholders no value was found for. Secret references
+// are kept as they are: see SecretHeaders.
+func (c *Config) ResolveHeaders(lookup interp.Lookup) (map[string]string, []string) {
+	merged := c.enabledHeaders()
+	var unresolved []string
+	for k, v := range merged {
+		if secret.IsRef(v) {
+			continue
+		}
+		var names []string
+		merged[k], names = interp.Expand(v, lookup)
+		unresolved = append(unresolved, names...)
+	}
+	slices.Sort(unresolved)
+	return merged, slices.Compact(unresolved)
+}
+
+// SecretHeaders returns the merged headers whose configured value is a
+// secret reference. Only these are resolved as references: a placeholder
+// value, which may come from a response, never is.
+func (c *Config) SecretHeaders() map[string]string {
+	merged := c.enabledHeaders()
+	maps.DeleteFunc(merged, func(_, v string) bool { return !secret.IsRef(v) })
+	return merged
+}
+
+// enabledHeaders returns the enabled global and active environment headers
+// as configured, environment headers overriding global ones of the same key
+// in any case.
+func (c *Config) enabledHeaders() map[string]string {
+	merged := make(map[string]string)
 	for _, h := range c.GlobalHeaders {
 		if h.Enabled {
-			result[h.Key] = h.Value
+			merged[h.Key] = h.Value
 		}
 	}
-	if env := c.ActiveEnvironment(); env != nil {
-		for _, h := range env.Headers {
-			if h.Enabled {
-				result[h.Key] = h.Value
-			}
+	for _, h := range c.envHeaders(c.ActiveEnv) {
+		if h.Enabled {
+			maps.DeleteFunc(merged, func(k, _ string) bool { return strings.EqualFold(k, h.Key) })
+			merged[h.Key] = h.Value
 		}
 	}
-	return result
+	return merged
+}
+
+// Lookup resolves placeholders from the active environment's Values,
+// including inherited ones, and env.NAME from the process environment.
+func (c *Config) Lookup() interp.Lookup {
+	return interp.Values(c.envValues(c.ActiveEnv))
+}
+
+// SetExtracted stores values picked out of a response for the environment
+// named name. They take precedence over its Values for the rest of the
+// session but are never saved.
+func (c *Config) SetExtracted(name string, values map[string]string) {
+	if c.extracted == nil {
+		c.extracted = make(map[string]map[string]string)
+	}
+	if c.extracted[name] == nil {
+		c.extracted[name] = make(map[string]string, len(values))
+	}
+	maps.Copy(c.extracted[name], values)
+}
+
+// Clone returns a copy of c whose environments, their values and the
+// extracted values can be changed without affecting c.
+func (c *Config) Clone() *Config {
+	own := *c
+	own.Environments = slices.Clone(c.Environments)
+	for i := range own.Environments {
+		own.Environments[i].Values = maps.Clone(own.Environments[i].Values)
+	}
+	own.extracted = make(map[string]map[string]string, len(c.extracted))
+	for name, values := range c.extracted {
+		own.extracted[name] = maps.Clone(values)
+	}
+	return &own
+}
+
+// EnvVariables parses the JSON variables of the active environment merged
+// over those it inherits. It returns nil when no environment is active or
+// none of them has variables.
+func (c *Config) EnvVariables() (map[string]any, error) {
+	return c.envVariables(c.ActiveEnv)
 }
 
 // ActiveEnvironment returns the active environment, or nil if none selected.
@@ -47,12 +170,25 @@ func (c *Config) ActiveEnvironment() *Environment {
 	if c.ActiveEnv == "" {
 		return nil
 	}
-	for i := range c.Environments {
-		if c.Environments[i].Name == c.ActiveEnv {
-			return &c.Environments[i]
-		}
+	return c.env(c.ActiveEnv)
+}
+
+// Transport returns the HTTP transport of the active environment, defaulting
+// to TransportPOST.
+func (c *Config) Transport() string {
+	if env := c.ActiveEnvironment(); env != nil && env.Transport != "" {
+		return env.Transport
 	}
-	return nil
+	return TransportPOST
+}
+
+// SubscriptionTransport returns the subscription transport of the active
+// environment, defaulting to SubscriptionsWS.
+func (c *Config) SubscriptionTransport() string {
+	if env := c.ActiveEnvironment(); env != nil && env.Subscriptions != "" {
+		return env.Subscriptions
+	}
+	return SubscriptionsWS
 }
 
 // EnvNames returns the list of environment names.
@@ -63,3 +199,32 @@ func (c *Config) EnvNames() []string {
 	}
 	return names
 }
+
+// DefaultSnapshotLimit is the total size of the response snapshots kept
+// with history when Snapshots is not set.
+const DefaultSnapshotLimit = 20 << 20
+
+// SnapshotLimit returns the total size in bytes of the response snapshots
+// to keep, parsing Snapshots: a number of bytes with an optional KB, MB or
+// GB suffix. Zero disables snapshots.
+func (c *Config) SnapshotLimit() (int64, error) {
+	if c.Snapshots == "" {
+		return DefaultSnapshotLimit, nil
+	}
+	s := strings.ToUpper(strings.TrimSpace(c.Snapshots))
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid snapshots size %q", c.Snapshots)
+	}
+	return int64(n * float64(unit)), nil
+}
@@ -0,0 +1,330 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WorkspaceDir is the directory a project keeps its shared qla
+// configuration in, usually committed next to the code.
+const WorkspaceDir = ".qraqula"
+
+// Workspace is configuration shared through a project repository. It is
+// layered over the user's config and never written by qla.
+//
+// Precedence: anything the workspace sets wins. Environments are merged by
+// name and headers by key, so a user can add personal headers, values and
+// credentials to a shared environment. The active environment is personal
+// and falls back to the workspace's. Compare ignore paths add up.
+//
+// A workspace config can run commands and send credentials anywhere through
+// secret references and auth, so it is only used once the user trusts the
+// workspace.
+type Workspace struct {
+	Dir    string // the .qraqula directory
+	Config Config
+}
+
+// ErrUntrusted is returned for a workspace the user has not trusted.
+var ErrUntrusted = errors.New("not trusted")
+
+// FindWorkspace returns the nearest .qraqula directory at or above dir, or
+// "" when there is none.
+func FindWorkspace(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	for {
+		// ~/.qraqula would make every directory a workspace.
+		candidate := filepath.Join(dir, WorkspaceDir)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() && dir != home {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadWorkspace reads the config.json of the workspace directory dir. A
+// workspace without one only contributes collections.
+func LoadWorkspace(dir string) (*Workspace, error) {
+	ws := &Workspace{Dir: dir}
+	data, err := os.ReadFile(filepath.Join(dir, configFile))
+	if os.IsNotExist(err) {
+		return ws, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ws.Config); err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Join(dir, configFile), err)
+	}
+	return ws, nil
+}
+
+// Workspace returns the workspace layered into c, or nil.
+func (c *Config) Workspace() *Workspace {
+	return c.workspace
+}
+
+// WorkspaceEnv reports whether the workspace defines the environment name.
+func (c *Config) WorkspaceEnv(name string) bool {
+	return c.workspaceEnv(name) != nil
+}
+
+// WorkspaceField reports whether the workspace sets the field of env, named
+// by its JSON key such as "endpoint" or "timeout".
+func (c *Config) WorkspaceField(env, field string) bool {
+	w := c.workspaceEnv(env)
+	if w == nil {
+		return false
+	}
+	raw, ok := fieldsOf(*w)[field]
+	return ok && !isZero(raw)
+}
+
+// WorkspaceHeader reports whether the workspace defines the header key of
+// env, or a global header when env is "".
+func (c *Config) WorkspaceHeader(env, key string) bool {
+	if c.workspace == nil {
+		return false
+	}
+	headers := c.workspace.Config.GlobalHeaders
+	if env != "" {
+		w := c.workspaceEnv(env)
+		if w == nil {
+			return false
+		}
+		headers = w.Headers
+	}
+	return hasHeader(headers, key)
+}
+
+func (c *Config) workspaceEnv(name string) *Environment {
+	if c.workspace == nil {
+		return nil
+	}
+	for i := range c.workspace.Config.Environments {
+		if c.workspace.Config.Environments[i].Name == name {
+			return &c.workspace.Config.Environments[i]
+		}
+	}
+	return nil
+}
+
+// layer returns personal with ws layered over it. The result shares no
+// memory with either.
+func layer(personal Config, ws *Workspace) Config {
+	var out Config
+	clone(&out, personal)
+	out.workspace = ws
+	if ws == nil {
+		return out
+	}
+	w := ws.Config
+	if out.ActiveEnv == "" {
+		out.ActiveEnv = w.ActiveEnv
+	}
+	out.GlobalHeaders = layerHeaders(out.GlobalHeaders, w.GlobalHeaders)
+	for _, p := range w.CompareIgnore {
+		if !slices.Contains(out.CompareIgnore, p) {
+			out.CompareIgnore = append(out.CompareIgnore, p)
+		}
+	}
+
+	// Workspace environments first, in workspace order.
+	var envs []Environment
+	for _, we := range w.Environments {
+		merged := Environment{}
+		clone(&merged, we)
+		if i := slices.IndexFunc(out.Environments, func(e Environment) bool { return e.Name == we.Name }); i >= 0 {
+			merged = layerEnv(out.Environments[i], we)
+		}
+		envs = append(envs, merged)
+	}
+	for _, e := range out.Environments {
+		if !slices.ContainsFunc(w.Environments, func(we Environment) bool { return we.Name == e.Name }) {
+			envs = append(envs, e)
+		}
+	}
+	out.Environments = envs
+	return out
+}
+
+// layerEnv returns p with every field w sets taken from w.
+func layerEnv(p, w Environment) Environment {
+	fields := fieldsOf(p)
+	for k, raw := range fieldsOf(w) {
+		if k != "headers" && k != "values" && !isZero(raw) {
+			fields[k] = raw
+		}
+	}
+	var out Environment
+	data, _ := json.Marshal(fields)
+	_ = json.Unmarshal(data, &out)
+	out.Headers = layerHeaders(out.Headers, w.Headers)
+	if len(w.Values) > 0 {
+		if out.Values == nil {
+			out.Values = make(map[string]string, len(w.Values))
+		}
+		maps.Copy(out.Values, w.Values)
+	}
+	return out
+}
+
+// layerHeaders returns the workspace headers followed by the personal
+// headers the workspace does not define.
+func layerHeaders(personal, ws []Header) []Header {
+	out := slices.Clone(ws)
+	for _, h := range personal {
+		if !hasHeader(ws, h.Key) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// personal returns the part of c that belongs in the user's config file.
+// Values the workspace hides are taken from prev, the personal config c was
+// layered from, so they survive a save.
+func (c Config) personal(prev Config) Config {
+	ws := c.workspace
+	var out Config
+	clone(&out, c)
+	if ws == nil {
+		return out
+	}
+	out.workspace = nil
+	w := ws.Config
+	if out.ActiveEnv == w.ActiveEnv && prev.ActiveEnv == "" {
+		out.ActiveEnv = ""
+	}
+	out.GlobalHeaders = personalHeaders(out.GlobalHeaders, prev.GlobalHeaders, w.GlobalHeaders)
+	out.CompareIgnore = slices.DeleteFunc(out.CompareIgnore, func(p string) bool {
+		return slices.Contains(w.CompareIgnore, p) && !slices.Contains(prev.CompareIgnore, p)
+	})
+
+	var envs []Environment
+	for _, e := range out.Environments {
+		we := c.workspaceEnv(e.Name)
+		if we == nil {
+			envs = append(envs, e)
+			continue
+		}
+		var pe *Environment
+		if i := slices.IndexFunc(prev.Environments, func(p Environment) bool { return p.Name == e.Name }); i >= 0 {
+			pe = &prev.Environments[i]
+		}
+		if own, ok := personalEnv(e, pe, *we); ok {
+			envs = append(envs, own)
+		}
+	}
+	out.Environments = envs
+	return out
+}
+
+// personalEnv strips what w sets from e, restoring prev's values for those
+// fields. ok is false when nothing personal is left.
+func personalEnv(e Environment, prev *Environment, w Environment) (Environment, bool) {
+	fields := fieldsOf(e)
+	var prevFields map[string]json.RawMessage
+	if prev != nil {
+		prevFields = fieldsOf(*prev)
+	}
+	for k, raw := range fieldsOf(w) {
+		if k == "name" || k == "headers" || k == "values" || isZero(raw) {
+			continue
+		}
+		if v, ok := prevFields[k]; ok {
+			fields[k] = v
+		} else {
+			delete(fields, k)
+		}
+	}
+	var out Environment
+	data, _ := json.Marshal(fields)
+	_ = json.Unmarshal(data, &out)
+
+	var prevHeaders []Header
+	var prevValues map[string]string
+	if prev != nil {
+		prevHeaders, prevValues = prev.Headers, prev.Values
+	}
+	out.Headers = personalHeaders(e.Headers, prevHeaders, w.Headers)
+	out.Values = nil
+	for k, v := range e.Values {
+		if _, shared := w.Values[k]; shared {
+			v, shared = prevValues[k]
+			if !shared {
+				continue
+			}
+		}
+		if out.Values == nil {
+			out.Values = make(map[string]string)
+		}
+		out.Values[k] = v
+	}
+
+	rest := fieldsOf(out)
+	for k, raw := range rest {
+		if k != "name" && !isZero(raw) {
+			return out, true
+		}
+	}
+	return out, false
+}
+
+// personalHeaders returns merged without the workspace headers, keeping the
+// personal headers they hide from prev.
+func personalHeaders(merged, prev, ws []Header) []Header {
+	var out []Header
+	for _, h := range merged {
+		if !hasHeader(ws, h.Key) {
+			out = append(out, h)
+		}
+	}
+	for _, h := range prev {
+		if hasHeader(ws, h.Key) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func hasHeader(headers []Header, key string) bool {
+	return slices.ContainsFunc(headers, func(h Header) bool { return strings.EqualFold(h.Key, key) })
+}
+
+// fieldsOf returns the JSON fields of e.
+func fieldsOf(e Environment) map[string]json.RawMessage {
+	data, _ := json.Marshal(e)
+	var fields map[string]json.RawMessage
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+func isZero(raw json.RawMessage) bool {
+	switch string(bytes.TrimSpace(raw)) {
+	case `""`, "null", "false", "0", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// clone deep-copies src into dst through JSON.
+func clone[T any](dst *T, src T) {
+	data, _ := json.Marshal(src)
+	_ = json.Unmarshal(data, dst)
+}
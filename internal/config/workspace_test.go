@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, _ := json.Marshal(v)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindWorkspace(t *testing.T) {
+	root := t.TempDir()
+	ws := filepath.Join(root, WorkspaceDir)
+	sub := filepath.Join(root, "services", "api")
+	_ = os.MkdirAll(ws, 0o755)
+	_ = os.MkdirAll(sub, 0o755)
+
+	if got := FindWorkspace(sub); got != ws {
+		t.Errorf("expected %s, got %q", ws, got)
+	}
+	if got := FindWorkspace(t.TempDir()); got != "" {
+		t.Errorf("expected no workspace, got %q", got)
+	}
+}
+
+func TestWorkspaceLayering(t *testing.T) {
+	wsDir := filepath.Join(t.TempDir(), WorkspaceDir)
+	writeJSON(t, filepath.Join(wsDir, configFile), Config{
+		ActiveEnv: "staging",
+		Environments: []Environment{{
+			Name:     "prod",
+			Endpoint: "https://api.example.com/graphql",
+			Headers:  []Header{{Key: "X-Client", Value: "qla", Enabled: true}},
+			Values:   map[string]string{"tenant": "shared"},
+		}, {
+			Name:     "staging",
+			Endpoint: "https://staging.example.com/graphql",
+		}},
+		GlobalHeaders: []Header{{Key: "Accept", Value: "application/json", Enabled: true}},
+		CompareIgnore: []string{"extensions"},
+	})
+	userDir := t.TempDir()
+	writeJSON(t, filepath.Join(userDir, configFile), Config{
+		Environments: []Environment{{
+			Name:     "prod",
+			Endpoint: "https://old.example.com/graphql",
+			Headers: []Header{
+				{Key: "Authorization", Value: "secret:prod.token", Enabled: true},
+				{Key: "x-client", Value: "mine", Enabled: true},
+			},
+			Values: map[string]string{"tenant": "mine", "user": "ada"},
+		}, {
+			Name:     "local",
+			Endpoint: "http://localhost:4000/graphql",
+		}},
+		GlobalHeaders: []Header{{Key: "X-Debug", Value: "1", Enabled: true}},
+		CompareIgnore: []string{"updatedAt"},
+	})
+
+	s := NewStore(userDir)
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	// Only a trusted workspace is used.
+	if err := s.UseWorkspace(wsDir); !errors.Is(err, ErrUntrusted) || len(s.Config.Environments) != 2 {
+		t.Fatalf("expected an untrusted workspace left out, got %v", err)
+	}
+	if err := s.TrustWorkspace(wsDir); err != nil {
+		t.Fatal(err)
+	}
+	c := &s.Config
+
+	if names := c.EnvNames(); len(names) != 3 || names[0] != "prod" || names[1] != "staging" || names[2] != "local" {
+		t.Fatalf("expected workspace environments first, got %v", names)
+	}
+	if c.ActiveEnv != "staging" {
+		t.Errorf("expected the workspace's active environment, got %q", c.ActiveEnv)
+	}
+	prod := c.Environments[0]
+	if prod.Endpoint != "https://api.example.com/graphql" {
+		t.Errorf("expected the workspace endpoint to win, got %q", prod.Endpoint)
+	}
+	if len(prod.Headers) != 2 || prod.Headers[0].Value != "qla" || prod.Headers[1].Key != "Authorization" {
+		t.Errorf("expected workspace headers plus personal ones, got %v", prod.Headers)
+	}
+	if prod.Values["tenant"] != "shared" || prod.Values["user"] != "ada" {
+		t.Errorf("unexpected values %v", prod.Values)
+	}
+	if len(c.GlobalHeaders) != 2 {
+		t.Errorf("expected merged global headers, got %v", c.GlobalHeaders)
+	}
+	if len(c.CompareIgnore) != 2 || c.CompareIgnore[0] != "updatedAt" || c.CompareIgnore[1] != "extensions" {
+		t.Errorf("expected personal and workspace ignore paths, got %v", c.CompareIgnore)
+	}
+
+	if !c.WorkspaceEnv("prod") || c.WorkspaceEnv("local") {
+		t.Error("unexpected workspace environments")
+	}
+	if !c.WorkspaceField("prod", "endpoint") || c.WorkspaceField("prod", "timeout") || c.WorkspaceField("local", "endpoint") {
+		t.Error("unexpected workspace fields")
+	}
+	if !c.WorkspaceHeader("prod", "x-client") || c.WorkspaceHeader("prod", "Authorization") || !c.WorkspaceHeader("", "Accept") {
+		t.Error("unexpected workspace headers")
+	}
+
+	// Personal changes are saved; workspace values are not copied into the
+	// user's file, and the personal values they hide survive.
+	c.ActiveEnv = "prod"
+	c.Environments[0].Values["token"] = "tok"
+	c.Environments[0].Timeout = "5s"
+	c.CompareIgnore = append(c.CompareIgnore, "data.now")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	data, _ := os.ReadFile(filepath.Join(userDir, configFile))
+	_ = json.Unmarshal(data, &saved)
+	if saved.ActiveEnv != "prod" || len(saved.Environments) != 2 {
+		t.Fatalf("unexpected personal config %s", data)
+	}
+	p := saved.Environments[0]
+	if p.Endpoint != "https://old.example.com/graphql" || p.Timeout != "5s" {
+		t.Errorf("unexpected personal prod %+v", p)
+	}
+	if len(p.Headers) != 2 || p.Values["tenant"] != "mine" || p.Values["token"] != "tok" {
+		t.Errorf("expected personal headers and values kept, got %v %v", p.Headers, p.Values)
+	}
+	if len(saved.GlobalHeaders) != 1 || saved.GlobalHeaders[0].Key != "X-Debug" {
+		t.Errorf("expected only personal global headers, got %v", saved.GlobalHeaders)
+	}
+	if len(saved.CompareIgnore) != 2 || saved.CompareIgnore[1] != "data.now" {
+		t.Errorf("expected only personal ignore paths, got %v", saved.CompareIgnore)
+	}
+	if len(saved.TrustedWorkspaces) != 1 || saved.TrustedWorkspaces[0] != wsDir {
+		t.Errorf("expected the workspace trusted, got %v", saved.TrustedWorkspaces)
+	}
+
+	// A workspace cannot trust itself, nor a broken one be used.
+	writeJSON(t, filepath.Join(wsDir, configFile), Config{TrustedWorkspaces: []string{wsDir}})
+	other := NewStore(t.TempDir())
+	_ = other.Load()
+	if err := other.UseWorkspace(wsDir); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("expected the workspace untrusted, got %v", err)
+	}
+	_ = os.WriteFile(filepath.Join(wsDir, configFile), []byte("{"), 0o644)
+	if err := s.UseWorkspace(wsDir); err == nil {
+		t.Error("expected a broken workspace config reported")
+	}
+}
@@ -33,6 +33,29 @@ func (m *Model) SetValue(s string) {
 	m.ta.SetValue(s)
 }
 
+// CursorLine returns the 1-based line the cursor is on.
+func (m Model) CursorLine() int {
+	return m.ta.Line() + 1
+}
+
+// GotoPosition moves the cursor to a 1-based line and column, as reported in
+// GraphQL error locations.
+func (m *Model) GotoPosition(line, col int) {
+	m.ta.MoveToBegin()
+	for m.ta.Line() < line-1 {
+		prev := m.ta.Line()
+		m.ta.CursorDown()
+		if m.ta.Line() == prev {
+			// Soft-wrapped line: CursorDown moved within it; keep going
+			// unless we are stuck on the last line.
+			if prev == m.ta.LineCount()-1 {
+				break
+			}
+		}
+	}
+	m.ta.SetCursorColumn(col - 1)
+}
+
 func (m *Model) Focus() tea.Cmd {
 	return nil
 }
@@ -1,6 +1,7 @@
 package editor
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -49,3 +50,26 @@ func TestViewEmptyShowsPlaceholder(t *testing.T) {
 		t.Error("expected non-empty view even without content")
 	}
 }
+
+func TestGotoPosition(t *testing.T) {
+	m := New()
+	m.SetSize(40, 10)
+	m.SetValue("query {\n  user {\n    name\n  }\n}")
+
+	m.GotoPosition(3, 5)
+	if m.CursorLine() != 3 || m.ta.Column() != 4 {
+		t.Errorf("expected line 3 col 5, got line %d col %d", m.CursorLine(), m.ta.Column()+1)
+	}
+
+	// Soft-wrapped lines count once.
+	m.SetValue("{ " + strings.Repeat("field ", 30) + "}\n{ last }")
+	m.GotoPosition(2, 3)
+	if m.CursorLine() != 2 {
+		t.Errorf("expected line 2 past a wrapped line, got %d", m.CursorLine())
+	}
+
+	m.GotoPosition(99, 1)
+	if m.CursorLine() != 2 {
+		t.Errorf("expected out-of-range line to clamp to last line, got %d", m.CursorLine())
+	}
+}
@@ -0,0 +1,119 @@
+// Package extract stores values from a response as placeholder values, so a
+// login mutation can feed the token into the headers of the next request.
+//
+// A rule reads "data.login.token -> authToken". Environments keep a list of
+// rules; a query adds its own with comments such as
+//
+//	# qla:extract data.login.token -> authToken
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/qraqula/qla/internal/jsonpath"
+)
+
+// Rule copies the value at Path in the response to the placeholder Name.
+type Rule struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+func (r Rule) String() string { return r.Path + " -> " + r.Name }
+
+var nameRE = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+
+// Parse parses a single "path -> name" rule.
+func Parse(s string) (Rule, error) {
+	path, name, ok := strings.Cut(s, "->")
+	if !ok {
+		return Rule{}, fmt.Errorf("invalid rule %q (use path -> name)", strings.TrimSpace(s))
+	}
+	r := Rule{Path: strings.TrimSpace(path), Name: strings.TrimSpace(name)}
+	if _, err := jsonpath.Split(r.Path); err != nil {
+		return Rule{}, err
+	}
+	if !nameRE.MatchString(r.Name) {
+		return Rule{}, fmt.Errorf("invalid name %q", r.Name)
+	}
+	return r, nil
+}
+
+// ParseList parses rules separated by semicolons. Empty input yields nil.
+func ParseList(s string) ([]Rule, error) {
+	var rules []Rule
+	for part := range strings.SplitSeq(s, ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		r, err := Parse(part)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// FormatList is the inverse of ParseList.
+func FormatList(rules []Rule) string {
+	parts := make([]string, len(rules))
+	for i, r := range rules {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+var directiveRE = regexp.MustCompile(`(?m)^\s*#\s*qla:extract\s+(.*)$`)
+
+// FromQuery returns the rules of the # qla:extract comments in query.
+func FromQuery(query string) ([]Rule, error) {
+	var rules []Rule
+	for _, m := range directiveRE.FindAllStringSubmatch(query, -1) {
+		more, err := ParseList(m[1])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, more...)
+	}
+	return rules, nil
+}
+
+// Apply evaluates rules against the data of a response. Strings are stored
+// as they are, anything else as compact JSON. Later rules for the same name
+// win. Rules whose path is missing or null are reported in errs.
+func Apply(rules []Rule, data json.RawMessage) (values map[string]string, errs []error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	var doc any
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, []error{fmt.Errorf("response data: %w", err)}
+		}
+	}
+	root := map[string]any{"data": doc}
+	for _, r := range rules {
+		v, err := jsonpath.Get(root, r.Path)
+		if err == nil && v == nil {
+			err = fmt.Errorf("%s is null", r.Path)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		if s, ok := v.(string); ok {
+			values[r.Name] = s
+		} else {
+			raw, _ := json.Marshal(v)
+			values[r.Name] = string(raw)
+		}
+	}
+	return values, errs
+}
@@ -0,0 +1,71 @@
+package extract
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	r, err := Parse("  data.login.token ->  authToken ")
+	if err != nil || r != (Rule{Path: "data.login.token", Name: "authToken"}) {
+		t.Fatalf("unexpected %+v, %v", r, err)
+	}
+	for _, bad := range []string{"data.login.token", "data..x -> a", "data.x -> ", "data.x -> a b"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestParseListRoundTrip(t *testing.T) {
+	rules, err := ParseList("data.login.token -> authToken; data.login.user.id -> userId;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "data.login.token -> authToken; data.login.user.id -> userId"
+	if got := FormatList(rules); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if rules, err := ParseList("  "); err != nil || rules != nil {
+		t.Errorf("expected no rules, got %v, %v", rules, err)
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	query := `# qla:extract data.login.token -> authToken
+mutation Login {
+  # qla:extract data.login.user.id -> userId
+  login { token user { id } }
+}`
+	rules, err := FromQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Rule{{"data.login.token", "authToken"}, {"data.login.user.id", "userId"}}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("got %v, want %v", rules, want)
+	}
+	if _, err := FromQuery("# qla:extract token"); err == nil {
+		t.Error("expected error for a rule without ->")
+	}
+}
+
+func TestApply(t *testing.T) {
+	data := json.RawMessage(`{"login":{"token":"abc","user":{"id":7,"roles":["admin"]},"expires":null}}`)
+	rules := []Rule{
+		{"data.login.token", "authToken"},
+		{"data.login.user.id", "userId"},
+		{"data.login.user.roles", "roles"},
+		{"data.login.expires", "expires"},
+		{"data.logout", "gone"},
+	}
+	values, errs := Apply(rules, data)
+	want := map[string]string{"authToken": "abc", "userId": "7", "roles": `["admin"]`}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("got %v, want %v", values, want)
+	}
+	if len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+}
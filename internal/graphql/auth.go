@@ -0,0 +1,48 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Authorizer supplies the Authorization header of every request, such as an
+// OAuth2 access token that is renewed when it expires.
+type Authorizer interface {
+	Authorization(ctx context.Context) (string, error)
+}
+
+// WithAuth sets the Authorizer. Its header replaces any configured
+// Authorization header.
+func WithAuth(a Authorizer) Option {
+	return func(c *Client) { c.auth = a }
+}
+
+// authorize returns headers with the Authorization header of the client's
+// Authorizer, leaving headers itself unmodified.
+func (c *Client) authorize(ctx context.Context, headers map[string]string) (map[string]string, error) {
+	if c.auth == nil {
+		return headers, nil
+	}
+	value, err := c.auth.Authorization(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+	out := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		if !strings.EqualFold(k, "Authorization") {
+			out[k] = v
+		}
+	}
+	out["Authorization"] = value
+	return out, nil
+}
+
+// rejected drops a cached token the server answered 401 to, so the next
+// request fetches a new one.
+func (c *Client) rejected(statusCode int) {
+	if inv, ok := c.auth.(interface{ Invalidate() }); ok && statusCode == http.StatusUnauthorized {
+		inv.Invalidate()
+	}
+}
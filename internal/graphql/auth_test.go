@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeAuth struct {
+	token       string
+	err         error
+	invalidated bool
+}
+
+func (a *fakeAuth) Authorization(context.Context) (string, error) { return a.token, a.err }
+func (a *fakeAuth) Invalidate()                                   { a.invalidated = true }
+
+func TestWithAuth(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Values("Authorization")
+		if got[0] != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	a := &fakeAuth{token: "Bearer fresh"}
+	c := NewClient(WithAuth(a))
+	headers := map[string]string{"authorization": "Bearer stale", "X-Trace": "1"}
+	if _, err := c.Execute(context.Background(), srv.URL, Request{Query: "{ a }"}, headers); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "Bearer fresh" {
+		t.Errorf("expected the configured header replaced, got %v", got)
+	}
+	if headers["authorization"] != "Bearer stale" {
+		t.Error("expected the caller's headers unmodified")
+	}
+	if a.invalidated {
+		t.Error("expected the token kept after a 200")
+	}
+
+	a.token = "Bearer revoked"
+	if _, err := c.Execute(context.Background(), srv.URL, Request{Query: "{ a }"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !a.invalidated {
+		t.Error("expected a 401 to invalidate the token")
+	}
+
+	a.token, a.invalidated = "Bearer revoked", false
+	if _, err := c.Stream(context.Background(), srv.URL, Request{Query: "{ a }"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !a.invalidated {
+		t.Error("expected a 401 to a stream to invalidate the token")
+	}
+
+	a.err = errors.New("token endpoint down")
+	if _, err := c.Execute(context.Background(), srv.URL, Request{Query: "{ a }"}, nil); err == nil || !strings.HasPrefix(err.Error(), "auth: ") {
+		t.Errorf("expected auth error, got %v", err)
+	}
+}
@@ -3,41 +3,228 @@ package graphql
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Mode selects how Execute sends queries over HTTP.
+type Mode int
+
+const (
+	ModePOST Mode = iota // JSON body via POST (default)
+	ModeGET              // query string via GET; mutations still use POST
+	ModeAPQ              // Automatic Persisted Queries: hash via GET, full query on miss
 )
 
 type Client struct {
 	http *http.Client
+	mode Mode
+	auth Authorizer
 }
 
-func NewClient() *Client {
-	return &Client{
+// Option configures a Client.
+type Option func(*Client)
+
+// WithMode sets how queries are sent.
+func WithMode(mode Mode) Option {
+	return func(c *Client) { c.mode = mode }
+}
+
+func NewClient(opts ...Option) *Client {
+	c := &Client{
 		http: &http.Client{Timeout: 30 * time.Second},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *Client) Execute(ctx context.Context, endpoint string, req Request, headers map[string]string) (*Result, error) {
-	body, err := json.Marshal(req)
+	headers, err := c.authorize(ctx, headers)
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.execute(ctx, endpoint, req, headers)
+	if err == nil {
+		c.rejected(result.StatusCode)
+	}
+	return result, err
+}
+
+func (c *Client) execute(ctx context.Context, endpoint string, req Request, headers map[string]string) (*Result, error) {
+	if vars, files := extractFiles(req.Variables); len(files) > 0 {
+		req.Variables = vars
+		httpReq, err := newUploadRequest(ctx, endpoint, req, files)
+		if err != nil {
+			return nil, err
+		}
+		// The multipart boundary must survive a configured Content-Type header.
+		uploadHeaders := make(map[string]string, len(headers))
+		for k, v := range headers {
+			if !strings.EqualFold(k, "Content-Type") {
+				uploadHeaders[k] = v
+			}
+		}
+		return c.send(httpReq, uploadHeaders)
+	}
+	if c.mode == ModePOST {
+		return c.do(ctx, http.MethodPost, endpoint, req, headers)
+	}
+	method := http.MethodGet
+	if isMutation(req) {
+		method = http.MethodPost
+	}
+	if c.mode == ModeGET {
+		return c.do(ctx, method, endpoint, req, headers)
+	}
+	return c.executePersisted(ctx, method, endpoint, req, headers)
+}
+
+// executePersisted sends only the query hash, over GET or, for mutations,
+// POST. When the server does not know the hash yet, the query is registered
+// by POSTing it together with the hash.
+func (c *Client) executePersisted(ctx context.Context, method, endpoint string, req Request, headers map[string]string) (*Result, error) {
+	sum := sha256.Sum256([]byte(req.Query))
+	ext := map[string]any{}
+	for k, v := range req.Extensions {
+		ext[k] = v
+	}
+	ext["persistedQuery"] = map[string]any{"version": 1, "sha256Hash": hex.EncodeToString(sum[:])}
+
+	hashed := req
+	hashed.Query = ""
+	hashed.Extensions = ext
+	result, err := c.do(ctx, method, endpoint, hashed, headers)
 	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
+		return nil, err
+	}
+	if !persistedQueryMissing(result.Response) {
+		result.PersistedQuery = PersistedHit
+		return result, nil
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	full := req
+	full.Extensions = ext
+	retry, err := c.do(ctx, http.MethodPost, endpoint, full, headers)
+	if err != nil {
+		return nil, err
+	}
+	retry.PersistedQuery = PersistedMiss
+	retry.Duration += result.Duration
+	return retry, nil
+}
+
+// persistedQueryMissing reports whether the server asked for the full query:
+// the hash is unknown or persisted queries are not supported at all.
+func persistedQueryMissing(resp Response) bool {
+	for _, e := range resp.Errors {
+		switch {
+		case e.Message == "PersistedQueryNotFound", e.Code() == "PERSISTED_QUERY_NOT_FOUND",
+			e.Message == "PersistedQueryNotSupported", e.Code() == "PERSISTED_QUERY_NOT_SUPPORTED":
+			return true
+		}
+	}
+	return false
+}
+
+// isMutation reports whether req selects a mutation. Mutations must not be
+// sent over GET.
+func isMutation(req Request) bool {
+	doc, err := parser.ParseQuery(&ast.Source{Input: req.Query})
+	if err != nil {
+		return false
+	}
+	var op *ast.OperationDefinition
+	if req.OperationName != "" {
+		op = doc.Operations.ForName(req.OperationName)
+	} else if len(doc.Operations) == 1 {
+		op = doc.Operations[0]
+	}
+	return op != nil && op.Operation == ast.Mutation
+}
+
+// newHTTPRequest encodes req as a JSON body (POST) or as query parameters
+// (GET).
+func newHTTPRequest(ctx context.Context, method, endpoint string, req Request) (*http.Request, error) {
+	if method == http.MethodPost {
+		body, err := json.Marshal(req)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+		httpReq, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq, nil
+	}
+
+	u, err := url.Parse(endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
+	q := u.Query()
+	if req.Query != "" {
+		q.Set("query", req.Query)
+	}
+	if req.OperationName != "" {
+		q.Set("operationName", req.OperationName)
+	}
+	if len(req.Variables) > 0 {
+		raw, err := json.Marshal(req.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("marshal variables: %w", err)
+		}
+		q.Set("variables", string(raw))
+	}
+	if len(req.Extensions) > 0 {
+		raw, err := json.Marshal(req.Extensions)
+		if err != nil {
+			return nil, fmt.Errorf("marshal extensions: %w", err)
+		}
+		q.Set("extensions", string(raw))
+	}
+	u.RawQuery = q.Encode()
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	// Apollo Server and Router reject GET requests without a non-simple
+	// header as a CSRF precaution.
+	httpReq.Header.Set("Apollo-Require-Preflight", "true")
+	return httpReq, nil
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, req Request, headers map[string]string) (*Result, error) {
+	httpReq, err := newHTTPRequest(ctx, method, endpoint, req)
+	if err != nil {
+		return nil, err
+	}
+	return c.send(httpReq, headers)
+}
+
+// send adds headers to httpReq, sends it and decodes the GraphQL response.
+func (c *Client) send(httpReq *http.Request, headers map[string]string) (*Result, error) {
 	for k, v := range headers {
 		httpReq.Header.Set(k, v)
 	}
+	ex := captureRequest(httpReq)
 
-	start := time.Now()
+	var trace tracer
+	httpReq = httpReq.WithContext(trace.withTrace(httpReq.Context()))
 	resp, err := c.http.Do(httpReq)
-	duration := time.Since(start)
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +234,13 @@ func (c *Client) Execute(ctx context.Context, endpoint string, req Request, head
 	if err != nil {
 		return nil, fmt.Errorf("read response: %w", err)
 	}
+	end := time.Now()
+	duration := end.Sub(trace.start)
+	timing := trace.timing(end)
+	ex.Proto = resp.Proto
+	ex.Status = resp.Status
+	ex.ResponseHeaders = resp.Header
+	ex.ResponseBody = respBody
 
 	var gqlResp Response
 	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
@@ -56,6 +250,8 @@ func (c *Client) Execute(ctx context.Context, endpoint string, req Request, head
 			StatusCode: resp.StatusCode,
 			Duration:   duration,
 			Size:       len(respBody),
+			Timing:     timing,
+			Exchange:   ex,
 		}, nil
 	}
 
@@ -64,5 +260,29 @@ func (c *Client) Execute(ctx context.Context, endpoint string, req Request, head
 		StatusCode: resp.StatusCode,
 		Duration:   duration,
 		Size:       len(respBody),
+		Timing:     timing,
+		Exchange:   ex,
 	}, nil
 }
+
+// maxCapturedBody caps the request body kept for inspection; uploads can be
+// arbitrarily large.
+const maxCapturedBody = 64 << 10
+
+// captureRequest records the request line, headers and body of httpReq
+// before it is sent.
+func captureRequest(httpReq *http.Request) *Exchange {
+	ex := &Exchange{
+		Method:         httpReq.Method,
+		URL:            httpReq.URL.String(),
+		RequestHeaders: httpReq.Header.Clone(),
+		RequestSize:    httpReq.ContentLength,
+	}
+	if httpReq.GetBody != nil {
+		if body, err := httpReq.GetBody(); err == nil {
+			ex.RequestBody, _ = io.ReadAll(io.LimitReader(body, maxCapturedBody))
+			body.Close()
+		}
+	}
+	return ex
+}
@@ -2,9 +2,16 @@ package graphql
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -76,6 +83,41 @@ func TestClientExecuteWithHeaders(t *testing.T) {
 	}
 }
 
+func TestClientExecuteCapturesExchange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		json.NewEncoder(w).Encode(Response{Data: json.RawMessage(`{}`)})
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	headers := map[string]string{"Authorization": "Bearer test-token"}
+	result, err := client.Execute(context.Background(), srv.URL, Request{Query: "{ hello }"}, headers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ex := result.Exchange
+	if ex == nil {
+		t.Fatal("expected exchange")
+	}
+	if ex.Method != http.MethodPost || ex.URL != srv.URL {
+		t.Errorf("unexpected request line %s %s", ex.Method, ex.URL)
+	}
+	if ex.RequestHeaders.Get("Authorization") != "Bearer test-token" {
+		t.Errorf("expected request headers, got %v", ex.RequestHeaders)
+	}
+	if !strings.Contains(string(ex.RequestBody), `"query":"{ hello }"`) || ex.RequestSize != int64(len(ex.RequestBody)) {
+		t.Errorf("unexpected request body %q (size %d)", ex.RequestBody, ex.RequestSize)
+	}
+	if ex.Status != "200 OK" || ex.ResponseHeaders.Get("X-RateLimit-Remaining") != "42" {
+		t.Errorf("unexpected response %s %v", ex.Status, ex.ResponseHeaders)
+	}
+	if len(ex.ResponseBody) != result.Size {
+		t.Errorf("expected response body of %d bytes, got %d", result.Size, len(ex.ResponseBody))
+	}
+}
+
 func TestClientExecuteCancellation(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		<-r.Context().Done()
@@ -91,3 +133,174 @@ func TestClientExecuteCancellation(t *testing.T) {
 		t.Fatal("expected error from cancelled context")
 	}
 }
+
+func TestErrorDecodesFullModel(t *testing.T) {
+	body := `{"errors":[{"message":"not allowed","locations":[{"line":3,"column":5}],"path":["user","posts",1,"title"],"extensions":{"code":"FORBIDDEN"}},{"message":"bad","extensions":{"classification":{"type":"ValidationError"}}}]}`
+	var resp Response
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	e := resp.Errors[0]
+	if len(e.Locations) != 1 || e.Locations[0] != (Location{Line: 3, Column: 5}) {
+		t.Errorf("unexpected locations: %v", e.Locations)
+	}
+	if e.Code() != "FORBIDDEN" {
+		t.Errorf("expected FORBIDDEN, got %q", e.Code())
+	}
+	if e.PathString() != "user.posts[1].title" {
+		t.Errorf("unexpected path: %q", e.PathString())
+	}
+	if resp.Errors[1].Code() != "ValidationError" {
+		t.Errorf("expected nested classification, got %q", resp.Errors[1].Code())
+	}
+}
+
+func TestClientExecuteGET(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case strings.HasPrefix(q.Get("query"), "mutation"):
+			t.Error("mutations must not be sent over GET")
+		case r.Method == http.MethodGet:
+			if r.Header.Get("Apollo-Require-Preflight") != "true" {
+				t.Error("expected a CSRF preflight header on GET")
+			}
+			if q.Get("query") != "query Q($id: ID) { user(id: $id) { id } }" || q.Get("variables") != `{"id":"1"}` || q.Get("operationName") != "Q" || q.Get("tenant") != "a" {
+				t.Errorf("unexpected query string: %s", r.URL.RawQuery)
+			}
+		}
+		json.NewEncoder(w).Encode(Response{Data: json.RawMessage(`{}`)})
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithMode(ModeGET))
+	req := Request{Query: "query Q($id: ID) { user(id: $id) { id } }", OperationName: "Q", Variables: map[string]any{"id": "1"}}
+	if _, err := client.Execute(context.Background(), srv.URL+"?tenant=a", req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.Execute(context.Background(), srv.URL, Request{Query: "mutation { save }"}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestClientExecutePersistedQuery(t *testing.T) {
+	known := map[string]bool{}
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		var req Request
+		if r.Method == http.MethodGet {
+			req.Query = r.URL.Query().Get("query")
+			_ = json.Unmarshal([]byte(r.URL.Query().Get("extensions")), &req.Extensions)
+		} else {
+			_ = json.NewDecoder(r.Body).Decode(&req)
+		}
+		hash := req.Extensions["persistedQuery"].(map[string]any)["sha256Hash"].(string)
+		if req.Query == "" && !known[hash] {
+			fmt.Fprint(w, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`)
+			return
+		}
+		if req.Query != "" {
+			sum := sha256.Sum256([]byte(req.Query))
+			if hex.EncodeToString(sum[:]) != hash {
+				t.Errorf("hash mismatch for %q", req.Query)
+			}
+			known[hash] = true
+		}
+		fmt.Fprint(w, `{"data":{"hello":"world"}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithMode(ModeAPQ))
+	req := Request{Query: "{ hello }"}
+	miss, err := client.Execute(context.Background(), srv.URL, req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if miss.PersistedQuery != PersistedMiss || string(miss.Response.Data) != `{"hello":"world"}` {
+		t.Errorf("expected miss with data, got %v %s", miss.PersistedQuery, miss.Response.Data)
+	}
+
+	hit, err := client.Execute(context.Background(), srv.URL, req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hit.PersistedQuery != PersistedHit {
+		t.Errorf("expected hit, got %v", hit.PersistedQuery)
+	}
+	if strings.Join(methods, ",") != "GET,POST,GET" {
+		t.Errorf("unexpected request sequence: %v", methods)
+	}
+
+	// Mutations keep the hash but are always POSTed.
+	methods = nil
+	mutation := Request{Query: "mutation { save }"}
+	for range 2 {
+		if _, err := client.Execute(context.Background(), srv.URL, mutation, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if strings.Join(methods, ",") != "POST,POST,POST" {
+		t.Errorf("unexpected request sequence for a mutation: %v", methods)
+	}
+}
+
+func TestClientExecuteUpload(t *testing.T) {
+	dir := t.TempDir()
+	avatar := filepath.Join(dir, "avatar.png")
+	if err := os.WriteFile(avatar, []byte("PNGDATA"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("expected multipart request: %v", err)
+		}
+		var ops Request
+		_ = json.Unmarshal([]byte(r.FormValue("operations")), &ops)
+		if ops.Variables["file"] != nil || ops.Variables["handle"] != "@ada" {
+			t.Errorf("expected file nulled and handle kept, got %v", ops.Variables)
+		}
+		if r.FormValue("map") != `{"0":["variables.docs.1","variables.file"]}` {
+			t.Errorf("unexpected map: %s", r.FormValue("map"))
+		}
+		f, hdr, err := r.FormFile("0")
+		if err != nil {
+			t.Fatalf("expected file part: %v", err)
+		}
+		data, _ := io.ReadAll(f)
+		if hdr.Filename != "avatar.png" || string(data) != "PNGDATA" || hdr.Header.Get("Content-Type") != "image/png" {
+			t.Errorf("unexpected file part %q %q %q", hdr.Filename, data, hdr.Header.Get("Content-Type"))
+		}
+		fmt.Fprint(w, `{"data":{"upload":true}}`)
+	}))
+	defer srv.Close()
+
+	req := Request{
+		Query: "mutation($file: Upload!, $docs: [Upload], $handle: String) { upload(file: $file) }",
+		Variables: map[string]any{
+			"file":   "@" + avatar,
+			"docs":   []any{nil, "@" + avatar},
+			"handle": "@ada",
+		},
+	}
+	headers := map[string]string{"Content-Type": "application/json"}
+	result, err := NewClient().Execute(context.Background(), srv.URL, req, headers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Response.Data) != `{"upload":true}` {
+		t.Errorf("unexpected data: %s", result.Response.Data)
+	}
+}
+
+func TestFileRef(t *testing.T) {
+	for v, want := range map[any]bool{
+		"@./a.png": true, "@../a": true, "@/tmp/a": true, "@~/a": true,
+		"@ada": false, "./a.png": false, 42: false,
+	} {
+		if _, ok := FileRef(v); ok != want {
+			t.Errorf("FileRef(%v) = %v, want %v", v, ok, want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package graphql
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// streamAccept lists the response formats Stream negotiates, most preferred
+// first: multipart/mixed (Apollo Router, @defer), Server-Sent Events (Yoga,
+// graphql-sse) and plain JSON as a fallback.
+const streamAccept = "multipart/mixed;deferSpec=20220824;subscriptionSpec=1.0, text/event-stream, application/graphql-response+json, application/json"
+
+// Stream POSTs req and reads the response incrementally. Servers may answer
+// with text/event-stream or multipart/mixed; each chunk becomes an Event.
+// Incremental delivery payloads (@defer/@stream) are merged by path so every
+// Event carries the accumulated response. A plain JSON answer yields a single
+// Event.
+func (c *Client) Stream(ctx context.Context, endpoint string, req Request, headers map[string]string) (*Stream, error) {
+	headers, err := c.authorize(ctx, headers)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", streamAccept)
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	// Streams may stay open far longer than the client timeout allows.
+	streamClient := &http.Client{Transport: c.http.Transport}
+	resp, err := streamClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	c.rejected(resp.StatusCode)
+
+	s := newStream()
+	s.statusCode = resp.StatusCode
+	r := countingReader{r: resp.Body, s: s}
+
+	mediaType, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "text/event-stream":
+		go func() {
+			defer resp.Body.Close()
+			s.finish(streamErr(ctx, readSSE(ctx, r, s)))
+		}()
+	case strings.HasPrefix(mediaType, "multipart/"):
+		go func() {
+			defer resp.Body.Close()
+			s.finish(streamErr(ctx, readMultipart(ctx, r, params["boundary"], s)))
+		}()
+	default:
+		defer resp.Body.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+		var gqlResp Response
+		if err := json.Unmarshal(data, &gqlResp); err != nil {
+			return nil, fmt.Errorf("unexpected %s response (status %d): %s", mediaType, resp.StatusCode, snippet(data))
+		}
+		s.emit(ctx, gqlResp)
+		s.finish(nil)
+	}
+	return s, nil
+}
+
+// streamErr prefers the context error when the caller cancelled the stream.
+func streamErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// payloadSink turns raw JSON chunks into events, merging incremental
+// delivery payloads as they arrive.
+type payloadSink struct {
+	ctx context.Context
+	s   *Stream
+	acc *accumulator
+}
+
+// handle processes one chunk. done reports that the server signalled the
+// final payload.
+func (p *payloadSink) handle(data []byte) (done bool, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "{}" {
+		return false, nil // heartbeat
+	}
+
+	// Multipart subscriptions wrap each event: {"payload": {...}}, with
+	// transport errors reported as {"payload": null, "errors": [...]}.
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false, fmt.Errorf("decode payload: %w", err)
+	}
+	if payload, ok := probe["payload"]; ok {
+		if string(payload) == "null" {
+			var errs []Error
+			_ = json.Unmarshal(probe["errors"], &errs)
+			p.s.emit(p.ctx, Response{Errors: errs})
+			return true, nil
+		}
+		data = payload
+	}
+
+	var pl incrementalPayload
+	if err := json.Unmarshal(data, &pl); err != nil {
+		return false, fmt.Errorf("decode payload: %w", err)
+	}
+	if pl.HasNext == nil && p.acc == nil {
+		p.s.emit(p.ctx, Response{Data: pl.Data, Errors: pl.Errors})
+		return false, nil
+	}
+	if p.acc == nil {
+		p.acc = &accumulator{}
+	}
+	p.acc.apply(pl)
+	p.s.emit(p.ctx, p.acc.response())
+	return pl.HasNext != nil && !*pl.HasNext, nil
+}
+
+// readSSE parses a text/event-stream body. "next" (or unnamed) events carry
+// payloads; a "complete" event ends the stream.
+func readSSE(ctx context.Context, r io.Reader, s *Stream) error {
+	sink := &payloadSink{ctx: ctx, s: s}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() (bool, error) {
+		defer func() { event, data = "", nil }()
+		switch event {
+		case "complete":
+			return true, nil
+		case "", "next", "message":
+			if len(data) == 0 {
+				return false, nil
+			}
+			return sink.handle([]byte(strings.Join(data, "\n")))
+		}
+		return false, nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			done, err := dispatch()
+			if done || err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	_, err := dispatch()
+	return err
+}
+
+// readMultipart parses a multipart/mixed body where each part is a JSON
+// payload.
+func readMultipart(ctx context.Context, r io.Reader, boundary string, s *Stream) error {
+	if boundary == "" {
+		boundary = "-"
+	}
+	sink := &payloadSink{ctx: ctx, s: s}
+	mr := multipart.NewReader(r, boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		done, err := sink.handle(data)
+		if done || err != nil {
+			return err
+		}
+	}
+}
+
+// snippet shortens a response body for use in an error message.
+func snippet(data []byte) string {
+	const max = 200
+	s := strings.TrimSpace(string(data))
+	if len(s) > max {
+		return s[:max] + "…"
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func collect(t *testing.T, s *Stream) []string {
+	t.Helper()
+	var got []string
+	for {
+		ev, ok := s.Next()
+		if !ok {
+			break
+		}
+		got = append(got, string(ev.Response.Data))
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	return got
+}
+
+func TestStreamSSE(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			t.Errorf("expected event-stream in Accept, got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"tick\":1}}\n\n")
+		fmt.Fprint(w, "event: next\ndata: {\"data\":{\"tick\":2}}\n\n")
+		fmt.Fprint(w, "event: complete\ndata:\n\n")
+	}))
+	defer srv.Close()
+
+	s, err := NewClient().Stream(context.Background(), srv.URL, Request{Query: "subscription { tick }"}, nil)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	got := collect(t, s)
+	if len(got) != 2 || got[1] != `{"tick":2}` {
+		t.Errorf("unexpected events: %v", got)
+	}
+	if s.StatusCode() != 200 || s.Size() == 0 {
+		t.Errorf("expected status and size, got %d / %d", s.StatusCode(), s.Size())
+	}
+}
+
+func TestStreamMultipartDefer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", `multipart/mixed; boundary="-"`)
+		parts := []string{
+			`{"data":{"user":{"id":"1","posts":[{"id":"a"}]}},"hasNext":true}`,
+			`{"incremental":[{"path":["user"],"data":{"name":"Ada"}}],"hasNext":true}`,
+			`{"incremental":[{"path":["user","posts",1],"items":[{"id":"b"}]}],"hasNext":false}`,
+		}
+		for _, p := range parts {
+			fmt.Fprintf(w, "\r\n---\r\nContent-Type: application/json\r\n\r\n%s", p)
+		}
+		fmt.Fprint(w, "\r\n-----\r\n")
+	}))
+	defer srv.Close()
+
+	s, err := NewClient().Stream(context.Background(), srv.URL, Request{Query: "{ user { id ... @defer { name } } }"}, nil)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	got := collect(t, s)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 partial updates, got %d: %v", len(got), got)
+	}
+	want := `{"user":{"id":"1","name":"Ada","posts":[{"id":"a"},{"id":"b"}]}}`
+	if got[2] != want {
+		t.Errorf("merged data = %s, want %s", got[2], want)
+	}
+}
+
+func TestStreamMultipartSubscriptionPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", `multipart/mixed;boundary="graphql";subscriptionSpec=1.0`)
+		fmt.Fprint(w, "\r\n--graphql\r\ncontent-type: application/json\r\n\r\n{}")
+		fmt.Fprint(w, "\r\n--graphql\r\ncontent-type: application/json\r\n\r\n{\"payload\":{\"data\":{\"tick\":1}}}")
+		fmt.Fprint(w, "\r\n--graphql--\r\n")
+	}))
+	defer srv.Close()
+
+	s, err := NewClient().Stream(context.Background(), srv.URL, Request{Query: "subscription { tick }"}, nil)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	got := collect(t, s)
+	if len(got) != 1 || got[0] != `{"tick":1}` {
+		t.Errorf("expected heartbeat skipped and payload unwrapped, got %v", got)
+	}
+}
+
+func TestStreamPlainJSONFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"hello":"world"}}`)
+	}))
+	defer srv.Close()
+
+	s, err := NewClient().Stream(context.Background(), srv.URL, Request{Query: "{ hello }"}, nil)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	got := collect(t, s)
+	if len(got) != 1 || got[0] != `{"hello":"world"}` {
+		t.Errorf("unexpected events: %v", got)
+	}
+}
+
+func TestAccumulatorPendingIDs(t *testing.T) {
+	var acc accumulator
+	hasNext := true
+	acc.apply(incrementalPayload{
+		Data:    []byte(`{"user":{"id":"1","friends":[]}}`),
+		HasNext: &hasNext,
+		Pending: []pendingItem{{ID: "0", Path: []any{"user"}}, {ID: "1", Path: []any{"user", "friends"}}},
+	})
+	acc.apply(incrementalPayload{
+		Incremental: []incrementalItem{
+			{ID: "0", Data: []byte(`{"name":"Ada"}`)},
+			{ID: "1", Items: []json.RawMessage{[]byte(`"Bob"`), []byte(`"Cy"`)}},
+			{ID: "0", SubPath: []any{"address"}, Data: []byte(`{"city":"Paris"}`), Errors: []Error{{Message: "partial"}}},
+		},
+	})
+	resp := acc.response()
+	want := `{"user":{"address":{"city":"Paris"},"friends":["Bob","Cy"],"id":"1","name":"Ada"}}`
+	if string(resp.Data) != want {
+		t.Errorf("data = %s, want %s", resp.Data, want)
+	}
+	if len(resp.Errors) != 1 {
+		t.Errorf("expected incremental errors to accumulate, got %v", resp.Errors)
+	}
+}
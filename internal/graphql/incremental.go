@@ -0,0 +1,182 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// incrementalPayload is one chunk of an incremental delivery response
+// (@defer/@stream). Both the 2022-08-24 path-based format and the newer
+// pending/id format are understood.
+type incrementalPayload struct {
+	Data        json.RawMessage   `json:"data,omitempty"`
+	Errors      []Error           `json:"errors,omitempty"`
+	HasNext     *bool             `json:"hasNext,omitempty"`
+	Incremental []incrementalItem `json:"incremental,omitempty"`
+	Pending     []pendingItem     `json:"pending,omitempty"`
+}
+
+type incrementalItem struct {
+	ID      string            `json:"id,omitempty"`
+	Path    []any             `json:"path,omitempty"`
+	SubPath []any             `json:"subPath,omitempty"`
+	Data    json.RawMessage   `json:"data,omitempty"`
+	Items   []json.RawMessage `json:"items,omitempty"`
+	Errors  []Error           `json:"errors,omitempty"`
+}
+
+type pendingItem struct {
+	ID   string `json:"id"`
+	Path []any  `json:"path"`
+}
+
+// accumulator merges incremental payloads into a single response.
+type accumulator struct {
+	data    any
+	errors  []Error
+	pending map[string][]any
+}
+
+// apply merges p into the accumulated response.
+func (a *accumulator) apply(p incrementalPayload) {
+	if len(p.Data) > 0 && string(p.Data) != "null" {
+		var v any
+		if json.Unmarshal(p.Data, &v) == nil {
+			a.data = v
+		}
+	}
+	a.errors = append(a.errors, p.Errors...)
+	for _, pd := range p.Pending {
+		if a.pending == nil {
+			a.pending = make(map[string][]any)
+		}
+		a.pending[pd.ID] = pd.Path
+	}
+
+	for _, inc := range p.Incremental {
+		a.errors = append(a.errors, inc.Errors...)
+		path := inc.Path
+		if inc.ID != "" {
+			path = append(append([]any{}, a.pending[inc.ID]...), inc.SubPath...)
+		}
+		if len(inc.Data) > 0 {
+			var v any
+			if json.Unmarshal(inc.Data, &v) == nil {
+				a.data = mergeAt(a.data, path, v)
+			}
+		}
+		if len(inc.Items) > 0 {
+			items := make([]any, 0, len(inc.Items))
+			for _, raw := range inc.Items {
+				var v any
+				_ = json.Unmarshal(raw, &v)
+				items = append(items, v)
+			}
+			a.data = appendItemsAt(a.data, path, items, inc.ID != "")
+		}
+	}
+}
+
+// response returns the merged response so far.
+func (a *accumulator) response() Response {
+	var resp Response
+	if a.data != nil {
+		resp.Data, _ = json.Marshal(a.data)
+	}
+	resp.Errors = append([]Error(nil), a.errors...)
+	return resp
+}
+
+// mergeAt deep-merges v into the value found at path inside root.
+func mergeAt(root any, path []any, v any) any {
+	if len(path) == 0 {
+		return deepMerge(root, v)
+	}
+	switch node := root.(type) {
+	case map[string]any:
+		key := pathKey(path[0])
+		node[key] = mergeAt(node[key], path[1:], v)
+		return node
+	case []any:
+		if i, ok := pathIndex(path[0]); ok && i < len(node) {
+			node[i] = mergeAt(node[i], path[1:], v)
+		}
+		return node
+	case nil:
+		if _, ok := pathIndex(path[0]); ok {
+			return root
+		}
+		return map[string]any{pathKey(path[0]): mergeAt(nil, path[1:], v)}
+	}
+	return root
+}
+
+// appendItemsAt adds streamed list items. In the path-based format the path
+// ends with the index of the first item; in the id-based format it points
+// at the list itself and items are appended.
+func appendItemsAt(root any, path []any, items []any, pathIsList bool) any {
+	if !pathIsList {
+		if len(path) == 0 {
+			return root
+		}
+		return setList(root, path[:len(path)-1], func(list []any) []any {
+			start, ok := pathIndex(path[len(path)-1])
+			if !ok || start > len(list) {
+				start = len(list)
+			}
+			return append(list[:start], items...)
+		})
+	}
+	return setList(root, path, func(list []any) []any { return append(list, items...) })
+}
+
+func setList(root any, path []any, update func([]any) []any) any {
+	if len(path) == 0 {
+		list, _ := root.([]any)
+		return update(list)
+	}
+	switch node := root.(type) {
+	case map[string]any:
+		key := pathKey(path[0])
+		node[key] = setList(node[key], path[1:], update)
+		return node
+	case []any:
+		if i, ok := pathIndex(path[0]); ok && i < len(node) {
+			node[i] = setList(node[i], path[1:], update)
+		}
+		return node
+	}
+	return root
+}
+
+func deepMerge(dst, src any) any {
+	dm, ok1 := dst.(map[string]any)
+	sm, ok2 := src.(map[string]any)
+	if !ok1 || !ok2 {
+		return src
+	}
+	for k, v := range sm {
+		dm[k] = deepMerge(dm[k], v)
+	}
+	return dm
+}
+
+func pathKey(p any) string {
+	switch v := p.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.Itoa(int(v))
+	}
+	return ""
+}
+
+func pathIndex(p any) (int, bool) {
+	switch v := p.(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	}
+	return 0, false
+}
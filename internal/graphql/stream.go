@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Event is a single payload delivered by a streaming operation.
+type Event struct {
+	Response Response
+	Received time.Time
+}
+
+// Stream delivers the events of a long-running operation (a subscription or
+// an @defer/@stream query) until the server completes it or the caller
+// cancels its context.
+type Stream struct {
+	events chan Event
+	done   chan struct{}
+	err    error
+
+	statusCode int
+	size       atomic.Int64
+}
+
+func newStream() *Stream {
+	return &Stream{
+		events: make(chan Event, 16),
+		done:   make(chan struct{}),
+	}
+}
+
+// Next blocks until the next event arrives. ok is false once the stream has
+// ended; Err then reports why.
+func (s *Stream) Next() (ev Event, ok bool) {
+	select {
+	case ev = <-s.events:
+		return ev, true
+	case <-s.done:
+		// Drain events that were queued before the stream ended.
+		select {
+		case ev = <-s.events:
+			return ev, true
+		default:
+			return Event{}, false
+		}
+	}
+}
+
+// Err returns the reason the stream ended: nil when the server completed the
+// operation, the context error when the caller cancelled it.
+func (s *Stream) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// StatusCode returns the HTTP status of the response that opened the stream.
+func (s *Stream) StatusCode() int { return s.statusCode }
+
+// Size returns the number of payload bytes received so far.
+func (s *Stream) Size() int { return int(s.size.Load()) }
+
+// countingReader adds every byte read to the stream's size.
+type countingReader struct {
+	r io.Reader
+	s *Stream
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.s.size.Add(int64(n))
+	return n, err
+}
+
+// emit queues an event, giving up if ctx is cancelled first so a stalled
+// consumer cannot block the transport goroutine forever.
+func (s *Stream) emit(ctx context.Context, resp Response) bool {
+	select {
+	case s.events <- Event{Response: resp, Received: time.Now()}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// finish ends the stream with err. Must be called exactly once.
+func (s *Stream) finish(err error) {
+	s.err = err
+	close(s.done)
+}
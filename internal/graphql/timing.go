@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing splits the duration of a request into its phases. Phases that did
+// not happen, such as DNS and connect on a reused connection, are zero.
+type Timing struct {
+	DNS      time.Duration // name lookup
+	Connect  time.Duration // TCP connect
+	TLS      time.Duration // TLS handshake
+	Wait     time.Duration // request written until the first response byte
+	Download time.Duration // first response byte until the body was read
+	TTFB     time.Duration // start until the first response byte
+	Reused   bool          // the connection came from the pool
+}
+
+// tracer collects httptrace events. Dial callbacks run on the transport's
+// goroutines, hence the lock.
+type tracer struct {
+	mu    sync.Mutex
+	start time.Time
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	wroteRequest, firstByte   time.Time
+	reused                    bool
+}
+
+// withTrace starts timing and returns ctx with the trace attached.
+func (t *tracer) withTrace(ctx context.Context) context.Context {
+	t.start = time.Now()
+	set := func(field *time.Time) {
+		t.mu.Lock()
+		if field.IsZero() {
+			*field = time.Now()
+		}
+		t.mu.Unlock()
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { set(&t.dnsStart) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { set(&t.dnsDone) },
+		ConnectStart:      func(string, string) { set(&t.connectStart) },
+		ConnectDone:       func(string, string, error) { set(&t.connectDone) },
+		TLSHandshakeStart: func() { set(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { set(&t.firstByte) },
+	})
+}
+
+// timing returns the phases of a request whose body was read at end.
+func (t *tracer) timing(end time.Time) Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	between := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() {
+			return 0
+		}
+		return to.Sub(from)
+	}
+	return Timing{
+		DNS:      between(t.dnsStart, t.dnsDone),
+		Connect:  between(t.connectStart, t.connectDone),
+		TLS:      between(t.tlsStart, t.tlsDone),
+		Wait:     between(t.wroteRequest, t.firstByte),
+		Download: between(t.firstByte, end),
+		TTFB:     between(t.start, t.firstByte),
+		Reused:   t.reused,
+	}
+}
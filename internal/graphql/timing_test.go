@@ -0,0 +1,42 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientTiming(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithTransport(srv.Client().Transport))
+	result, err := client.Execute(context.Background(), srv.URL, Request{Query: "{ a }"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tm := result.Timing
+	if tm.Reused || tm.Connect <= 0 || tm.TLS <= 0 {
+		t.Errorf("expected a fresh TLS connection, got %+v", tm)
+	}
+	if tm.Wait < 20*time.Millisecond || tm.TTFB < tm.Wait {
+		t.Errorf("expected server wait of at least 20ms within TTFB, got %+v", tm)
+	}
+	if tm.TTFB > result.Duration {
+		t.Errorf("TTFB %v exceeds total duration %v", tm.TTFB, result.Duration)
+	}
+
+	result, err = client.Execute(context.Background(), srv.URL, Request{Query: "{ a }"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tm := result.Timing; !tm.Reused || tm.Connect != 0 || tm.TLS != 0 {
+		t.Errorf("expected a reused connection without handshake, got %+v", tm)
+	}
+}
@@ -0,0 +1,98 @@
+package graphql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TransportConfig holds the connection settings of an environment.
+type TransportConfig struct {
+	CAFile   string // PEM bundle trusted in addition to the system roots
+	CertFile string // client certificate for mutual TLS
+	KeyFile  string // client key for mutual TLS
+	Insecure bool   // skip certificate verification
+	Proxy    string // proxy URL; empty uses HTTP_PROXY/HTTPS_PROXY
+}
+
+// NewTransport builds an HTTP transport from cfg. File paths may start
+// with ~/.
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	tlsCfg := &tls.Config{InsecureSkipVerify: cfg.Insecure}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(ExpandPath(cfg.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle: no certificates in %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, errors.New("client certificate and key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(ExpandPath(cfg.CertFile), ExpandPath(cfg.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	t.TLSClientConfig = tlsCfg
+
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", cfg.Proxy)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	return t, nil
+}
+
+// WithTimeout sets the overall request timeout. Zero disables it.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.http.Timeout = d }
+}
+
+// WithTransport sets the HTTP transport, e.g. one built by NewTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) { c.http.Transport = rt }
+}
+
+// WithConnections keeps up to n idle connections per host between requests,
+// so a client sending n at once reuses them instead of dialing anew. It
+// applies to the transport set by earlier options.
+func WithConnections(n int) Option {
+	return func(c *Client) {
+		rt := c.http.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		t, ok := rt.(*http.Transport)
+		if !ok {
+			return
+		}
+		if t == http.DefaultTransport {
+			t = t.Clone()
+		}
+		t.MaxIdleConnsPerHost = n
+		if t.MaxIdleConns != 0 && t.MaxIdleConns < n {
+			t.MaxIdleConns = n
+		}
+		c.http.Transport = t
+	}
+}
@@ -0,0 +1,106 @@
+package graphql
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTransportCustomCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer srv.Close()
+	req := Request{Query: "{ hello }"}
+
+	if _, err := NewClient().Execute(context.Background(), srv.URL, req, nil); err == nil {
+		t.Fatal("expected unknown CA to fail")
+	}
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(ca, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tr, err := NewTransport(TransportConfig{CAFile: ca})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	if _, err := NewClient(WithTransport(tr)).Execute(context.Background(), srv.URL, req, nil); err != nil {
+		t.Errorf("expected custom CA to be trusted: %v", err)
+	}
+
+	tr, _ = NewTransport(TransportConfig{Insecure: true})
+	if _, err := NewClient(WithTransport(tr)).Execute(context.Background(), srv.URL, req, nil); err != nil {
+		t.Errorf("expected insecure transport to skip verification: %v", err)
+	}
+}
+
+func TestTransportProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		fmt.Fprint(w, `{"data":{}}`)
+	}))
+	defer proxy.Close()
+
+	tr, err := NewTransport(TransportConfig{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewTransport: %v", err)
+	}
+	if _, err := NewClient(WithTransport(tr)).Execute(context.Background(), "http://api.internal/graphql", Request{Query: "{ hello }"}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxied != "http://api.internal/graphql" {
+		t.Errorf("expected request through proxy, got %q", proxied)
+	}
+}
+
+func TestTransportConfigErrors(t *testing.T) {
+	for name, cfg := range map[string]TransportConfig{
+		"missing CA": {CAFile: filepath.Join(t.TempDir(), "nope.pem")},
+		"cert only":  {CertFile: "client.pem"},
+		"bad proxy":  {Proxy: "not a url"},
+	} {
+		if _, err := NewTransport(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := NewClient(WithTimeout(20 * time.Millisecond))
+	if _, err := client.Execute(context.Background(), srv.URL, Request{Query: "{ hello }"}, nil); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+func TestWithConnections(t *testing.T) {
+	c := NewClient(WithConnections(50))
+	tr, ok := c.http.Transport.(*http.Transport)
+	if !ok || tr == http.DefaultTransport || tr.MaxIdleConnsPerHost != 50 {
+		t.Fatalf("expected a copy of the default transport keeping 50 connections, got %#v", c.http.Transport)
+	}
+	if http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost == 50 {
+		t.Error("expected the default transport left alone")
+	}
+
+	own, _ := NewTransport(TransportConfig{Insecure: true})
+	c = NewClient(WithTransport(own), WithConnections(200))
+	if c.http.Transport != own || own.MaxIdleConnsPerHost != 200 || own.MaxIdleConns < 200 {
+		t.Errorf("expected the configured transport to keep 200 connections, got %d/%d", own.MaxIdleConnsPerHost, own.MaxIdleConns)
+	}
+}
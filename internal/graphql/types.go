@@ -2,12 +2,17 @@ package graphql
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 )
 
 type Request struct {
-	Query     string         `json:"query"`
-	Variables map[string]any `json:"variables,omitempty"`
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"`
 }
 
 type Response struct {
@@ -15,16 +20,90 @@ type Response struct {
 	Errors []Error         `json:"errors,omitempty"`
 }
 
+// Error is a GraphQL error as defined by the spec: a message, the query
+// locations it refers to, the response path of the failing field and
+// server-specific extensions.
 type Error struct {
-	Message string `json:"message"`
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
 }
 
+// Location is a 1-based line/column position in the query document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Code returns the error code from extensions: "code" (Apollo, Yoga, most
+// servers) or "classification" (graphql-java, Spring). Empty if neither is set.
+func (e Error) Code() string {
+	for _, k := range []string{"code", "classification"} {
+		switch v := e.Extensions[k].(type) {
+		case string:
+			return v
+		case map[string]any:
+			// graphql-java may nest the classification as {"type": "..."}
+			if t, ok := v["type"].(string); ok {
+				return t
+			}
+		}
+	}
+	return ""
+}
+
+// PathString renders the error path as user.posts[1].title.
+func (e Error) PathString() string {
+	var b strings.Builder
+	for _, p := range e.Path {
+		switch v := p.(type) {
+		case string:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(v)
+		case float64:
+			fmt.Fprintf(&b, "[%d]", int(v))
+		case int:
+			fmt.Fprintf(&b, "[%d]", v)
+		}
+	}
+	return b.String()
+}
+
+// PersistedQueryStatus reports how an Automatic Persisted Query was resolved.
+type PersistedQueryStatus int
+
+const (
+	PersistedNone PersistedQueryStatus = iota // not sent as a persisted query
+	PersistedHit                              // the server knew the hash
+	PersistedMiss                             // the full query had to be sent
+)
+
 type Result struct {
-	Response   Response
-	RawBody    []byte // raw response body, set when JSON decode fails
-	StatusCode int
-	Duration   time.Duration
-	Size       int
+	Response       Response
+	RawBody        []byte // raw response body, set when JSON decode fails
+	StatusCode     int
+	Duration       time.Duration
+	Size           int
+	PersistedQuery PersistedQueryStatus
+	Timing         Timing
+	Exchange       *Exchange
+}
+
+// Exchange is the HTTP request and response behind a Result, as they went
+// over the wire.
+type Exchange struct {
+	Method          string
+	URL             string
+	RequestHeaders  http.Header
+	RequestBody     []byte // at most maxCapturedBody bytes
+	RequestSize     int64  // full size of the request body
+	Proto           string // e.g. HTTP/1.1
+	Status          string // e.g. 200 OK
+	ResponseHeaders http.Header
+	ResponseBody    []byte
 }
 
 func (r Response) HasErrors() bool {
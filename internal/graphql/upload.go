@@ -0,0 +1,162 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FileRef returns the path referenced by a variable value such as
+// "@./avatar.png". Only "@" followed by ./, ../, / or ~/ is a file reference,
+// so ordinary strings starting with "@" (handles, emails) are left alone.
+func FileRef(v any) (path string, ok bool) {
+	s, isStr := v.(string)
+	if !isStr || !strings.HasPrefix(s, "@") {
+		return "", false
+	}
+	p := s[1:]
+	for _, prefix := range []string{"./", "../", "/", "~/"} {
+		if strings.HasPrefix(p, prefix) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// ExpandPath resolves a leading ~/ to the user's home directory.
+func ExpandPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// uploadFile is a file reference found in the variables, with the
+// object paths (e.g. "variables.files.0") it is mapped to.
+type uploadFile struct {
+	path  string
+	paths []string
+}
+
+// extractFiles returns a copy of vars with every file reference replaced by
+// null, and the referenced files. The same file used twice is sent once.
+func extractFiles(vars map[string]any) (map[string]any, []uploadFile) {
+	var files []uploadFile
+	index := map[string]int{}
+	var walk func(v any, path string) any
+	walk = func(v any, path string) any {
+		if p, ok := FileRef(v); ok {
+			i, seen := index[p]
+			if !seen {
+				i = len(files)
+				index[p] = i
+				files = append(files, uploadFile{path: p})
+			}
+			files[i].paths = append(files[i].paths, path)
+			return nil
+		}
+		switch val := v.(type) {
+		case map[string]any:
+			// Map iteration is random; walk keys in order so that part
+			// numbering and the map are stable.
+			out := make(map[string]any, len(val))
+			for _, k := range slices.Sorted(maps.Keys(val)) {
+				out[k] = walk(val[k], path+"."+k)
+			}
+			return out
+		case []any:
+			out := make([]any, len(val))
+			for i, item := range val {
+				out[i] = walk(item, path+"."+strconv.Itoa(i))
+			}
+			return out
+		}
+		return v
+	}
+	out, _ := walk(vars, "variables").(map[string]any)
+	return out, files
+}
+
+// newUploadRequest builds a GraphQL multipart request
+// (https://github.com/jaydenseric/graphql-multipart-request-spec) with the
+// operations, map and file parts.
+func newUploadRequest(ctx context.Context, endpoint string, req Request, files []uploadFile) (*http.Request, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	ops, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	fileMap := make(map[string][]string, len(files))
+	for i, f := range files {
+		fileMap[strconv.Itoa(i)] = f.paths
+	}
+	mapJSON, err := json.Marshal(fileMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal map: %w", err)
+	}
+	if err := w.WriteField("operations", string(ops)); err != nil {
+		return nil, err
+	}
+	if err := w.WriteField("map", string(mapJSON)); err != nil {
+		return nil, err
+	}
+
+	for i, f := range files {
+		if err := writeFilePart(w, strconv.Itoa(i), ExpandPath(f.path)); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", w.FormDataContentType())
+	// Apollo Server's CSRF protection rejects multipart requests without a
+	// non-simple header.
+	httpReq.Header.Set("Apollo-Require-Preflight", "true")
+	return httpReq, nil
+}
+
+func writeFilePart(w *multipart.Writer, field, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+	defer f.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, field, filepath.Base(path)))
+	h.Set("Content-Type", contentType)
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return fmt.Errorf("upload %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// wsProtocol is the subprotocol name of the graphql-transport-ws protocol
+// (https://github.com/enisdenjo/graphql-ws/blob/master/PROTOCOL.md).
+const wsProtocol = "graphql-transport-ws"
+
+// wsAckTimeout bounds how long we wait for connection_ack after connection_init.
+const wsAckTimeout = 10 * time.Second
+
+// subscriptionID is the operation id used for the single subscription we run
+// per connection.
+const subscriptionID = "1"
+
+type wsMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscribe opens a WebSocket to endpoint and runs req as a subscription
+// using the graphql-transport-ws protocol. headers are sent as the
+// connection_init payload. Cancelling ctx sends complete and closes the
+// socket with a normal closure.
+func (c *Client) Subscribe(ctx context.Context, endpoint string, req Request, headers map[string]string) (*Stream, error) {
+	headers, err := c.authorize(ctx, headers)
+	if err != nil {
+		return nil, err
+	}
+	conn, _, err := websocket.Dial(ctx, WebSocketURL(endpoint), &websocket.DialOptions{
+		HTTPClient:   c.http,
+		Subprotocols: []string{wsProtocol},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dial websocket: %w", err)
+	}
+	conn.SetReadLimit(-1)
+
+	if err := wsHandshake(ctx, conn, headers); err != nil {
+		conn.Close(websocket.StatusProtocolError, "handshake failed")
+		return nil, err
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		conn.Close(websocket.StatusInternalError, "")
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	if err := wsWrite(ctx, conn, wsMessage{ID: subscriptionID, Type: "subscribe", Payload: payload}); err != nil {
+		conn.Close(websocket.StatusInternalError, "")
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+
+	s := newStream()
+	s.statusCode = http.StatusSwitchingProtocols
+	go wsReadLoop(ctx, conn, s)
+	return s, nil
+}
+
+// WebSocketURL maps an http(s) endpoint to its ws(s) equivalent. Endpoints
+// that already use a ws scheme are returned unchanged.
+func WebSocketURL(endpoint string) string {
+	switch {
+	case strings.HasPrefix(endpoint, "https://"):
+		return "wss://" + strings.TrimPrefix(endpoint, "https://")
+	case strings.HasPrefix(endpoint, "http://"):
+		return "ws://" + strings.TrimPrefix(endpoint, "http://")
+	}
+	return endpoint
+}
+
+// wsHandshake sends connection_init and waits for connection_ack,
+// answering pings in the meantime.
+func wsHandshake(ctx context.Context, conn *websocket.Conn, headers map[string]string) error {
+	var payload json.RawMessage
+	if len(headers) > 0 {
+		payload, _ = json.Marshal(headers)
+	}
+	if err := wsWrite(ctx, conn, wsMessage{Type: "connection_init", Payload: payload}); err != nil {
+		return fmt.Errorf("connection_init: %w", err)
+	}
+
+	ackCtx, cancel := context.WithTimeout(ctx, wsAckTimeout)
+	defer cancel()
+	for {
+		msg, err := wsRead(ackCtx, conn)
+		if err != nil {
+			return fmt.Errorf("waiting for connection_ack: %w", err)
+		}
+		switch msg.Type {
+		case "connection_ack":
+			return nil
+		case "ping":
+			if err := wsWrite(ctx, conn, wsMessage{Type: "pong"}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// wsReadLoop forwards next/error/complete messages into s until the server
+// completes the subscription, the socket fails, or ctx is cancelled.
+func wsReadLoop(ctx context.Context, conn *websocket.Conn, s *Stream) {
+	// Reads use a background context: cancelling a read context makes the
+	// library drop the connection, and we want to say goodbye properly.
+	stop := context.AfterFunc(ctx, func() {
+		writeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = wsWrite(writeCtx, conn, wsMessage{ID: subscriptionID, Type: "complete"})
+		conn.Close(websocket.StatusNormalClosure, "")
+	})
+	defer stop()
+
+	for {
+		_, data, err := conn.Read(context.Background())
+		if err == nil {
+			s.size.Add(int64(len(data)))
+		}
+		var msg wsMessage
+		if err == nil {
+			msg, err = decodeWSMessage(data)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				s.finish(ctx.Err())
+				return
+			}
+			conn.CloseNow()
+			s.finish(fmt.Errorf("read websocket: %w", err))
+			return
+		}
+
+		switch msg.Type {
+		case "next":
+			var resp Response
+			if err := json.Unmarshal(msg.Payload, &resp); err != nil {
+				conn.Close(websocket.StatusProtocolError, "invalid next payload")
+				s.finish(fmt.Errorf("decode next payload: %w", err))
+				return
+			}
+			if !s.emit(ctx, resp) {
+				continue // cancelled — AfterFunc closes the socket
+			}
+		case "error":
+			var errs []Error
+			if err := json.Unmarshal(msg.Payload, &errs); err != nil {
+				errs = []Error{{Message: string(msg.Payload)}}
+			}
+			s.emit(ctx, Response{Errors: errs})
+			conn.Close(websocket.StatusNormalClosure, "")
+			s.finish(nil)
+			return
+		case "complete":
+			conn.Close(websocket.StatusNormalClosure, "")
+			s.finish(nil)
+			return
+		case "ping":
+			_ = wsWrite(context.Background(), conn, wsMessage{Type: "pong"})
+		}
+	}
+}
+
+func wsRead(ctx context.Context, conn *websocket.Conn) (wsMessage, error) {
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		return wsMessage{}, err
+	}
+	return decodeWSMessage(data)
+}
+
+func decodeWSMessage(data []byte) (wsMessage, error) {
+	var msg wsMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return wsMessage{}, fmt.Errorf("decode message: %w", err)
+	}
+	if msg.Type == "" {
+		return wsMessage{}, errors.New("message without type")
+	}
+	return msg, nil
+}
+
+func wsWrite(ctx context.Context, conn *websocket.Conn, msg wsMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageText, data)
+}
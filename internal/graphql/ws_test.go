@@ -0,0 +1,139 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// wsServer runs a graphql-transport-ws server that acks, checks the init
+// payload, and sends count events before completing (or blocks when count<0).
+func wsServer(t *testing.T, count int, gotComplete chan<- struct{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{wsProtocol}})
+		if err != nil {
+			t.Errorf("accept: %v", err)
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+
+		init, err := wsRead(ctx, conn)
+		if err != nil || init.Type != "connection_init" {
+			t.Errorf("expected connection_init, got %+v (%v)", init, err)
+			return
+		}
+		var payload map[string]string
+		_ = json.Unmarshal(init.Payload, &payload)
+		if payload["Authorization"] != "Bearer t" {
+			t.Errorf("expected headers in init payload, got %v", payload)
+		}
+		_ = wsWrite(ctx, conn, wsMessage{Type: "connection_ack"})
+
+		sub, err := wsRead(ctx, conn)
+		if err != nil || sub.Type != "subscribe" {
+			t.Errorf("expected subscribe, got %+v (%v)", sub, err)
+			return
+		}
+		var req Request
+		_ = json.Unmarshal(sub.Payload, &req)
+		if req.Query != "subscription { tick }" {
+			t.Errorf("unexpected query %q", req.Query)
+		}
+
+		for i := 0; i < count; i++ {
+			data := fmt.Sprintf(`{"data":{"tick":%d}}`, i)
+			_ = wsWrite(ctx, conn, wsMessage{ID: sub.ID, Type: "next", Payload: json.RawMessage(data)})
+		}
+		if count >= 0 {
+			_ = wsWrite(ctx, conn, wsMessage{ID: sub.ID, Type: "complete"})
+			conn.Close(websocket.StatusNormalClosure, "")
+			return
+		}
+
+		msg, err := wsRead(ctx, conn)
+		if err == nil && msg.Type == "complete" && gotComplete != nil {
+			close(gotComplete)
+		}
+		conn.Close(websocket.StatusNormalClosure, "")
+	}))
+}
+
+func TestSubscribeReceivesEvents(t *testing.T) {
+	srv := wsServer(t, 3, nil)
+	defer srv.Close()
+
+	client := NewClient()
+	stream, err := client.Subscribe(context.Background(), srv.URL, Request{Query: "subscription { tick }"}, map[string]string{"Authorization": "Bearer t"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	var got []string
+	for {
+		ev, ok := stream.Next()
+		if !ok {
+			break
+		}
+		if ev.Received.IsZero() {
+			t.Error("expected event timestamp")
+		}
+		got = append(got, string(ev.Response.Data))
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events, got %d: %v", len(got), got)
+	}
+	if got[2] != `{"tick":2}` {
+		t.Errorf("unexpected last event: %s", got[2])
+	}
+	if stream.Err() != nil {
+		t.Errorf("expected clean completion, got %v", stream.Err())
+	}
+}
+
+func TestSubscribeCancelSendsComplete(t *testing.T) {
+	gotComplete := make(chan struct{})
+	srv := wsServer(t, -1, gotComplete)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewClient()
+	stream, err := client.Subscribe(ctx, srv.URL, Request{Query: "subscription { tick }"}, map[string]string{"Authorization": "Bearer t"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	cancel()
+
+	if _, ok := stream.Next(); ok {
+		t.Error("expected no events after cancel")
+	}
+	if !errors.Is(stream.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", stream.Err())
+	}
+	select {
+	case <-gotComplete:
+	case <-time.After(2 * time.Second):
+		t.Error("server did not receive complete")
+	}
+}
+
+func TestWebSocketURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:4000/graphql": "ws://localhost:4000/graphql",
+		"https://api.example.com/gql":   "wss://api.example.com/gql",
+		"wss://api.example.com/gql":     "wss://api.example.com/gql",
+	}
+	for in, want := range tests {
+		if got := WebSocketURL(in); got != want {
+			t.Errorf("WebSocketURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -38,6 +38,7 @@ type sidebarItem struct {
 	entryID   string    // entry ID (empty for folders)
 	endpoint  string    // dim suffix for entries
 	collapsed bool      // only for kindFolder
+	shared    bool      // only for kindFolder: a workspace collection
 	createdAt time.Time // entry timestamp
 }
 
@@ -92,6 +93,9 @@ func needsScroll(s string, maxWidth int) bool {
 	return lipgloss.Width(s) > maxWidth
 }
 
+// sharedTag follows the name of workspace collections.
+const sharedTag = " ws"
+
 // renderFolderLine renders a folder item as a single line.
 func renderFolderLine(si sidebarItem, selected bool, width, scrollOffset int) string {
 	var prefix string
@@ -108,9 +112,14 @@ func renderFolderLine(si sidebarItem, selected bool, width, scrollOffset int) st
 		icon = "📂 "
 	}
 
+	var tag string
+	if si.shared {
+		tag = sharedTag
+	}
+
 	prefixW := lipgloss.Width(prefix)
 	iconW := lipgloss.Width(icon)
-	nameMax := width - prefixW - iconW
+	nameMax := width - prefixW - iconW - lipgloss.Width(tag)
 	if nameMax < 1 {
 		nameMax = 1
 	}
@@ -128,7 +137,7 @@ func renderFolderLine(si sidebarItem, selected bool, width, scrollOffset int) st
 		nameStr = hTitleStyle.Render(name)
 	}
 
-	return prefix + icon + nameStr
+	return prefix + icon + nameStr + hDimStyle.Render(tag)
 }
 
 // formatTimestamp formats a timestamp for display in the sidebar.
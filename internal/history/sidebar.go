@@ -23,6 +23,16 @@ type LoadEntryMsg struct {
 	Entry Entry
 }
 
+// RunFolderMsg is sent to run the entries of a folder as a suite.
+type RunFolderMsg struct {
+	Folder string
+}
+
+// ShowRunsMsg is sent to list the response snapshots of an entry.
+type ShowRunsMsg struct {
+	Entry Entry
+}
+
 // SidebarUpdatedMsg signals the sidebar content changed and needs re-render.
 type SidebarUpdatedMsg struct{}
 
@@ -177,6 +187,7 @@ func (sb *Sidebar) rebuildSections() {
 			kind:      kindFolder,
 			name:      f.Name,
 			collapsed: collapsed,
+			shared:    f.Workspace,
 		})
 		if !collapsed {
 			for _, e := range f.Entries {
@@ -449,6 +460,9 @@ func (sb *Sidebar) nameMaxForItem(si sidebarItem) int {
 		} else {
 			overhead += lipgloss.Width("📂 ")
 		}
+		if si.shared {
+			overhead += lipgloss.Width(sharedTag)
+		}
 	case kindEntry:
 		if si.folder != "" {
 			overhead += 2 // indent
@@ -587,6 +601,10 @@ func (sb Sidebar) Update(msg tea.Msg) (Sidebar, tea.Cmd) {
 			return sb.handleCollapse()
 		case "N":
 			return sb.handleCreateFolder()
+		case "R":
+			return sb.handleRunFolder()
+		case "s":
+			return sb.handleShowRuns()
 		case "r":
 			return sb.startRename()
 		case "d":
@@ -641,6 +659,33 @@ func (sb Sidebar) handleCollapse() (Sidebar, tea.Cmd) {
 	return sb, nil
 }
 
+// handleRunFolder runs the selected folder, or the folder of the selected
+// entry.
+func (sb Sidebar) handleRunFolder() (Sidebar, tea.Cmd) {
+	si := sb.selectedItem()
+	if si == nil {
+		return sb, nil
+	}
+	folder := si.folder
+	if si.kind == kindFolder {
+		folder = si.name
+	}
+	if folder == "" {
+		return sb, nil
+	}
+	return sb, func() tea.Msg { return RunFolderMsg{Folder: folder} }
+}
+
+// handleShowRuns lists the saved responses of the selected entry.
+func (sb Sidebar) handleShowRuns() (Sidebar, tea.Cmd) {
+	entry := sb.SelectedEntry()
+	if entry == nil {
+		return sb, nil
+	}
+	e := *entry
+	return sb, func() tea.Msg { return ShowRunsMsg{Entry: e} }
+}
+
 func (sb Sidebar) handleCreateFolder() (Sidebar, tea.Cmd) {
 	name := "New Folder"
 	i := 1
@@ -670,6 +715,10 @@ func (sb Sidebar) startRename() (Sidebar, tea.Cmd) {
 		return sb, nil
 	}
 
+	if si.kind == kindFolder && si.shared {
+		return sb, nil
+	}
+
 	sb.renaming = true
 	sb.renameInput.SetValue(si.name)
 	sb.renameInput.CursorEnd()
@@ -726,6 +775,9 @@ func (sb Sidebar) handleDelete() (Sidebar, tea.Cmd) {
 
 	switch si.kind {
 	case kindFolder:
+		if si.shared {
+			return sb, nil
+		}
 		// Folders require confirmation
 		sb.confirming = true
 		sb.confirmName = si.name
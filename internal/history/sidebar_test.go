@@ -138,6 +138,63 @@ func TestSidebarSelectedFolder(t *testing.T) {
 	}
 }
 
+func TestSidebarRunFolder(t *testing.T) {
+	store := testStore(t)
+	_ = store.CreateFolder("Smoke")
+	e1 := Entry{ID: GenerateID(), Name: "GetUsers", Query: "{ users }", CreatedAt: time.Now()}
+	_ = store.AddEntry(e1)
+	_ = store.MoveEntry(e1.ID, "Smoke")
+	e2 := Entry{ID: GenerateID(), Name: "GetPosts", Query: "{ posts }", CreatedAt: time.Now()}
+	_ = store.AddEntry(e2)
+
+	sb := NewSidebar(store)
+	sb.SetSize(40, 20)
+	press := func() tea.Msg {
+		_, cmd := sb.Update(tea.KeyPressMsg{Code: 'R', Text: "R"})
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+
+	for i := range 2 { // the folder, then its entry
+		sb.SelectInSection(sectionFolders, i)
+		if msg, ok := press().(RunFolderMsg); !ok || msg.Folder != "Smoke" {
+			t.Errorf("item %d: expected RunFolderMsg for Smoke, got %#v", i, msg)
+		}
+	}
+	sb.SelectInSection(sectionRecent, 0)
+	if msg := press(); msg != nil {
+		t.Errorf("expected no run for an unsorted entry, got %#v", msg)
+	}
+}
+
+func TestSidebarShowRuns(t *testing.T) {
+	store := testStore(t)
+	_ = store.CreateFolder("Smoke")
+	e := Entry{ID: GenerateID(), Name: "GetUsers", Query: "{ users }", CreatedAt: time.Now()}
+	_ = store.AddEntry(e)
+
+	sb := NewSidebar(store)
+	sb.SetSize(40, 20)
+	press := func() tea.Msg {
+		_, cmd := sb.Update(tea.KeyPressMsg{Code: 's', Text: "s"})
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+
+	sb.SelectInSection(sectionRecent, 0)
+	if msg, ok := press().(ShowRunsMsg); !ok || msg.Entry.ID != e.ID {
+		t.Errorf("expected ShowRunsMsg for GetUsers, got %#v", msg)
+	}
+	sb.SelectInSection(sectionFolders, 0)
+	if msg := press(); msg != nil {
+		t.Errorf("expected no runs for a folder, got %#v", msg)
+	}
+}
+
 func TestSidebarView(t *testing.T) {
 	store := testStore(t)
 	sb := NewSidebar(store)
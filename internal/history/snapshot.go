@@ -0,0 +1,199 @@
+package history
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotsDir = "_snapshots"
+	snapshotExt  = ".json.gz"
+	maxSnapshots = 20 // runs kept per entry
+)
+
+// Snapshot describes the response of one run of an entry, saved gzipped
+// in <dir>/_snapshots/<entry id>/ so it can be shown again without
+// re-sending the request. The fields are kept in the gzip header: listing
+// the runs of an entry does not decompress the responses.
+type Snapshot struct {
+	EntryID  string        `json:"-"`
+	At       time.Time     `json:"at"`
+	Status   int           `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Size     int           `json:"size"` // bytes received
+
+	file string // set by Snapshots
+}
+
+// SaveSnapshot saves body, the response of a run of the entry
+// snap.EntryID. The entry keeps its last 20 runs, and the oldest snapshots
+// of all entries are dropped while together they take more than limit
+// bytes on disk. A limit of 0 saves nothing.
+func (s *Store) SaveSnapshot(snap Snapshot, body []byte, limit int64) error {
+	if limit <= 0 || snap.EntryID == "" {
+		return nil
+	}
+	meta, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Comment = string(meta)
+	zw.ModTime = snap.At
+	if _, err := zw.Write(body); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if int64(buf.Len()) > limit {
+		return nil
+	}
+
+	// Responses may hold tokens and personal data: keep them private.
+	dir := filepath.Join(s.dir, snapshotsDir, snap.EntryID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, snapshotFile(snap.At))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	s.pruneSnapshots(snap.EntryID, limit)
+	return nil
+}
+
+// Snapshots returns the saved runs of the entry, newest first.
+func (s *Store) Snapshots(entryID string) []Snapshot {
+	dir := filepath.Join(s.dir, snapshotsDir, entryID)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var snaps []Snapshot
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), snapshotExt) {
+			continue
+		}
+		snap, err := readSnapshotHeader(filepath.Join(dir, f.Name()))
+		if err != nil {
+			continue // skip corrupt
+		}
+		snap.EntryID = entryID
+		snap.file = f.Name()
+		snaps = append(snaps, snap)
+	}
+	slices.SortFunc(snaps, func(a, b Snapshot) int { return b.At.Compare(a.At) })
+	return snaps
+}
+
+// LoadSnapshot returns the response saved for snap.
+func (s *Store) LoadSnapshot(snap Snapshot) ([]byte, error) {
+	file := snap.file
+	if file == "" {
+		file = snapshotFile(snap.At)
+	}
+	f, err := os.Open(filepath.Join(s.dir, snapshotsDir, snap.EntryID, file))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(zr)
+}
+
+// deleteSnapshots removes the saved runs of the entry.
+func (s *Store) deleteSnapshots(entryID string) {
+	if entryID != "" {
+		_ = os.RemoveAll(filepath.Join(s.dir, snapshotsDir, entryID))
+	}
+}
+
+// pruneSnapshots drops the runs of the entry beyond maxSnapshots, then the
+// oldest runs of all entries while they take more than limit bytes.
+func (s *Store) pruneSnapshots(entryID string, limit int64) {
+	root := filepath.Join(s.dir, snapshotsDir)
+	type file struct {
+		path string
+		at   int64
+		size int64
+	}
+	var all []file
+	var total int64
+	dirs, _ := os.ReadDir(root)
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		var files []file
+		entries, _ := os.ReadDir(filepath.Join(root, d.Name()))
+		for _, e := range entries {
+			at, err := strconv.ParseInt(strings.TrimSuffix(e.Name(), snapshotExt), 10, 64)
+			info, ierr := e.Info()
+			if err != nil || ierr != nil || e.IsDir() {
+				continue
+			}
+			files = append(files, file{filepath.Join(root, d.Name(), e.Name()), at, info.Size()})
+		}
+		slices.SortFunc(files, func(a, b file) int { return cmp.Compare(b.at, a.at) })
+		if d.Name() == entryID && len(files) > maxSnapshots {
+			for _, f := range files[maxSnapshots:] {
+				_ = os.Remove(f.path)
+			}
+			files = files[:maxSnapshots]
+		}
+		for _, f := range files {
+			total += f.size
+		}
+		all = append(all, files...)
+	}
+
+	slices.SortFunc(all, func(a, b file) int { return cmp.Compare(a.at, b.at) })
+	for _, f := range all {
+		if total <= limit {
+			break
+		}
+		_ = os.Remove(f.path)
+		total -= f.size
+		// Leave no empty entry directories behind.
+		_ = os.Remove(filepath.Dir(f.path))
+	}
+}
+
+func snapshotFile(at time.Time) string {
+	return strconv.FormatInt(at.UnixNano(), 10) + snapshotExt
+}
+
+// readSnapshotHeader reads the fields of a snapshot from the gzip header of
+// the file at path.
+func readSnapshotHeader(path string) (Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var snap Snapshot
+	err = json.Unmarshal([]byte(zr.Comment), &snap)
+	return snap, err
+}
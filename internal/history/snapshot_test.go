@@ -0,0 +1,107 @@
+package history
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir)
+	_ = s.Load()
+	e := Entry{ID: GenerateID(), Name: "users", Query: "{ users }", CreatedAt: time.Now()}
+	if err := s.AddEntry(e); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		snap := Snapshot{EntryID: e.ID, At: start.Add(time.Duration(i) * time.Minute), Status: 200 + i, Duration: 35 * time.Millisecond, Size: 20}
+		body := fmt.Appendf(nil, `{"data":{"run":%d}}`, i)
+		if err := s.SaveSnapshot(snap, body, 1<<20); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snaps := s.Snapshots(e.ID)
+	if len(snaps) != 3 || snaps[0].Status != 202 || !snaps[0].At.Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("expected 3 runs newest first, got %+v", snaps)
+	}
+	if snaps[2].Duration != 35*time.Millisecond || snaps[2].Size != 20 {
+		t.Errorf("expected the run details kept, got %+v", snaps[2])
+	}
+	path := filepath.Join(dir, snapshotsDir, e.ID, snapshotFile(snaps[0].At))
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the snapshot mode 0600, got %v", info.Mode().Perm())
+	}
+	body, err := s.LoadSnapshot(snaps[1])
+	if err != nil || string(body) != `{"data":{"run":1}}` {
+		t.Errorf("unexpected body %q (%v)", body, err)
+	}
+
+	// The snapshots directory is not a folder.
+	_ = s.Load()
+	if len(s.Folders()) != 0 {
+		t.Errorf("expected no folders, got %v", s.Folders())
+	}
+
+	// A limit of 0 saves nothing.
+	if err := s.SaveSnapshot(Snapshot{EntryID: e.ID, At: time.Now()}, []byte("{}"), 0); err != nil || len(s.Snapshots(e.ID)) != 3 {
+		t.Errorf("expected nothing saved without a limit (%v)", err)
+	}
+
+	if err := s.DeleteEntry(e.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, snapshotsDir, e.ID)); !os.IsNotExist(err) {
+		t.Error("expected the snapshots deleted with the entry")
+	}
+}
+
+func TestSnapshotLimits(t *testing.T) {
+	s := NewStore(t.TempDir())
+	_ = s.Load()
+	start := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+
+	// An entry keeps its last maxSnapshots runs.
+	for i := range maxSnapshots + 2 {
+		_ = s.SaveSnapshot(Snapshot{EntryID: "a", At: start.Add(time.Duration(i) * time.Second)}, []byte("{}"), 1<<20)
+	}
+	snaps := s.Snapshots("a")
+	if len(snaps) != maxSnapshots || !snaps[len(snaps)-1].At.Equal(start.Add(2*time.Second)) {
+		t.Fatalf("expected the last %d runs kept, got %d from %v", maxSnapshots, len(snaps), snaps[len(snaps)-1].At)
+	}
+
+	// The oldest runs of any entry go when the total exceeds the limit.
+	body := []byte(`{"x":"` + GenerateID() + `"}`)
+	var size int64
+	_ = s.SaveSnapshot(Snapshot{EntryID: "b", At: start.Add(time.Hour)}, body, 1<<20)
+	_ = filepath.WalkDir(filepath.Join(s.dir, snapshotsDir), func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			info, _ := d.Info()
+			size += info.Size()
+		}
+		return nil
+	})
+	_ = s.SaveSnapshot(Snapshot{EntryID: "b", At: start.Add(2 * time.Hour)}, body, size)
+	if n := len(s.Snapshots("a")); n >= maxSnapshots {
+		t.Errorf("expected the oldest runs of a dropped, %d left", n)
+	}
+	if n := len(s.Snapshots("b")); n != 2 {
+		t.Errorf("expected the newest runs kept, got %d", n)
+	}
+
+	// A response larger than the limit is not saved.
+	_ = s.SaveSnapshot(Snapshot{EntryID: "c", At: start}, bytes.Repeat([]byte("x"), 100), 10)
+	if len(s.Snapshots("c")) != 0 {
+		t.Error("expected a response over the limit not saved")
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -14,19 +16,21 @@ import (
 
 // Entry represents a single saved query in history.
 type Entry struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Query     string    `json:"query"`
-	Variables string    `json:"variables"`
-	Endpoint  string    `json:"endpoint"`
-	EnvName   string    `json:"envName,omitempty"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Query     string            `json:"query"`
+	Variables string            `json:"variables"`
+	Endpoint  string            `json:"endpoint"`
+	EnvName   string            `json:"envName,omitempty"`
+	Dynamic   map[string]string `json:"dynamic,omitempty"` // {{$...}} values as sent
+	CreatedAt time.Time         `json:"createdAt"`
 }
 
 // Folder groups entries under a user-defined name.
 type Folder struct {
-	Name    string
-	Entries []Entry // sorted newest-first by CreatedAt
+	Name      string
+	Entries   []Entry // sorted newest-first by CreatedAt
+	Workspace bool    // a collection shared through the project workspace
 }
 
 // Meta stores sidebar UI state and folder ordering.
@@ -51,10 +55,11 @@ const (
 
 // Store manages on-disk history storage.
 type Store struct {
-	dir      string
-	Meta     Meta
-	folders  []Folder
-	unsorted []Entry
+	dir         string
+	collections string // workspace collections directory, if any
+	Meta        Meta
+	folders     []Folder
+	unsorted    []Entry
 }
 
 // NewStore creates a new Store rooted at dir.
@@ -62,6 +67,12 @@ func NewStore(dir string) *Store {
 	return &Store{dir: dir}
 }
 
+// SetCollections adds the folders in dir, a project workspace's shared
+// collections, to the next Load. Personal folders of the same name win.
+func (s *Store) SetCollections(dir string) {
+	s.collections = dir
+}
+
 // Load reads the directory structure: scan subdirs for .json files,
 // read _meta.json, populate folders + unsorted. Skips corrupt files.
 func (s *Store) Load() error {
@@ -91,7 +102,7 @@ func (s *Store) Load() error {
 	var dirNames []string
 
 	for _, e := range entries {
-		if !e.IsDir() {
+		if !e.IsDir() || e.Name() == snapshotsDir {
 			continue
 		}
 		name := e.Name()
@@ -121,9 +132,52 @@ func (s *Store) Load() error {
 		}
 	}
 
+	s.loadCollections()
 	return nil
 }
 
+// loadCollections appends the workspace collections, in meta order where
+// known, then by name.
+func (s *Store) loadCollections() {
+	if s.collections == "" {
+		return
+	}
+	dirs, err := os.ReadDir(s.collections)
+	if err != nil {
+		return
+	}
+	var shared []Folder
+	for _, d := range dirs {
+		if !d.IsDir() || d.Name() == unsortedDir || s.hasFolder(d.Name()) {
+			continue
+		}
+		entries := s.loadEntries(filepath.Join(s.collections, d.Name()))
+		sortEntriesNewestFirst(entries)
+		shared = append(shared, Folder{Name: d.Name(), Entries: entries, Workspace: true})
+	}
+	order := func(name string) int {
+		if i := slices.Index(s.Meta.FolderOrder, name); i >= 0 {
+			return i
+		}
+		return len(s.Meta.FolderOrder)
+	}
+	slices.SortStableFunc(shared, func(a, b Folder) int { return order(a.Name) - order(b.Name) })
+	s.folders = append(s.folders, shared...)
+}
+
+func (s *Store) hasFolder(name string) bool {
+	return slices.ContainsFunc(s.folders, func(f Folder) bool { return f.Name == name })
+}
+
+// folderDir returns the directory of folder, which is in the workspace for
+// shared collections.
+func (s *Store) folderDir(folder string) string {
+	if s.IsShared(folder) {
+		return filepath.Join(s.collections, folder)
+	}
+	return filepath.Join(s.dir, folder)
+}
+
 func (s *Store) loadEntries(dir string) []Entry {
 	files, err := os.ReadDir(dir)
 	if err != nil {
@@ -171,7 +225,7 @@ func (s *Store) Save() error {
 
 // SaveEntry writes an entry JSON to <dir>/<folder>/<id>.json atomically.
 func (s *Store) SaveEntry(e Entry, folder string) error {
-	dir := filepath.Join(s.dir, folder)
+	dir := s.folderDir(folder)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
 	}
@@ -200,14 +254,17 @@ func (s *Store) AddEntry(e Entry) error {
 		sortEntriesNewestFirst(s.unsorted)
 		for _, old := range s.unsorted[maxEntries:] {
 			_ = os.Remove(filepath.Join(s.dir, unsortedDir, old.ID+".json"))
+			s.deleteSnapshots(old.ID)
 		}
 		s.unsorted = s.unsorted[:maxEntries]
 	}
 	return nil
 }
 
-// DeleteEntry finds an entry by ID across all folders and removes its file.
+// DeleteEntry finds an entry by ID across all folders and removes its file
+// and response snapshots.
 func (s *Store) DeleteEntry(id string) error {
+	s.deleteSnapshots(id)
 	// Check unsorted
 	for i, e := range s.unsorted {
 		if e.ID == id {
@@ -220,7 +277,7 @@ func (s *Store) DeleteEntry(id string) error {
 		for ei, e := range s.folders[fi].Entries {
 			if e.ID == id {
 				s.folders[fi].Entries = append(s.folders[fi].Entries[:ei], s.folders[fi].Entries[ei+1:]...)
-				return os.Remove(filepath.Join(s.dir, s.folders[fi].Name, id+".json"))
+				return os.Remove(filepath.Join(s.folderDir(s.folders[fi].Name), id+".json"))
 			}
 		}
 	}
@@ -264,7 +321,7 @@ func (s *Store) MoveEntry(id, toFolder string) error {
 	}
 
 	// Remove old file
-	_ = os.Remove(filepath.Join(s.dir, fromDir, id+".json"))
+	_ = os.Remove(filepath.Join(s.folderDir(fromDir), id+".json"))
 
 	// Add to target
 	if toFolder == unsortedDir {
@@ -291,8 +348,20 @@ func (s *Store) CreateFolder(name string) error {
 	return s.Save()
 }
 
+// ErrSharedFolder is returned when deleting or renaming a workspace
+// collection, which belongs to the project repository.
+var ErrSharedFolder = errors.New("shared collections are managed in the workspace")
+
+// IsShared reports whether folder is a workspace collection.
+func (s *Store) IsShared(folder string) bool {
+	return slices.ContainsFunc(s.folders, func(f Folder) bool { return f.Name == folder && f.Workspace })
+}
+
 // DeleteFolder removes a folder. Entries are moved to unsorted first.
 func (s *Store) DeleteFolder(name string) error {
+	if s.IsShared(name) {
+		return ErrSharedFolder
+	}
 	for fi := range s.folders {
 		if s.folders[fi].Name == name {
 			// Move entries to unsorted
@@ -328,6 +397,9 @@ func (s *Store) DeleteFolder(name string) error {
 
 // RenameFolder renames a folder directory and updates meta.
 func (s *Store) RenameFolder(old, newName string) error {
+	if s.IsShared(old) {
+		return ErrSharedFolder
+	}
 	if err := os.Rename(filepath.Join(s.dir, old), filepath.Join(s.dir, newName)); err != nil {
 		return err
 	}
@@ -408,13 +480,18 @@ func (s *Store) HasContent() bool {
 
 // IsDuplicate checks if the most recent entry matches the given query/variables/endpoint.
 func (s *Store) IsDuplicate(query, variables, endpoint string) bool {
+	newest, ok := s.Newest()
+	return ok && newest.Query == query && newest.Variables == variables && newest.Endpoint == endpoint
+}
+
+// Newest returns the most recent entry across folders and unsorted.
+func (s *Store) Newest() (Entry, bool) {
 	all := s.AllEntries()
 	if len(all) == 0 {
-		return false
+		return Entry{}, false
 	}
 	sortEntriesNewestFirst(all)
-	newest := all[0]
-	return newest.Query == query && newest.Variables == variables && newest.Endpoint == endpoint
+	return all[0], true
 }
 
 // SetCollapsed updates the collapsed state for a folder.
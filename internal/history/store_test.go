@@ -431,3 +431,41 @@ func TestMetaAtomicWrite(t *testing.T) {
 		t.Errorf("meta file is not valid JSON: %v", err)
 	}
 }
+
+func TestWorkspaceCollections(t *testing.T) {
+	dir := t.TempDir()
+	shared := t.TempDir()
+	e := Entry{ID: GenerateID(), Name: "login", Query: "mutation Login { login }", CreatedAt: time.Now()}
+	data, _ := json.Marshal(e)
+	_ = os.MkdirAll(filepath.Join(shared, "auth"), 0o755)
+	_ = os.WriteFile(filepath.Join(shared, "auth", e.ID+".json"), data, 0o644)
+
+	s := NewStore(dir)
+	s.SetCollections(shared)
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	folders := s.Folders()
+	if len(folders) != 1 || folders[0].Name != "auth" || !folders[0].Workspace || len(folders[0].Entries) != 1 {
+		t.Fatalf("folders = %+v", folders)
+	}
+
+	// Entries saved to a collection land in the workspace, not the personal dir
+	e2 := Entry{ID: GenerateID(), Name: "me", Query: "{ me }", CreatedAt: time.Now()}
+	if err := s.SaveEntry(e2, "auth"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(shared, "auth", e2.ID+".json")); err != nil {
+		t.Errorf("entry not saved to the workspace: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "auth")); !os.IsNotExist(err) {
+		t.Error("collection should not be copied to the personal dir")
+	}
+
+	if err := s.DeleteFolder("auth"); err != ErrSharedFolder {
+		t.Errorf("DeleteFolder = %v, want ErrSharedFolder", err)
+	}
+	if err := s.RenameFolder("auth", "other"); err != ErrSharedFolder {
+		t.Errorf("RenameFolder = %v, want ErrSharedFolder", err)
+	}
+}
@@ -0,0 +1,40 @@
+package inspector
+
+import "strings"
+
+// sensitiveHeaders are masked by exact (case-insensitive) name.
+var sensitiveHeaders = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+}
+
+// sensitiveParts mask any header whose name contains them, e.g. X-Api-Key
+// or X-Auth-Token.
+var sensitiveParts = []string{"token", "secret", "password", "api-key", "apikey", "session"}
+
+// sensitive reports whether the value of header name should be masked.
+func sensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, h := range sensitiveHeaders {
+		if name == h {
+			return true
+		}
+	}
+	for _, p := range sensitiveParts {
+		if strings.Contains(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// mask hides a header value, keeping an auth scheme such as "Bearer" so the
+// kind of credential stays visible.
+func mask(value string) string {
+	if scheme, cred, ok := strings.Cut(value, " "); ok && cred != "" && !strings.ContainsAny(scheme, "=;") {
+		return scheme + " ••••••••"
+	}
+	return "••••••••"
+}
@@ -0,0 +1,178 @@
+package inspector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/highlight"
+)
+
+// CloseMsg is returned to the parent app when the inspector is left.
+type CloseMsg struct{}
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	sectionStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("196"))
+	lineStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("252"))
+	nameStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	valueStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	maskedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	dimStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+)
+
+// Model shows the timing and the raw HTTP request and response of the last
+// query. Sensitive header values are masked until revealed with r.
+type Model struct {
+	vp       viewport.Model
+	exchange *graphql.Exchange
+	timing   graphql.Timing
+	duration time.Duration
+	reveal   bool
+	width    int
+	height   int
+}
+
+func New() Model {
+	vp := viewport.New()
+	vp.SoftWrap = true
+	return Model{vp: vp}
+}
+
+// SetResult shows the exchange and timing of r and masks secrets again.
+func (m *Model) SetResult(r *graphql.Result) {
+	m.exchange = r.Exchange
+	m.timing = r.Timing
+	m.duration = r.Duration
+	m.reveal = false
+	m.render()
+	m.vp.GotoTop()
+}
+
+// HasExchange reports whether there is an exchange to show.
+func (m Model) HasExchange() bool { return m.exchange != nil }
+
+func (m *Model) SetSize(w, h int) {
+	m.width = w
+	m.height = h
+	m.vp.SetWidth(w)
+	m.vp.SetHeight(h - 1) // title line
+	m.render()
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if kmsg, ok := msg.(tea.KeyPressMsg); ok {
+		switch kmsg.String() {
+		case "r":
+			m.reveal = !m.reveal
+			m.render()
+			return m, nil
+		case "esc", "i":
+			return m, func() tea.Msg { return CloseMsg{} }
+		}
+	}
+	var cmd tea.Cmd
+	m.vp, cmd = m.vp.Update(msg)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	title := titleStyle.Render(" Inspector ")
+	if m.exchange == nil {
+		return title + "\n" + dimStyle.Render("  (no request sent yet)")
+	}
+	if m.reveal {
+		title += dimStyle.Render(" secrets shown")
+	}
+	return title + "\n" + m.vp.View()
+}
+
+func (m *Model) render() {
+	if m.exchange == nil {
+		m.vp.SetContent("")
+		return
+	}
+	ex := m.exchange
+	var b strings.Builder
+
+	b.WriteString(sectionStyle.Render("── Timing ──") + "\n")
+	for _, line := range waterfall(m.timing, m.duration, m.width) {
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n" + sectionStyle.Render("── Request ──") + "\n")
+	b.WriteString(lineStyle.Render(ex.Method+" "+ex.URL) + "\n")
+	if u, err := url.Parse(ex.URL); err == nil && ex.RequestHeaders.Get("Host") == "" {
+		b.WriteString(m.renderHeader("Host", u.Host) + "\n")
+	}
+	m.writeHeaders(&b, ex.RequestHeaders)
+	if len(ex.RequestBody) > 0 {
+		b.WriteString("\n" + renderBody(ex.RequestBody))
+		if more := ex.RequestSize - int64(len(ex.RequestBody)); more > 0 {
+			b.WriteString("\n" + dimStyle.Render(fmt.Sprintf("… %d more bytes", more)))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n" + sectionStyle.Render("── Response ──") + "\n")
+	b.WriteString(lineStyle.Render(ex.Proto+" "+ex.Status) + "\n")
+	m.writeHeaders(&b, ex.ResponseHeaders)
+	if len(ex.ResponseBody) > 0 {
+		b.WriteString("\n" + renderBody(ex.ResponseBody))
+	}
+
+	m.vp.SetContent(b.String())
+}
+
+// writeHeaders writes headers sorted by name, one line per value.
+func (m Model) writeHeaders(b *strings.Builder, h map[string][]string) {
+	names := make([]string, 0, len(h))
+	for k := range h {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	for _, k := range names {
+		for _, v := range h[k] {
+			b.WriteString(m.renderHeader(k, v) + "\n")
+		}
+	}
+}
+
+func (m Model) renderHeader(name, value string) string {
+	if !m.reveal && sensitive(name) {
+		return nameStyle.Render(name+":") + " " + maskedStyle.Render(mask(value))
+	}
+	return nameStyle.Render(name+":") + " " + valueStyle.Render(value)
+}
+
+// renderBody pretty-prints JSON bodies and shows anything else as text with
+// control bytes replaced, so multipart uploads cannot garble the terminal.
+func renderBody(body []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, body, "", "  "); err == nil {
+		return highlight.Colorize(buf.String(), "json")
+	}
+	return printable(body)
+}
+
+func printable(b []byte) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n', r == '\t':
+			return r
+		case r == '\r':
+			return -1
+		case r < 0x20, r == 0x7f, r == '�':
+			return '·'
+		}
+		return r
+	}, strings.ToValidUTF8(string(b), "�"))
+}
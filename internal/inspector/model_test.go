@@ -0,0 +1,171 @@
+package inspector
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/highlight"
+)
+
+func keyMsg(k string) tea.KeyPressMsg {
+	return tea.KeyPressMsg{Code: -1, Text: k}
+}
+
+func testExchange() *graphql.Exchange {
+	return &graphql.Exchange{
+		Method: "POST",
+		URL:    "https://api.example.com/graphql",
+		RequestHeaders: http.Header{
+			"Authorization": {"Bearer s3cr3t"},
+			"X-Api-Key":     {"k3y"},
+			"Content-Type":  {"application/json"},
+		},
+		RequestBody:     []byte(`{"query":"{ me }"}`),
+		RequestSize:     18,
+		Proto:           "HTTP/1.1",
+		Status:          "200 OK",
+		ResponseHeaders: http.Header{"X-Cache": {"HIT"}},
+		ResponseBody:    []byte(`{"data":{"me":null}}`),
+	}
+}
+
+func view(m Model) string {
+	return highlight.StripANSI(m.View())
+}
+
+func TestViewShowsExchange(t *testing.T) {
+	m := New()
+	m.SetSize(80, 40)
+	m.SetResult(&graphql.Result{Exchange: testExchange()})
+
+	v := view(m)
+	for _, want := range []string{
+		"POST https://api.example.com/graphql",
+		"Host: api.example.com",
+		"Content-Type: application/json",
+		`"query": "{ me }"`,
+		"HTTP/1.1 200 OK",
+		"X-Cache: HIT",
+		`"me": null`,
+	} {
+		if !strings.Contains(v, want) {
+			t.Errorf("expected %q in view:\n%s", want, v)
+		}
+	}
+}
+
+func TestSensitiveHeadersMaskedUntilRevealed(t *testing.T) {
+	m := New()
+	m.SetSize(80, 40)
+	m.SetResult(&graphql.Result{Exchange: testExchange()})
+
+	v := view(m)
+	if strings.Contains(v, "s3cr3t") || strings.Contains(v, "k3y") {
+		t.Fatalf("expected secrets masked:\n%s", v)
+	}
+	if !strings.Contains(v, "Authorization: Bearer ••••••••") {
+		t.Errorf("expected auth scheme kept:\n%s", v)
+	}
+
+	m, _ = m.Update(keyMsg("r"))
+	v = view(m)
+	if !strings.Contains(v, "Bearer s3cr3t") || !strings.Contains(v, "X-Api-Key: k3y") {
+		t.Errorf("expected secrets revealed:\n%s", v)
+	}
+
+	// A new exchange is masked again.
+	m.SetResult(&graphql.Result{Exchange: testExchange()})
+	if strings.Contains(view(m), "s3cr3t") {
+		t.Error("expected secrets masked after new exchange")
+	}
+}
+
+func TestTruncatedRequestBody(t *testing.T) {
+	ex := testExchange()
+	ex.RequestHeaders = http.Header{"Content-Type": {"multipart/form-data"}}
+	ex.RequestBody = []byte("--b\r\nContent-Disposition: form-data; name=\"0\"\r\n\r\n\x89PNG\x00")
+	ex.RequestSize = int64(len(ex.RequestBody)) + 1000
+
+	m := New()
+	m.SetSize(80, 40)
+	m.SetResult(&graphql.Result{Exchange: ex})
+	v := view(m)
+	if strings.ContainsAny(v, "\x00\r") {
+		t.Error("expected control bytes replaced")
+	}
+	if !strings.Contains(v, "… 1000 more bytes") {
+		t.Errorf("expected truncation note:\n%s", v)
+	}
+}
+
+func TestTimingWaterfall(t *testing.T) {
+	m := New()
+	m.SetSize(60, 40)
+	m.SetResult(&graphql.Result{
+		Duration: 100 * time.Millisecond,
+		Timing: graphql.Timing{
+			Connect:  10 * time.Millisecond,
+			TLS:      20 * time.Millisecond,
+			Wait:     50 * time.Millisecond,
+			Download: 15 * time.Millisecond,
+			TTFB:     85 * time.Millisecond,
+		},
+		Exchange: testExchange(),
+	})
+
+	lines := strings.Split(view(m), "\n")
+	bars := map[string]string{}
+	for _, l := range lines {
+		for _, name := range []string{"DNS", "Connect", "TLS", "Send", "Wait", "Download", "Total"} {
+			if strings.HasPrefix(l, name+" ") {
+				bars[name] = l
+			}
+		}
+	}
+	if _, ok := bars["DNS"]; ok {
+		t.Error("expected phases that did not happen to be left out")
+	}
+	if !strings.HasSuffix(bars["Send"], "5.0ms") || !strings.HasSuffix(bars["Total"], "100ms") {
+		t.Errorf("unexpected durations: %q, %q", bars["Send"], bars["Total"])
+	}
+	// Each phase starts where the previous one ended.
+	prev := -1
+	for _, name := range []string{"Connect", "TLS", "Send", "Wait", "Download"} {
+		start := strings.Index(bars[name], "█")
+		if start <= prev {
+			t.Errorf("expected %s to start after the previous phase: %q", name, bars[name])
+		}
+		prev = start
+	}
+}
+
+func TestEscEmitsClose(t *testing.T) {
+	m := New()
+	_, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if cmd == nil {
+		t.Fatal("expected command")
+	}
+	if _, ok := cmd().(CloseMsg); !ok {
+		t.Error("expected CloseMsg")
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Authorization": true,
+		"cookie":        true,
+		"X-Auth-Token":  true,
+		"X-API-Key":     true,
+		"Content-Type":  false,
+		"X-Request-Id":  false,
+		"Accept":        false,
+	} {
+		if got := sensitive(name); got != want {
+			t.Errorf("sensitive(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
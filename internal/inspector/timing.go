@@ -0,0 +1,76 @@
+package inspector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/qraqula/qla/internal/graphql"
+)
+
+var barStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+
+type phase struct {
+	name string
+	d    time.Duration
+}
+
+// phases returns the request phases in order. Send covers whatever the
+// trace did not attribute before the first byte: writing the request and,
+// on a pooled connection, waiting for it.
+func phases(t graphql.Timing) []phase {
+	send := t.TTFB - t.DNS - t.Connect - t.TLS - t.Wait
+	return []phase{
+		{"DNS", t.DNS},
+		{"Connect", t.Connect},
+		{"TLS", t.TLS},
+		{"Send", max(send, 0)},
+		{"Wait", t.Wait},
+		{"Download", t.Download},
+	}
+}
+
+// waterfall renders each phase as a bar offset by the phases before it,
+// scaled so that total spans the available width.
+func waterfall(t graphql.Timing, total time.Duration, width int) []string {
+	const labelW, durW = 9, 9
+	barW := max(width-labelW-durW-2, 10)
+
+	var lines []string
+	var offset time.Duration
+	for _, p := range phases(t) {
+		if p.d <= 0 {
+			continue
+		}
+		start := scale(offset, total, barW)
+		n := max(scale(offset+p.d, total, barW)-start, 1)
+		start = min(start, barW-n)
+		bar := strings.Repeat(" ", start) + barStyle.Render(strings.Repeat("█", n)) + strings.Repeat(" ", barW-start-n)
+		lines = append(lines, fmt.Sprintf("%-*s %s %*s", labelW, p.name, bar, durW, formatDuration(p.d)))
+		offset += p.d
+	}
+	lines = append(lines, fmt.Sprintf("%-*s %s %*s", labelW, "Total", strings.Repeat(" ", barW), durW, formatDuration(total)))
+	if t.Reused {
+		lines = append(lines, dimStyle.Render("reused connection"))
+	}
+	return lines
+}
+
+func scale(d, total time.Duration, w int) int {
+	if total <= 0 {
+		return 0
+	}
+	return min(int(int64(d)*int64(w)/int64(total)), w)
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= 10*time.Millisecond:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	default:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+}
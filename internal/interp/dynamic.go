@@ -0,0 +1,138 @@
+package interp
+
+import (
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dynamic resolves the built-in {{$...}} tokens:
+//
+//	{{$uuid}}                random UUID v4
+//	{{$timestamp}}           Unix time in seconds
+//	{{$isoTimestamp}}        UTC time in RFC 3339
+//	{{$randomInt}}           integer in [0, 1000]; {{$randomInt 1 6}} in [1, 6]
+//	{{$randomString}}        16 letters and digits; {{$randomString 8}} for 8
+//
+// Each distinct token is evaluated once, so a key used in both a header and
+// a variable carries the same value. Use one Dynamic per request.
+type Dynamic struct {
+	now    func() time.Time
+	values map[string]string
+}
+
+func NewDynamic() *Dynamic {
+	return &Dynamic{now: time.Now, values: make(map[string]string)}
+}
+
+// Lookup resolves a built-in token. Names not starting with $ are unknown.
+func (d *Dynamic) Lookup(name string) (string, bool) {
+	fields := strings.Fields(name)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "$") {
+		return "", false
+	}
+	key := strings.Join(fields, " ")
+	if v, ok := d.values[key]; ok {
+		return v, true
+	}
+	v, err := d.eval(fields[0], fields[1:])
+	if err != nil {
+		return "", false
+	}
+	d.values[key] = v
+	return v, true
+}
+
+// Values returns the tokens evaluated so far and their values.
+func (d *Dynamic) Values() map[string]string {
+	return d.values
+}
+
+// Seed sets the values of tokens drawn for an earlier request, as returned
+// by Values, so that they are replayed rather than drawn again.
+func (d *Dynamic) Seed(values map[string]string) {
+	maps.Copy(d.values, values)
+}
+
+// FormatValues lists values, as returned by Values, for display:
+// {{$uuid}}=…, {{$timestamp}}=… in key order.
+func FormatValues(values map[string]string) string {
+	parts := make([]string, 0, len(values))
+	for _, k := range slices.Sorted(maps.Keys(values)) {
+		parts = append(parts, "{{"+k+"}}="+values[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (d *Dynamic) eval(fn string, args []string) (string, error) {
+	switch fn {
+	case "$uuid":
+		return uuid(), nil
+	case "$timestamp":
+		return strconv.FormatInt(d.now().Unix(), 10), nil
+	case "$isoTimestamp":
+		return d.now().UTC().Format(time.RFC3339), nil
+	case "$randomInt":
+		lo, hi := int64(0), int64(1000)
+		if len(args) > 0 {
+			if len(args) != 2 {
+				return "", fmt.Errorf("$randomInt takes a min and a max")
+			}
+			var err1, err2 error
+			lo, err1 = strconv.ParseInt(args[0], 10, 64)
+			hi, err2 = strconv.ParseInt(args[1], 10, 64)
+			if err1 != nil || err2 != nil || hi < lo {
+				return "", fmt.Errorf("$randomInt: invalid range")
+			}
+		}
+		// The span of a wide range does not fit in an int64.
+		span := new(big.Int).Sub(big.NewInt(hi), big.NewInt(lo))
+		n, err := rand.Int(rand.Reader, span.Add(span, big.NewInt(1)))
+		if err != nil {
+			return "", err
+		}
+		return n.Add(n, big.NewInt(lo)).String(), nil
+	case "$randomString":
+		length := 16
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 || len(args) > 1 {
+				return "", fmt.Errorf("$randomString: invalid length")
+			}
+			if n > maxRandomString {
+				return "", fmt.Errorf("$randomString: length over %d", maxRandomString)
+			}
+			length = n
+		}
+		return randomString(length), nil
+	}
+	return "", fmt.Errorf("unknown token %s", fn)
+}
+
+func uuid() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// maxRandomString bounds the length of {{$randomString n}}.
+const maxRandomString = 4096
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	limit := big.NewInt(int64(len(alphanumeric)))
+	for i := range b {
+		j, _ := rand.Int(rand.Reader, limit)
+		b[i] = alphanumeric[j.Int64()]
+	}
+	return string(b)
+}
@@ -0,0 +1,76 @@
+package interp
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDynamic(t *testing.T) {
+	d := NewDynamic()
+	d.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	uuidRe := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if v, ok := d.Lookup("$uuid"); !ok || !uuidRe.MatchString(v) {
+		t.Errorf("unexpected uuid %q", v)
+	}
+	if v, _ := d.Lookup("$timestamp"); v != "1767323045" {
+		t.Errorf("unexpected timestamp %q", v)
+	}
+	if v, _ := d.Lookup("$isoTimestamp"); v != "2026-01-02T03:04:05Z" {
+		t.Errorf("unexpected iso timestamp %q", v)
+	}
+	for range 20 {
+		d := NewDynamic()
+		v, ok := d.Lookup("$randomInt 5 7")
+		n, _ := strconv.Atoi(v)
+		if !ok || n < 5 || n > 7 {
+			t.Fatalf("randomInt out of range: %q", v)
+		}
+	}
+	for _, wide := range []string{"$randomInt 0 9223372036854775807", "$randomInt -9223372036854775808 9223372036854775807"} {
+		if v, ok := d.Lookup(wide); !ok {
+			t.Errorf("expected %q resolved, got %q", wide, v)
+		} else if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			t.Errorf("randomInt out of range: %q", v)
+		}
+	}
+	if v, _ := d.Lookup("$randomString 8"); !regexp.MustCompile(`^[A-Za-z0-9]{8}$`).MatchString(v) {
+		t.Errorf("unexpected random string %q", v)
+	}
+
+	for _, bad := range []string{"$nope", "$randomInt 9 1", "$randomString x", "$randomString 2000000000", "uuid"} {
+		if _, ok := d.Lookup(bad); ok {
+			t.Errorf("expected %q to be unresolved", bad)
+		}
+	}
+}
+
+func TestDynamicEvaluatedOnce(t *testing.T) {
+	d := NewDynamic()
+	lookup := Chain(Values(nil), d.Lookup)
+	out, _ := Expand("{{$uuid}} {{ $uuid }}", lookup)
+	first, _ := d.Lookup("$uuid")
+	if out != first+" "+first {
+		t.Errorf("expected the same uuid twice, got %q", out)
+	}
+	if len(d.Values()) != 1 || d.Values()["$uuid"] != first {
+		t.Errorf("expected recorded value, got %v", d.Values())
+	}
+	if other, _ := NewDynamic().Lookup("$uuid"); other == first {
+		t.Error("expected a new value per Dynamic")
+	}
+}
+
+func TestDynamicSeed(t *testing.T) {
+	d := NewDynamic()
+	d.Seed(map[string]string{"$uuid": "abc", "$randomInt 1 6": "4"})
+	out, _ := Expand("{{$uuid}} {{$randomInt 1 6}}", d.Lookup)
+	if out != "abc 4" {
+		t.Errorf("expected the seeded values replayed, got %q", out)
+	}
+	if got := FormatValues(d.Values()); got != "{{$randomInt 1 6}}=4, {{$uuid}}=abc" {
+		t.Errorf("got %q", got)
+	}
+}
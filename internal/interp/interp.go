@@ -0,0 +1,113 @@
+// Package interp resolves {{name}} placeholders in endpoints, header values
+// and query variables.
+package interp
+
+import (
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var placeholder = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// Lookup resolves the trimmed text between the braces of a placeholder. ok is
+// false when the name is unknown.
+type Lookup func(name string) (value string, ok bool)
+
+// Expand replaces every placeholder in s. Unknown placeholders are left in
+// place and their names returned, each once, in order of appearance.
+func Expand(s string, lookup Lookup) (string, []string) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	var unresolved []string
+	out := placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := placeholder.FindStringSubmatch(m)[1]
+		if v, ok := lookup(name); ok {
+			return v
+		}
+		if !slices.Contains(unresolved, name) {
+			unresolved = append(unresolved, name)
+		}
+		return m
+	})
+	return out, unresolved
+}
+
+// ExpandValue expands placeholders in every string inside a decoded JSON
+// value, leaving v itself unmodified.
+func ExpandValue(v any, lookup Lookup) (any, []string) {
+	var unresolved []string
+	var walk func(v any) any
+	walk = func(v any) any {
+		switch val := v.(type) {
+		case string:
+			s, names := Expand(val, lookup)
+			for _, n := range names {
+				if !slices.Contains(unresolved, n) {
+					unresolved = append(unresolved, n)
+				}
+			}
+			return s
+		case map[string]any:
+			out := make(map[string]any, len(val))
+			for k, item := range val {
+				out[k] = walk(item)
+			}
+			return out
+		case []any:
+			out := make([]any, len(val))
+			for i, item := range val {
+				out[i] = walk(item)
+			}
+			return out
+		}
+		return v
+	}
+	return walk(v), unresolved
+}
+
+// Values looks names up in values, and env.NAME in the process environment.
+func Values(values map[string]string) Lookup {
+	return func(name string) (string, bool) {
+		if v, ok := values[name]; ok {
+			return v, true
+		}
+		if key, ok := strings.CutPrefix(name, "env."); ok {
+			return os.LookupEnv(key)
+		}
+		return "", false
+	}
+}
+
+// Sole reports whether s consists of exactly one placeholder, returning its
+// name.
+func Sole(s string) (string, bool) {
+	m := placeholder.FindStringSubmatchIndex(s)
+	if m == nil || m[0] != 0 || m[1] != len(s) {
+		return "", false
+	}
+	return s[m[2]:m[3]], true
+}
+
+// Chain tries each lookup in turn.
+func Chain(lookups ...Lookup) Lookup {
+	return func(name string) (string, bool) {
+		for _, l := range lookups {
+			if v, ok := l(name); ok {
+				return v, true
+			}
+		}
+		return "", false
+	}
+}
+
+// Format renders names as placeholders for messages: {{a}}, {{b}}.
+func Format(names []string) string {
+	wrapped := make([]string, len(names))
+	for i, n := range names {
+		wrapped[i] = "{{" + n + "}}"
+	}
+	return strings.Join(wrapped, ", ")
+}
@@ -0,0 +1,70 @@
+package interp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("QLA_TEST_TOKEN", "s3cr3t")
+	lookup := Values(map[string]string{"host": "api.dev", "tenant": "t-1"})
+
+	tests := []struct {
+		in, want   string
+		unresolved []string
+	}{
+		{"https://{{host}}/graphql", "https://api.dev/graphql", nil},
+		{"Bearer {{ env.QLA_TEST_TOKEN }}", "Bearer s3cr3t", nil},
+		{"{{tenant}}-{{tenant}}", "t-1-t-1", nil},
+		{"{{missing}} {{env.QLA_TEST_UNSET}} {{missing}}", "{{missing}} {{env.QLA_TEST_UNSET}} {{missing}}", []string{"missing", "env.QLA_TEST_UNSET"}},
+		{"no placeholders { here }", "no placeholders { here }", nil},
+	}
+	for _, tt := range tests {
+		got, unresolved := Expand(tt.in, lookup)
+		if got != tt.want || !reflect.DeepEqual(unresolved, tt.unresolved) {
+			t.Errorf("Expand(%q) = %q, %v; want %q, %v", tt.in, got, unresolved, tt.want, tt.unresolved)
+		}
+	}
+}
+
+func TestExpandValue(t *testing.T) {
+	lookup := Values(map[string]string{"tenant": "t-1"})
+	in := map[string]any{
+		"input": map[string]any{"tenant": "{{tenant}}", "tags": []any{"a", "{{tag}}"}},
+		"count": 3.0,
+	}
+	got, unresolved := ExpandValue(in, lookup)
+	want := map[string]any{
+		"input": map[string]any{"tenant": "t-1", "tags": []any{"a", "{{tag}}"}},
+		"count": 3.0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected result %v", got)
+	}
+	if !reflect.DeepEqual(unresolved, []string{"tag"}) {
+		t.Errorf("expected tag unresolved, got %v", unresolved)
+	}
+	if in["input"].(map[string]any)["tenant"] != "{{tenant}}" {
+		t.Error("input was modified")
+	}
+}
+
+func TestSole(t *testing.T) {
+	tests := map[string]string{"{{$randomInt 1 6}}": "$randomInt 1 6", "{{ count }}": "count"}
+	for in, want := range tests {
+		if got, ok := Sole(in); !ok || got != want {
+			t.Errorf("Sole(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{"n{{count}}", "{{a}}{{b}}", "{{a}} ", "plain"} {
+		if _, ok := Sole(in); ok {
+			t.Errorf("expected %q not to be a lone placeholder", in)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := Format([]string{"a", "env.B"}); got != "{{a}}, {{env.B}}" {
+		t.Errorf("unexpected %q", got)
+	}
+}
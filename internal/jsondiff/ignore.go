@@ -0,0 +1,98 @@
+package jsondiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Ignored reports whether path is under one of patterns. A pattern is a
+// path in the form FormatPath writes, where * stands for any key and [*]
+// for any index: data.items[*].updatedAt. It also covers what is below the
+// path it matches. A pattern of a single key, such as updatedAt, matches
+// that key at any depth.
+func Ignored(path []any, patterns []string) bool {
+	segs := segments(path)
+	for _, p := range patterns {
+		pat := splitPattern(p)
+		switch {
+		case len(pat) == 0:
+		case len(pat) == 1 && !isIndex(pat[0]):
+			for _, s := range segs {
+				if matchSegment(pat[0], s) {
+					return true
+				}
+			}
+		case len(pat) <= len(segs):
+			matched := true
+			for i, ps := range pat {
+				if !matchSegment(ps, segs[i]) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Filter returns the changes whose path is not Ignored by patterns and how
+// many were left out.
+func Filter(changes []Change, patterns []string) ([]Change, int) {
+	if len(patterns) == 0 {
+		return changes, 0
+	}
+	var kept []Change
+	for _, c := range changes {
+		if !Ignored(c.Path, patterns) {
+			kept = append(kept, c)
+		}
+	}
+	return kept, len(changes) - len(kept)
+}
+
+// segments returns path with indexes written as [2].
+func segments(path []any) []string {
+	segs := make([]string, len(path))
+	for i, p := range path {
+		switch p := p.(type) {
+		case int:
+			segs[i] = "[" + strconv.Itoa(p) + "]"
+		default:
+			segs[i] = p.(string)
+		}
+	}
+	return segs
+}
+
+// splitPattern splits data.items[*].id into data, items, [*] and id.
+func splitPattern(p string) []string {
+	var segs []string
+	for _, part := range strings.Split(strings.TrimSpace(p), ".") {
+		for part != "" {
+			i := strings.IndexByte(part[1:], '[') + 1
+			if i == 0 {
+				i = len(part)
+			}
+			segs = append(segs, part[:i])
+			part = part[i:]
+		}
+	}
+	return segs
+}
+
+func isIndex(seg string) bool {
+	return strings.HasPrefix(seg, "[")
+}
+
+func matchSegment(pattern, seg string) bool {
+	switch pattern {
+	case "*":
+		return !isIndex(seg)
+	case "[*]":
+		return isIndex(seg)
+	}
+	return pattern == seg
+}
@@ -0,0 +1,119 @@
+// Package jsondiff compares two values decoded by encoding/json and lists
+// what differs, key by key and index by index.
+package jsondiff
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Kind is how a value differs.
+type Kind int
+
+const (
+	Added   Kind = iota // only in the new value
+	Removed             // only in the old value
+	Changed             // in both, with different values
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	}
+	return "changed"
+}
+
+// Change is a difference at Path, a list of object keys (strings) and
+// array indexes (ints).
+type Change struct {
+	Path []any
+	Kind Kind
+	Old  any // unset when Added
+	New  any // unset when Removed
+}
+
+// PathString formats the path as data.items[2].name.
+func (c Change) PathString() string {
+	return FormatPath(c.Path)
+}
+
+// FormatPath formats path as data.items[2].name.
+func FormatPath(path []any) string {
+	var b strings.Builder
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			b.WriteString("[" + strconv.Itoa(p) + "]")
+		default:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(p.(string))
+		}
+	}
+	return b.String()
+}
+
+// Diff returns the changes from before to after, objects compared key by
+// key in key order and arrays index by index.
+func Diff(before, after any) []Change {
+	var changes []Change
+	diff(nil, before, after, &changes)
+	return changes
+}
+
+func diff(path []any, before, after any, changes *[]Change) {
+	switch o := before.(type) {
+	case map[string]any:
+		if n, ok := after.(map[string]any); ok {
+			keys := slices.Collect(maps.Keys(o))
+			for k := range n {
+				if _, ok := o[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				diffAt(append(slices.Clip(path), k), o, n, k, changes)
+			}
+			return
+		}
+	case []any:
+		if n, ok := after.([]any); ok {
+			for i := range max(len(o), len(n)) {
+				p := append(slices.Clip(path), i)
+				switch {
+				case i >= len(n):
+					*changes = append(*changes, Change{Path: p, Kind: Removed, Old: o[i]})
+				case i >= len(o):
+					*changes = append(*changes, Change{Path: p, Kind: Added, New: n[i]})
+				default:
+					diff(p, o[i], n[i], changes)
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Path: path, Kind: Changed, Old: before, New: after})
+	}
+}
+
+func diffAt(path []any, before, after map[string]any, key string, changes *[]Change) {
+	o, inOld := before[key]
+	n, inNew := after[key]
+	switch {
+	case !inNew:
+		*changes = append(*changes, Change{Path: path, Kind: Removed, Old: o})
+	case !inOld:
+		*changes = append(*changes, Change{Path: path, Kind: Added, New: n})
+	default:
+		diff(path, o, n, changes)
+	}
+}
@@ -0,0 +1,89 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDiff(t *testing.T) {
+	before := decode(t, `{"data": {"job": {"status": "RUNNING", "progress": 10, "tags": ["a", "b"], "eta": 5}, "same": {"x": [1, {"y": 2}]}}}`)
+	after := decode(t, `{"data": {"job": {"status": "DONE", "progress": 10, "tags": ["a"], "result": {"ok": true}}, "same": {"x": [1, {"y": 2}]}}}`)
+
+	var got []string
+	for _, c := range Diff(before, after) {
+		got = append(got, fmt.Sprintf("%s %s %v -> %v", c.Kind, c.PathString(), c.Old, c.New))
+	}
+	want := []string{
+		"removed data.job.eta 5 -> <nil>",
+		"added data.job.result <nil> -> map[ok:true]",
+		"changed data.job.status RUNNING -> DONE",
+		"removed data.job.tags[1] b -> <nil>",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes := Diff(before, before); len(changes) != 0 {
+		t.Errorf("expected no changes between equal values, got %v", changes)
+	}
+	changes := Diff(decode(t, `{"a": [1]}`), decode(t, `{"a": {"0": 1}}`))
+	if len(changes) != 1 || changes[0].Kind != Changed || changes[0].PathString() != "a" {
+		t.Errorf("expected a type change reported at a, got %+v", changes)
+	}
+	if changes := Diff(nil, decode(t, `{"a": 1}`)); len(changes) != 1 || len(changes[0].Path) != 0 {
+		t.Errorf("expected the whole value changed, got %+v", changes)
+	}
+}
+
+func TestFormatPath(t *testing.T) {
+	if got := FormatPath([]any{"data", "items", 2, "name"}); got != "data.items[2].name" {
+		t.Errorf("got %q", got)
+	}
+	if got := FormatPath([]any{0, "id"}); got != "[0].id" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	path := []any{"data", "items", 2, "updatedAt"}
+	for _, tc := range []struct {
+		pattern string
+		want    bool
+	}{
+		{"data.items[2].updatedAt", true},
+		{"data.items[*].updatedAt", true},
+		{"data.*[*].updatedAt", true},
+		{"data.items", true}, // covers what is below it
+		{"updatedAt", true},  // a single key matches at any depth
+		{"items", true},
+		{"data.items[1]", false},
+		{"data.items.updatedAt", false},
+		{"*.updatedAt", false},
+		{"data.items[2].updatedAt.x", false},
+		{"", false},
+	} {
+		if got := Ignored(path, []string{tc.pattern}); got != tc.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tc.pattern, got, tc.want)
+		}
+	}
+
+	changes := Diff(
+		decode(t, `{"data": {"id": 1, "at": "10:00", "meta": {"ts": 1}}, "extensions": {"cost": 3}}`),
+		decode(t, `{"data": {"id": 2, "at": "10:01", "meta": {"ts": 2}}, "extensions": {"cost": 4}}`),
+	)
+	kept, ignored := Filter(changes, []string{"at", "data.meta", "extensions"})
+	if len(kept) != 1 || kept[0].PathString() != "data.id" || ignored != 3 {
+		t.Errorf("expected only data.id kept and 3 ignored, got %+v and %d", kept, ignored)
+	}
+}
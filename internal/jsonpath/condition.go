@@ -0,0 +1,64 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Condition compares the value at a path with an expected one, as in
+// data.job.status == "DONE". The expected value is JSON, or a string when it
+// is not valid JSON.
+type Condition struct {
+	Path  string
+	Not   bool // != rather than ==
+	Value any
+	text  string // the expected value as written
+}
+
+// ParseCondition parses "path == value" or "path != value".
+func ParseCondition(s string) (Condition, error) {
+	op := "=="
+	i := strings.Index(s, op)
+	if j := strings.Index(s, "!="); j >= 0 && (i < 0 || j < i) {
+		op, i = "!=", j
+	}
+	if i < 0 {
+		return Condition{}, fmt.Errorf("invalid condition %q (use path == value)", strings.TrimSpace(s))
+	}
+	c := Condition{
+		Path: strings.TrimSpace(s[:i]),
+		Not:  op == "!=",
+		text: strings.TrimSpace(s[i+len(op):]),
+	}
+	if _, err := Split(c.Path); err != nil {
+		return Condition{}, err
+	}
+	if c.text == "" {
+		return Condition{}, fmt.Errorf("invalid condition %q: no value", strings.TrimSpace(s))
+	}
+	if err := json.Unmarshal([]byte(c.text), &c.Value); err != nil {
+		c.Value = c.text
+	}
+	return c, nil
+}
+
+func (c Condition) String() string {
+	op := " == "
+	if c.Not {
+		op = " != "
+	}
+	return c.Path + op + c.text
+}
+
+// Eval reports whether the condition holds for v, a value decoded by
+// encoding/json, and returns the value found at the path. A missing path
+// is an error.
+func (c Condition) Eval(v any) (bool, any, error) {
+	got, err := Get(v, c.Path)
+	if err != nil {
+		return false, nil, err
+	}
+	return reflect.DeepEqual(got, c.Value) != c.Not, got, nil
+}
@@ -0,0 +1,62 @@
+// Package jsonpath looks up values in decoded JSON by dotted paths such as
+// data.users[0].name (data.users.0.name works too).
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Split breaks path into its keys and array indexes.
+func Split(path string) ([]string, error) {
+	var parts []string
+	for _, seg := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(seg, "[")
+		if key != "" {
+			parts = append(parts, key)
+		}
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			if !ok || idx == "" {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+			parts = append(parts, idx)
+			rest = strings.TrimPrefix(after, "[")
+			if after != "" && !strings.HasPrefix(after, "[") {
+				return nil, fmt.Errorf("invalid path %q", path)
+			}
+		}
+		if key == "" && !strings.Contains(seg, "[") {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+	}
+	return parts, nil
+}
+
+// Get returns the value at path inside v, a value decoded by encoding/json.
+func Get(v any, path string) (any, error) {
+	parts, err := Split(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range parts {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[p]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", strings.Join(parts[:i+1], "."))
+			}
+			v = next
+		case []any:
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 0 || n >= len(node) {
+				return nil, fmt.Errorf("%s not found", strings.Join(parts[:i+1], "."))
+			}
+			v = node[n]
+		default:
+			return nil, fmt.Errorf("%s not found", strings.Join(parts[:i+1], "."))
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,86 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := map[string][]string{
+		"data.login.token":   {"data", "login", "token"},
+		"data.users[0].name": {"data", "users", "0", "name"},
+		"data.users.0.name":  {"data", "users", "0", "name"},
+		"data.matrix[1][2]":  {"data", "matrix", "1", "2"},
+		"data":               {"data"},
+	}
+	for path, want := range tests {
+		got, err := Split(path)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Split(%q) = %v, %v; want %v", path, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "data..x", "data.users[0", "data.users[]", "data.users[0]x"} {
+		if _, err := Split(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	var doc any
+	_ = json.Unmarshal([]byte(`{"data":{"login":{"token":"abc","user":{"id":7}},"users":[{"name":"ada"}]}}`), &doc)
+
+	if v, err := Get(doc, "data.login.token"); err != nil || v != "abc" {
+		t.Errorf("unexpected %v, %v", v, err)
+	}
+	if v, err := Get(doc, "data.users[0].name"); err != nil || v != "ada" {
+		t.Errorf("unexpected %v, %v", v, err)
+	}
+	if v, err := Get(doc, "data.login.user.id"); err != nil || v != 7.0 {
+		t.Errorf("unexpected %v, %v", v, err)
+	}
+	for _, missing := range []string{"data.logout", "data.users[3]", "data.login.token.x"} {
+		if _, err := Get(doc, missing); err == nil {
+			t.Errorf("expected %q not found", missing)
+		}
+	}
+}
+
+func TestCondition(t *testing.T) {
+	var doc any
+	_ = json.Unmarshal([]byte(`{"data":{"job":{"status":"DONE","progress":100,"tags":["a"]}}}`), &doc)
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{`data.job.status == "DONE"`, true},
+		{`data.job.status == DONE`, true},
+		{`data.job.status != "DONE"`, false},
+		{`data.job.progress == 100`, true},
+		{`data.job.progress == 99.5`, false},
+		{`data.job.tags == ["a"]`, true},
+		{`data.job.status == "a == b"`, false},
+	}
+	for _, tt := range tests {
+		c, err := ParseCondition(tt.cond)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.cond, err)
+		}
+		if got, _, err := c.Eval(doc); err != nil || got != tt.want {
+			t.Errorf("%s = %v, %v; want %v", tt.cond, got, err, tt.want)
+		}
+	}
+	c, _ := ParseCondition(` data.job.status==  "DONE" `)
+	if c.String() != `data.job.status == "DONE"` {
+		t.Errorf("unexpected String %q", c.String())
+	}
+	if _, _, err := c.Eval(map[string]any{"data": nil}); err == nil {
+		t.Error("expected a missing path to be an error")
+	}
+	for _, bad := range []string{"data.job.status", "data..x == 1", "data.x == "} {
+		if _, err := ParseCondition(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package overlay
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/qraqula/qla/internal/auth"
+	"github.com/qraqula/qla/internal/config"
+)
+
+// connField is one editable connection setting of an environment.
+type connField struct {
+	label       string
+	json        string // key in config.json, to tell workspace settings
+	placeholder string // shown in the input when editing
+	unset       string // shown in the list when the value is empty
+	get         func(*config.Environment) string
+	set         func(*config.Environment, string) error
+	options     []string                       // cycled with enter/space instead of edited; the first is the default
+	secret      bool                           // value is masked in the list
+	show        func(*config.Environment) bool // nil means always shown
+}
+
+var connFields = []connField{
+	{
+		label:       "Timeout",
+		json:        "timeout",
+		placeholder: "30s, 5m, 0 for none",
+		unset:       "30s (default)",
+		get:         func(e *config.Environment) string { return e.Timeout },
+		set: func(e *config.Environment, v string) error {
+			if v != "" {
+				if _, err := time.ParseDuration(v); err != nil {
+					return fmt.Errorf("invalid duration %q (use e.g. 30s, 5m)", v)
+				}
+			}
+			e.Timeout = v
+			return nil
+		},
+	},
+	{
+		label:       "CA bundle",
+		json:        "caCert",
+		placeholder: "~/certs/ca.pem",
+		unset:       "system roots",
+		get:         func(e *config.Environment) string { return e.CACert },
+		set:         func(e *config.Environment, v string) error { e.CACert = v; return nil },
+	},
+	{
+		label:       "Client cert",
+		json:        "clientCert",
+		placeholder: "~/certs/client.pem",
+		unset:       "none",
+		get:         func(e *config.Environment) string { return e.ClientCert },
+		set:         func(e *config.Environment, v string) error { e.ClientCert = v; return nil },
+	},
+	{
+		label:       "Client key",
+		json:        "clientKey",
+		placeholder: "~/certs/client-key.pem",
+		unset:       "none",
+		get:         func(e *config.Environment) string { return e.ClientKey },
+		set:         func(e *config.Environment, v string) error { e.ClientKey = v; return nil },
+	},
+	{
+		label: "Skip TLS verify",
+		json:  "insecure",
+		get: func(e *config.Environment) string {
+			if e.Insecure {
+				return "on"
+			}
+			return "off"
+		},
+		set:     func(e *config.Environment, v string) error { e.Insecure = v == "on"; return nil },
+		options: []string{"off", "on"},
+	},
+	{
+		label:       "Proxy",
+		json:        "proxy",
+		placeholder: "http://proxy.corp:3128",
+		unset:       "from HTTP(S)_PROXY",
+		get:         func(e *config.Environment) string { return e.Proxy },
+		set:         func(e *config.Environment, v string) error { e.Proxy = v; return nil },
+	},
+	{
+		label: "Auth",
+		json:  "auth",
+		get: func(e *config.Environment) string {
+			if e.Auth == nil || e.Auth.Type == "" {
+				return "none"
+			}
+			return e.Auth.Type
+		},
+		set: func(e *config.Environment, v string) error {
+			if v == "none" {
+				e.Auth = nil
+				return nil
+			}
+			if e.Auth == nil {
+				e.Auth = &auth.Config{}
+			}
+			e.Auth.Type = v
+			return nil
+		},
+		options: append([]string{"none"}, auth.Types...),
+	},
+	authField("Username", "alice", func(c *auth.Config) *string { return &c.Username }, false, auth.TypeBasic),
+	authField("Password", "{{env.API_PASSWORD}}", func(c *auth.Config) *string { return &c.Password }, true, auth.TypeBasic),
+	authField("Token URL", "https://auth.example.com/oauth/token", func(c *auth.Config) *string { return &c.TokenURL }, false, auth.TypeClientCredentials, auth.TypeRefreshToken),
+	authField("Client ID", "my-client", func(c *auth.Config) *string { return &c.ClientID }, false, auth.TypeClientCredentials, auth.TypeRefreshToken),
+	authField("Client secret", "{{env.CLIENT_SECRET}}", func(c *auth.Config) *string { return &c.ClientSecret }, true, auth.TypeClientCredentials, auth.TypeRefreshToken),
+	authField("Scope", "read write", func(c *auth.Config) *string { return &c.Scope }, false, auth.TypeClientCredentials, auth.TypeRefreshToken),
+	authField("Refresh token", "{{env.REFRESH_TOKEN}}", func(c *auth.Config) *string { return &c.RefreshToken }, true, auth.TypeRefreshToken),
+}
+
+// authField is a text field of the auth section, shown for the given types.
+func authField(label, placeholder string, field func(*auth.Config) *string, secret bool, types ...string) connField {
+	return connField{
+		label:       label,
+		json:        "auth",
+		placeholder: placeholder,
+		unset:       "none",
+		get:         func(e *config.Environment) string { return *field(e.Auth) },
+		set: func(e *config.Environment, v string) error {
+			*field(e.Auth) = v
+			return nil
+		},
+		secret: secret,
+		show: func(e *config.Environment) bool {
+			return e.Auth != nil && slices.Contains(types, e.Auth.Type)
+		},
+	}
+}
+
+// visibleConnFields returns the fields that apply to env.
+func visibleConnFields(env *config.Environment) []connField {
+	var fields []connField
+	for _, f := range connFields {
+		if f.show == nil || f.show(env) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// connEnv returns the environment whose connection settings are shown.
+func (m *Model) connEnv() *config.Environment {
+	if m.config == nil || m.envCursor >= len(m.config.Environments) {
+		return nil
+	}
+	return &m.config.Environments[m.envCursor]
+}
+
+func (m Model) openConnection() (Model, tea.Cmd) {
+	if m.connEnv() == nil {
+		return m, nil
+	}
+	m.connOpen = true
+	m.connCursor = 0
+	return m, nil
+}
+
+// handleConnKey handles keys while the connection settings are shown.
+func (m Model) handleConnKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	env := m.connEnv()
+	if env == nil {
+		m.connOpen = false
+		return m, nil
+	}
+	fields := visibleConnFields(env)
+	m.connCursor = min(m.connCursor, len(fields)-1)
+	f := fields[m.connCursor]
+	m.editErr = ""
+	switch msg.String() {
+	case "space", " ", "enter", "d", "backspace":
+		if m.connLocked(f) {
+			return m, nil
+		}
+	}
+
+	switch msg.String() {
+	case "esc", "c":
+		m.connOpen = false
+	case "j", "down":
+		if m.connCursor < len(fields)-1 {
+			m.connCursor++
+		}
+	case "k", "up":
+		if m.connCursor > 0 {
+			m.connCursor--
+		}
+	case "space", " ":
+		if f.options != nil {
+			return m.cycleConnField(env, f)
+		}
+	case "enter":
+		if f.options != nil {
+			return m.cycleConnField(env, f)
+		}
+		m.mode = ModeEditConn
+		m.editErr = ""
+		m.input.SetValue(f.get(env))
+		m.input.Placeholder = f.placeholder
+		m.setInputWidth()
+		cmd := m.input.Focus()
+		m.input.CursorEnd()
+		return m, cmd
+	case "d", "backspace":
+		if f.options != nil {
+			_ = f.set(env, f.options[0])
+		} else {
+			_ = f.set(env, "")
+		}
+		return m, m.emitChanged()
+	}
+	return m, nil
+}
+
+// cycleConnField moves f to its next option.
+func (m Model) cycleConnField(env *config.Environment, f connField) (Model, tea.Cmd) {
+	i := slices.Index(f.options, f.get(env))
+	_ = f.set(env, f.options[(i+1)%len(f.options)])
+	return m, m.emitChanged()
+}
+
+// connField returns the field under the cursor.
+func (m Model) connField() connField {
+	fields := visibleConnFields(m.connEnv())
+	return fields[min(m.connCursor, len(fields)-1)]
+}
+
+// confirmConnEdit stores the edited value, keeping the input open when the
+// value is rejected.
+func (m Model) confirmConnEdit(val string) (Model, tea.Cmd) {
+	env := m.connEnv()
+	if env == nil {
+		m.mode = ModeNormal
+		return m, nil
+	}
+	if err := m.connField().set(env, strings.TrimSpace(val)); err != nil {
+		m.editErr = err.Error()
+		return m, m.input.Focus()
+	}
+	m.mode = ModeNormal
+	m.editErr = ""
+	return m, m.emitChanged()
+}
+
+func (m Model) renderConnSection() string {
+	var lines []string
+	cw := m.contentWidth()
+	env := m.connEnv()
+
+	lines = append(lines, activeSectionTitle.Render("CONNECTION — "+env.Name))
+	lines = append(lines, sepLine.Render(strings.Repeat("─", cw)))
+
+	labelW := 0
+	for _, f := range connFields {
+		labelW = max(labelW, lipgloss.Width(f.label))
+	}
+	for i, f := range visibleConnFields(env) {
+		label := f.label + strings.Repeat(" ", labelW-lipgloss.Width(f.label))
+		val := f.get(env)
+		var valStr string
+		switch {
+		case f.options != nil && val != f.options[0]:
+			valStr = enabledStyle.Render(val)
+		case val == "" || f.options != nil:
+			valStr = dimStyle.Render(truncate(orDefault(val, f.unset), cw-labelW-4))
+		case f.secret && !strings.Contains(val, "{{"):
+			valStr = dimStyle.Render("••••••••")
+		default:
+			valStr = normalStyle.Render(truncate(val, cw-labelW-4))
+		}
+		if m.config.WorkspaceField(env.Name, f.json) {
+			valStr += "  " + hintStyle.Render("workspace")
+		}
+		if i == m.connCursor {
+			lines = append(lines, "  "+selectedStyle.Render(label)+"  "+valStr)
+		} else {
+			lines = append(lines, "  "+normalStyle.Render(label)+"  "+valStr)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,150 @@
+package overlay
+
+import (
+	"fmt"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/secret"
+)
+
+// inherited returns the headers the active environment inherits, listed
+// greyed out after its own.
+func (m Model) inherited() []config.InheritedHeader {
+	if m.config == nil || m.config.ActiveEnv == "" {
+		return nil
+	}
+	return m.config.InheritedHeaders(m.config.ActiveEnv)
+}
+
+// inheritedRow returns the inherited header under the cursor, if any.
+func (m Model) inheritedRow() (config.InheritedHeader, bool) {
+	if m.section != SectionHeaders {
+		return config.InheritedHeader{}, false
+	}
+	hdrs := m.currentHeaders()
+	if hdrs == nil {
+		return config.InheritedHeader{}, false
+	}
+	i := m.hdrCursor - len(*hdrs)
+	inherited := m.inherited()
+	if i < 0 || i >= len(inherited) {
+		return config.InheritedHeader{}, false
+	}
+	return inherited[i], true
+}
+
+// headerRows returns the number of rows of the current header section.
+func (m Model) headerRows() int {
+	hdrs := m.currentHeaders()
+	if hdrs == nil {
+		return 0
+	}
+	if m.section == SectionHeaders {
+		return len(*hdrs) + len(m.inherited())
+	}
+	return len(*hdrs)
+}
+
+// handleInheritedKey handles key on an inherited header. Enter copies it
+// into the environment to edit, space overrides it toggled; other changes
+// need an override first.
+func (m Model) handleInheritedKey(key string, h config.InheritedHeader) (Model, tea.Cmd, bool) {
+	switch key {
+	case "enter":
+		m.override(h.Header)
+		next, cmd := m.handleEnter()
+		return next, tea.Batch(m.emitChanged(), cmd), true
+	case "space", " ":
+		h.Enabled = !h.Enabled
+		m.override(h.Header)
+		return m, m.emitChanged(), true
+	case "d", "s", "e":
+		m.editErr = fmt.Sprintf("inherited from %s; ↵ or space overrides it here", h.From)
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// override adds h to the active environment's own headers and selects it.
+func (m *Model) override(h config.Header) {
+	env := m.config.ActiveEnvironment()
+	env.Headers = append(env.Headers, h)
+	m.hdrCursor = len(env.Headers) - 1
+}
+
+func (m Model) startEditEnvExtends() (Model, tea.Cmd) {
+	if m.config == nil || len(m.config.Environments) == 0 || m.envCursor >= len(m.config.Environments) {
+		return m, nil
+	}
+	m.mode = ModeEditEnvExtends
+	m.editErr = ""
+	m.input.SetValue(m.config.Environments[m.envCursor].Extends)
+	m.input.Placeholder = "environment to inherit from"
+	m.setInputWidth()
+	cmd := m.input.Focus()
+	m.input.CursorEnd()
+	return m, cmd
+}
+
+// children returns the environments that extend name.
+func (m Model) children(name string) []string {
+	var names []string
+	for _, env := range m.config.Environments {
+		if env.Extends == name {
+			names = append(names, env.Name)
+		}
+	}
+	return names
+}
+
+// renameParent points the environments extending old at name.
+func (m *Model) renameParent(old, name string) {
+	for i := range m.config.Environments {
+		if m.config.Environments[i].Extends == old {
+			m.config.Environments[i].Extends = name
+		}
+	}
+}
+
+// deleteBlocked reports whether the environment under the cursor is
+// extended by others, which would be left without a parent, and says so.
+func (m *Model) deleteBlocked() bool {
+	name := m.config.Environments[m.envCursor].Name
+	children := m.children(name)
+	if len(children) == 0 {
+		return false
+	}
+	m.editErr = fmt.Sprintf("%s extends %s", children[0], name)
+	return true
+}
+
+func (m Model) renderInheritedRow(idx int, h config.InheritedHeader) string {
+	check := disabledStyle.Render("[ ]")
+	if h.Enabled {
+		check = disabledStyle.Render("[✓]")
+	}
+	tag := "  " + dimStyle.Render("from "+h.From)
+
+	valMax := m.contentWidth() - 8 - lipgloss.Width(h.Key) - lipgloss.Width(tag)
+	if valMax < 10 {
+		valMax = 10
+	}
+	val := truncate(secret.Describe(h.Value), valMax)
+
+	keyStyle, valStyle := dimStyle, dimStyle
+	if m.section == SectionHeaders && idx == m.hdrCursor {
+		if m.hdrCol == 0 {
+			keyStyle = selectedStyle
+		} else {
+			valStyle = selectedStyle
+		}
+	}
+	return fmt.Sprintf("  %s %s  %s", check, keyStyle.Render(h.Key), valStyle.Render(val)) + tag
+}
+
+func (m Model) isInheritedRow() bool {
+	_, ok := m.inheritedRow()
+	return ok
+}
@@ -1,6 +1,7 @@
 package overlay
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -8,6 +9,8 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/extract"
+	"github.com/qraqula/qla/internal/secret"
 )
 
 // Messages returned to the parent app.
@@ -35,6 +38,10 @@ const (
 	ModeRenameEnv       // renaming an environment
 	ModeEditEnvEndpoint // editing environment endpoint
 	ModeEditEnvVars     // editing environment variables
+	ModeEditConn        // editing a connection setting
+	ModeEditEnvValues   // editing environment placeholder values
+	ModeEditEnvExtract  // editing environment extraction rules
+	ModeEditEnvExtends  // editing the environment an environment extends
 )
 
 // Vampire theme colors (matching app).
@@ -91,6 +98,14 @@ type Model struct {
 	hdrCursor int
 	hdrCol    int // 0=key, 1=value
 
+	// Connection settings of the environment under the cursor, shown in
+	// place of the environment list.
+	connOpen   bool
+	connCursor int
+	editErr    string // rejected input, shown next to the edit line
+
+	secrets *secret.Resolver
+
 	input textinput.Model
 }
 
@@ -110,6 +125,8 @@ func (m *Model) Open(cfg *config.Config, w, h int) {
 	m.envCursor = 0
 	m.hdrCursor = 0
 	m.hdrCol = 0
+	m.connOpen = false
+	m.editErr = ""
 	m.input.SetValue("")
 	m.input.Blur()
 	m.clampCursors()
@@ -153,6 +170,18 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 	if m.mode != ModeNormal {
 		return m.handleEditKey(msg)
 	}
+	if m.connOpen {
+		return m.handleConnKey(msg)
+	}
+	m.editErr = ""
+	if m.workspaceLocked(msg.String()) {
+		return m, nil
+	}
+	if h, ok := m.inheritedRow(); ok {
+		if next, cmd, handled := m.handleInheritedKey(msg.String(), h); handled {
+			return next, cmd
+		}
+	}
 
 	switch msg.String() {
 	case "esc":
@@ -220,17 +249,59 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "s":
+		if m.section != SectionEnvs {
+			return m.protectHeader(false)
+		}
+		return m, nil
+
 	case "e":
 		if m.section == SectionEnvs {
 			return m.startEditEnvEndpoint()
 		}
-		return m, nil
+		return m.protectHeader(true)
 
 	case "v":
 		if m.section == SectionEnvs {
 			return m.startEditEnvVars()
 		}
 		return m, nil
+
+	case "t":
+		if m.section == SectionEnvs {
+			return m.toggleSubscriptions()
+		}
+		return m, nil
+
+	case "m":
+		if m.section == SectionEnvs {
+			return m.cycleTransport()
+		}
+		return m, nil
+
+	case "c":
+		if m.section == SectionEnvs {
+			return m.openConnection()
+		}
+		return m, nil
+
+	case "p":
+		if m.section == SectionEnvs {
+			return m.startEditEnvValues()
+		}
+		return m, nil
+
+	case "x":
+		if m.section == SectionEnvs {
+			return m.startEditEnvExtract()
+		}
+		return m, nil
+
+	case "i":
+		if m.section == SectionEnvs {
+			return m.startEditEnvExtends()
+		}
+		return m, nil
 	}
 
 	return m, nil
@@ -242,6 +313,7 @@ func (m Model) handleEditKey(msg tea.KeyPressMsg) (Model, tea.Cmd) {
 		return m.confirmEdit()
 	case "esc":
 		m.mode = ModeNormal
+		m.editErr = ""
 		m.input.Blur()
 		return m, nil
 	}
@@ -345,7 +417,7 @@ func (m Model) handleDelete() (Model, tea.Cmd) {
 		if len(m.config.Environments) == 0 {
 			return m, nil
 		}
-		if m.envCursor >= len(m.config.Environments) {
+		if m.envCursor >= len(m.config.Environments) || m.deleteBlocked() {
 			return m, nil
 		}
 		name := m.config.Environments[m.envCursor].Name
@@ -385,6 +457,39 @@ func (m Model) toggleHeader() (Model, tea.Cmd) {
 	return m, m.emitChanged()
 }
 
+// toggleSubscriptions switches the selected environment between WebSocket
+// and HTTP streaming subscriptions.
+func (m Model) toggleSubscriptions() (Model, tea.Cmd) {
+	if m.config == nil || len(m.config.Environments) == 0 || m.envCursor >= len(m.config.Environments) {
+		return m, nil
+	}
+	env := &m.config.Environments[m.envCursor]
+	if env.Subscriptions == config.SubscriptionsSSE {
+		env.Subscriptions = ""
+	} else {
+		env.Subscriptions = config.SubscriptionsSSE
+	}
+	return m, m.emitChanged()
+}
+
+// cycleTransport switches the selected environment between POST, GET and
+// persisted-query (APQ) execution.
+func (m Model) cycleTransport() (Model, tea.Cmd) {
+	if m.config == nil || len(m.config.Environments) == 0 || m.envCursor >= len(m.config.Environments) {
+		return m, nil
+	}
+	env := &m.config.Environments[m.envCursor]
+	switch env.Transport {
+	case "", config.TransportPOST:
+		env.Transport = config.TransportGET
+	case config.TransportGET:
+		env.Transport = config.TransportAPQ
+	default:
+		env.Transport = ""
+	}
+	return m, m.emitChanged()
+}
+
 func (m Model) startRenameEnv() (Model, tea.Cmd) {
 	if m.config == nil || len(m.config.Environments) == 0 || m.envCursor >= len(m.config.Environments) {
 		return m, nil
@@ -423,6 +528,38 @@ func (m Model) startEditEnvVars() (Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m Model) startEditEnvValues() (Model, tea.Cmd) {
+	if m.config == nil || len(m.config.Environments) == 0 || m.envCursor >= len(m.config.Environments) {
+		return m, nil
+	}
+	m.mode = ModeEditEnvValues
+	m.editErr = ""
+	m.input.SetValue("")
+	if values := m.config.Environments[m.envCursor].Values; len(values) > 0 {
+		raw, _ := json.Marshal(values)
+		m.input.SetValue(string(raw))
+	}
+	m.input.Placeholder = `{"token": "abc123"} for {{token}}`
+	m.setInputWidth()
+	cmd := m.input.Focus()
+	m.input.CursorEnd()
+	return m, cmd
+}
+
+func (m Model) startEditEnvExtract() (Model, tea.Cmd) {
+	if m.config == nil || len(m.config.Environments) == 0 || m.envCursor >= len(m.config.Environments) {
+		return m, nil
+	}
+	m.mode = ModeEditEnvExtract
+	m.editErr = ""
+	m.input.SetValue(extract.FormatList(m.config.Environments[m.envCursor].Extract))
+	m.input.Placeholder = "data.login.token -> authToken; ..."
+	m.setInputWidth()
+	cmd := m.input.Focus()
+	m.input.CursorEnd()
+	return m, cmd
+}
+
 func (m Model) confirmEdit() (Model, tea.Cmd) {
 	val := m.input.Value()
 	m.input.Blur()
@@ -447,6 +584,7 @@ func (m Model) confirmEdit() (Model, tea.Cmd) {
 		if m.config.ActiveEnv == old {
 			m.config.ActiveEnv = val
 		}
+		m.renameParent(old, val)
 		return m, m.emitChanged()
 
 	case ModeEditEnvEndpoint:
@@ -465,6 +603,57 @@ func (m Model) confirmEdit() (Model, tea.Cmd) {
 		m.config.Environments[m.envCursor].Variables = val
 		return m, m.emitChanged()
 
+	case ModeEditConn:
+		return m.confirmConnEdit(val)
+
+	case ModeEditEnvValues:
+		if m.envCursor >= len(m.config.Environments) {
+			m.mode = ModeNormal
+			return m, nil
+		}
+		var values map[string]string
+		if strings.TrimSpace(val) != "" {
+			if err := json.Unmarshal([]byte(val), &values); err != nil {
+				m.editErr = "values must be a JSON object of strings"
+				return m, m.input.Focus()
+			}
+		}
+		m.mode = ModeNormal
+		m.editErr = ""
+		m.config.Environments[m.envCursor].Values = values
+		return m, m.emitChanged()
+
+	case ModeEditEnvExtract:
+		if m.envCursor >= len(m.config.Environments) {
+			m.mode = ModeNormal
+			return m, nil
+		}
+		rules, err := extract.ParseList(val)
+		if err != nil {
+			m.editErr = err.Error()
+			return m, m.input.Focus()
+		}
+		m.mode = ModeNormal
+		m.editErr = ""
+		m.config.Environments[m.envCursor].Extract = rules
+		return m, m.emitChanged()
+
+	case ModeEditEnvExtends:
+		if m.envCursor >= len(m.config.Environments) {
+			m.mode = ModeNormal
+			return m, nil
+		}
+		val = strings.TrimSpace(val)
+		if err := m.config.CheckExtends(m.config.Environments[m.envCursor].Name, val); err != nil {
+			m.editErr = err.Error()
+			return m, m.input.Focus()
+		}
+		m.mode = ModeNormal
+		m.editErr = ""
+		m.config.Environments[m.envCursor].Extends = val
+		m.clampCursors()
+		return m, m.emitChanged()
+
 	case ModeEditKey:
 		hdrs := m.currentHeaders()
 		if hdrs == nil || m.hdrCursor >= len(*hdrs) {
@@ -523,13 +712,8 @@ func (m *Model) clampCursors() {
 	if m.envCursor >= len(m.config.Environments) {
 		m.envCursor = max(0, len(m.config.Environments)-1)
 	}
-	hdrs := m.currentHeaders()
-	if hdrs != nil {
-		if m.hdrCursor >= len(*hdrs) {
-			m.hdrCursor = max(0, len(*hdrs)-1)
-		}
-	} else {
-		m.hdrCursor = 0
+	if rows := m.headerRows(); m.hdrCursor >= rows {
+		m.hdrCursor = max(0, rows-1)
 	}
 }
 
@@ -540,8 +724,7 @@ func (m *Model) moveCursorDown() {
 			m.envCursor++
 		}
 	default:
-		hdrs := m.currentHeaders()
-		if hdrs != nil && m.hdrCursor < len(*hdrs)-1 {
+		if m.hdrCursor < m.headerRows()-1 {
 			m.hdrCursor++
 		}
 	}
@@ -598,7 +781,11 @@ func (m Model) renderContent() string {
 	var sections []string
 
 	// Title
-	sections = append(sections, m.renderEnvSection())
+	if m.connOpen {
+		sections = append(sections, m.renderConnSection())
+	} else {
+		sections = append(sections, m.renderEnvSection())
+	}
 	sections = append(sections, "")
 	sections = append(sections, m.renderHeaderSection())
 	sections = append(sections, "")
@@ -608,6 +795,9 @@ func (m Model) renderContent() string {
 	if m.mode != ModeNormal {
 		sections = append(sections, "")
 		sections = append(sections, m.renderEditLine())
+	} else if m.editErr != "" {
+		sections = append(sections, "")
+		sections = append(sections, activeSectionTitle.Render(m.editErr))
 	}
 
 	// Hints
@@ -622,10 +812,14 @@ func (m Model) renderEnvSection() string {
 	cw := m.contentWidth()
 
 	title := "ENVIRONMENTS"
+	var origin string
+	if m.config != nil && m.config.Workspace() != nil {
+		origin = dimStyle.Render("  workspace " + m.workspaceDir() + " layered over your config")
+	}
 	if m.section == SectionEnvs {
-		lines = append(lines, activeSectionTitle.Render(title))
+		lines = append(lines, activeSectionTitle.Render(title)+origin)
 	} else {
-		lines = append(lines, sectionTitle.Render(title))
+		lines = append(lines, sectionTitle.Render(title)+origin)
 	}
 	lines = append(lines, sepLine.Render(strings.Repeat("─", cw)))
 
@@ -643,12 +837,25 @@ func (m Model) renderEnvSection() string {
 		}
 
 		name := env.Name
-		// Truncate endpoint to fill remaining width after marker + name + gap
-		epMax := cw - lipgloss.Width(marker) - lipgloss.Width(name) - 2
+		var tag string
+		if env.Transport != "" && env.Transport != config.TransportPOST {
+			tag += "  " + hintStyle.Render(strings.ToUpper(env.Transport))
+		}
+		if env.Subscriptions == config.SubscriptionsSSE {
+			tag += "  " + hintStyle.Render("sse")
+		}
+		if env.Extends != "" {
+			tag += "  " + hintStyle.Render("extends "+env.Extends)
+		}
+		if m.config.WorkspaceEnv(env.Name) {
+			tag += "  " + hintStyle.Render("workspace")
+		}
+		// Truncate endpoint to fill remaining width after marker + name + gap + tag
+		epMax := cw - lipgloss.Width(marker) - lipgloss.Width(name) - 2 - lipgloss.Width(tag)
 		if epMax < 10 {
 			epMax = 10
 		}
-		ep := dimStyle.Render(truncate(env.Endpoint, epMax))
+		ep := dimStyle.Render(truncate(m.config.Endpoint(env.Name), epMax)) + tag
 
 		if m.section == SectionEnvs && i == m.envCursor {
 			line := marker + selectedStyle.Render(name) + "  " + ep
@@ -684,13 +891,17 @@ func (m Model) renderHeaderSection() string {
 		return strings.Join(lines, "\n")
 	}
 
-	if len(env.Headers) == 0 {
+	inherited := m.inherited()
+	if len(env.Headers) == 0 && len(inherited) == 0 {
 		lines = append(lines, dimStyle.Render("  (no headers)"))
 		return strings.Join(lines, "\n")
 	}
 
 	for i, h := range env.Headers {
-		lines = append(lines, m.renderHeaderRow(i, h, m.section == SectionHeaders))
+		lines = append(lines, m.renderHeaderRow(i, h, m.section == SectionHeaders, m.config.WorkspaceHeader(env.Name, h.Key)))
+	}
+	for i, h := range inherited {
+		lines = append(lines, m.renderInheritedRow(len(env.Headers)+i, h))
 	}
 
 	return strings.Join(lines, "\n")
@@ -714,13 +925,13 @@ func (m Model) renderGlobalSection() string {
 	}
 
 	for i, h := range m.config.GlobalHeaders {
-		lines = append(lines, m.renderHeaderRow(i, h, m.section == SectionGlobal))
+		lines = append(lines, m.renderHeaderRow(i, h, m.section == SectionGlobal, m.config.WorkspaceHeader("", h.Key)))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
-func (m Model) renderHeaderRow(idx int, h config.Header, isActiveSection bool) string {
+func (m Model) renderHeaderRow(idx int, h config.Header, isActiveSection, fromWorkspace bool) string {
 	var check string
 	if h.Enabled {
 		check = enabledStyle.Render("[✓]")
@@ -729,11 +940,16 @@ func (m Model) renderHeaderRow(idx int, h config.Header, isActiveSection bool) s
 	}
 
 	key := h.Key
-	val := h.Value
+	val := secret.Describe(h.Value)
+
+	var tag string
+	if fromWorkspace {
+		tag = "  " + hintStyle.Render("workspace")
+	}
 
 	// Dynamic truncation: overhead is "  " + check + " " + key + "  " = ~8 + keyWidth
 	cw := m.contentWidth()
-	valMax := cw - 8 - lipgloss.Width(key)
+	valMax := cw - 8 - lipgloss.Width(key) - lipgloss.Width(tag)
 	if valMax < 10 {
 		valMax = 10
 	}
@@ -747,15 +963,19 @@ func (m Model) renderHeaderRow(idx int, h config.Header, isActiveSection bool) s
 			key = normalStyle.Render(key)
 			val = selectedStyle.Render(truncate(val, valMax))
 		}
-		return fmt.Sprintf("  %s %s  %s", check, key, val)
+		return fmt.Sprintf("  %s %s  %s", check, key, val) + tag
 	}
 
-	return fmt.Sprintf("  %s %s  %s", check, normalStyle.Render(key), dimStyle.Render(truncate(val, valMax)))
+	return fmt.Sprintf("  %s %s  %s", check, normalStyle.Render(key), dimStyle.Render(truncate(val, valMax))) + tag
 }
 
 func (m Model) renderEditLine() string {
 	label := m.editLabel()
-	return sectionTitle.Render(label) + m.input.View()
+	line := sectionTitle.Render(label) + m.input.View()
+	if m.editErr != "" {
+		line += "\n" + activeSectionTitle.Render(m.editErr)
+	}
+	return line
 }
 
 // editLabel returns the label for the current edit mode.
@@ -769,10 +989,18 @@ func (m Model) editLabel() string {
 		return "Endpoint: "
 	case ModeEditEnvVars:
 		return "Variables: "
+	case ModeEditEnvValues:
+		return "Values: "
+	case ModeEditEnvExtract:
+		return "Extract: "
+	case ModeEditEnvExtends:
+		return "Extends: "
 	case ModeEditKey:
 		return "Key: "
 	case ModeEditValue:
 		return "Value: "
+	case ModeEditConn:
+		return m.connField().label + ": "
 	}
 	return ""
 }
@@ -789,11 +1017,15 @@ func (m *Model) setInputWidth() {
 
 func (m Model) renderHints() string {
 	var hints []string
-	switch m.section {
-	case SectionEnvs:
-		hints = []string{"tab section", "j/k nav", "↵ select", "n new", "r rename", "e endpoint", "v vars", "d del", "esc close"}
+	switch {
+	case m.connOpen:
+		hints = []string{"j/k nav", "↵ edit", "space cycle", "d clear", "esc back"}
+	case m.section == SectionEnvs:
+		hints = []string{"tab section", "j/k nav", "↵ select", "n new", "r rename", "e endpoint", "v vars", "p values", "x extract", "i extends", "c connection", "m post/get/apq", "t ws/sse", "d del", "esc close"}
+	case m.isInheritedRow():
+		hints = []string{"tab section", "j/k nav", "↵ override", "space override toggled", "esc close"}
 	default:
-		hints = []string{"tab section", "j/k nav", "h/l col", "↵ edit", "a/n add", "d del", "space toggle", "esc close"}
+		hints = []string{"tab section", "j/k nav", "h/l col", "↵ edit", "a/n add", "d del", "space toggle", "s secrets file", "e encrypt", "esc close"}
 	}
 	return hintStyle.Render(strings.Join(hints, "  "))
 }
@@ -1,10 +1,15 @@
 package overlay
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/qraqula/qla/internal/auth"
 	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/secret"
 )
 
 func keyMsg(k string) tea.KeyPressMsg {
@@ -376,3 +381,351 @@ func searchString(s, sub string) bool {
 	}
 	return false
 }
+
+func TestToggleSubscriptions(t *testing.T) {
+	m := New()
+	cfg := testConfig()
+	m.Open(&cfg, 100, 40)
+
+	m, cmd := m.Update(keyMsg("t"))
+	if cfg.Environments[0].Subscriptions != config.SubscriptionsSSE {
+		t.Errorf("expected sse, got %q", cfg.Environments[0].Subscriptions)
+	}
+	if cmd == nil {
+		t.Error("expected ConfigChangedMsg cmd")
+	}
+
+	m.Update(keyMsg("t"))
+	if cfg.SubscriptionTransport() != config.SubscriptionsWS {
+		t.Errorf("expected ws after second toggle, got %q", cfg.SubscriptionTransport())
+	}
+}
+
+func TestCycleTransport(t *testing.T) {
+	m := New()
+	cfg := testConfig()
+	m.Open(&cfg, 100, 40)
+
+	want := []string{config.TransportGET, config.TransportAPQ, ""}
+	for _, w := range want {
+		m, _ = m.Update(keyMsg("m"))
+		if cfg.Environments[0].Transport != w {
+			t.Fatalf("expected %q, got %q", w, cfg.Environments[0].Transport)
+		}
+	}
+	m.Update(keyMsg("m"))
+	m.Update(keyMsg("m"))
+	if !strings.Contains(m.View(), "APQ") {
+		t.Error("expected APQ tag on the environment row")
+	}
+}
+
+func TestConnectionSettings(t *testing.T) {
+	m := New()
+	cfg := testConfig()
+	m.Open(&cfg, 100, 40)
+
+	m, _ = m.Update(keyMsg("c"))
+	if !strings.Contains(m.View(), "CONNECTION — dev") {
+		t.Fatal("expected connection settings for dev")
+	}
+
+	// Timeout is the first field
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.mode != ModeEditConn {
+		t.Fatalf("expected ModeEditConn, got %d", m.mode)
+	}
+	m.input.SetValue("soon")
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.mode != ModeEditConn {
+		t.Error("invalid timeout should keep the input open")
+	}
+	if !strings.Contains(m.View(), "invalid duration") {
+		t.Error("expected validation error in view")
+	}
+	m.input.SetValue("5s")
+	m, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.mode != ModeNormal || cfg.Environments[0].Timeout != "5s" {
+		t.Errorf("expected timeout 5s, got %q", cfg.Environments[0].Timeout)
+	}
+	if cmd == nil {
+		t.Error("expected ConfigChangedMsg cmd")
+	}
+
+	// Skip TLS verify toggles with space
+	for range 4 {
+		m, _ = m.Update(keyMsg("j"))
+	}
+	m, _ = m.Update(tea.KeyPressMsg{Code: ' ', Text: " "})
+	if !cfg.Environments[0].Insecure {
+		t.Error("expected insecure after toggle")
+	}
+	m, _ = m.Update(keyMsg("d"))
+	if cfg.Environments[0].Insecure {
+		t.Error("expected insecure cleared")
+	}
+
+	// esc returns to the environment list without closing the overlay
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if !m.IsOpen() || m.connOpen {
+		t.Error("esc should only leave the connection settings")
+	}
+}
+
+func TestEditEnvValues(t *testing.T) {
+	m := New()
+	cfg := testConfig()
+	m.Open(&cfg, 100, 40)
+
+	m, _ = m.Update(keyMsg("p"))
+	if m.mode != ModeEditEnvValues {
+		t.Fatalf("expected ModeEditEnvValues, got %d", m.mode)
+	}
+	m.input.SetValue(`{"token": 1}`)
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.mode != ModeEditEnvValues || !strings.Contains(m.View(), "JSON object of strings") {
+		t.Fatal("expected non-string values to be rejected")
+	}
+	m.input.SetValue(`{"token": "abc"}`)
+	m, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.mode != ModeNormal || cfg.Environments[0].Values["token"] != "abc" {
+		t.Errorf("expected token value stored, got %v", cfg.Environments[0].Values)
+	}
+	if cmd == nil {
+		t.Error("expected ConfigChangedMsg cmd")
+	}
+}
+
+func TestEditEnvExtract(t *testing.T) {
+	m := New()
+	cfg := testConfig()
+	m.Open(&cfg, 100, 40)
+
+	m, _ = m.Update(keyMsg("x"))
+	if m.mode != ModeEditEnvExtract {
+		t.Fatalf("expected ModeEditEnvExtract, got %d", m.mode)
+	}
+	m.input.SetValue("data.login.token")
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.mode != ModeEditEnvExtract || !strings.Contains(m.View(), "path -> name") {
+		t.Fatal("expected a rule without -> to be rejected")
+	}
+	m.input.SetValue("data.login.token -> authToken; data.login.user.id -> userId")
+	m, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	rules := cfg.Environments[0].Extract
+	if m.mode != ModeNormal || len(rules) != 2 || rules[0].Name != "authToken" || rules[1].Path != "data.login.user.id" {
+		t.Errorf("expected both rules stored, got %v", rules)
+	}
+	if cmd == nil {
+		t.Error("expected ConfigChangedMsg cmd")
+	}
+}
+
+func TestConnectionAuth(t *testing.T) {
+	m := New()
+	cfg := testConfig()
+	m.Open(&cfg, 100, 40)
+	m, _ = m.Update(keyMsg("c"))
+
+	// Auth follows Proxy
+	for range 6 {
+		m, _ = m.Update(keyMsg("j"))
+	}
+	if strings.Contains(m.View(), "Token URL") {
+		t.Error("expected no OAuth2 fields without auth")
+	}
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if a := cfg.Environments[0].Auth; a == nil || a.Type != auth.TypeClientCredentials {
+		t.Fatalf("expected client credentials, got %+v", a)
+	}
+	if strings.Contains(m.View(), "Username") || !strings.Contains(m.View(), "Token URL") {
+		t.Error("expected only the OAuth2 fields")
+	}
+
+	// Client secret is the third field after Auth
+	for range 3 {
+		m, _ = m.Update(keyMsg("j"))
+	}
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.mode != ModeEditConn || m.editLabel() != "Client secret: " {
+		t.Fatalf("expected client secret input, got mode %d label %q", m.mode, m.editLabel())
+	}
+	m.input.SetValue("hunter2")
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cfg.Environments[0].Auth.ClientSecret != "hunter2" {
+		t.Errorf("expected secret stored, got %q", cfg.Environments[0].Auth.ClientSecret)
+	}
+	if strings.Contains(m.View(), "hunter2") {
+		t.Error("expected the secret masked")
+	}
+
+	// Clearing the auth type hides its fields again
+	for range 3 {
+		m, _ = m.Update(keyMsg("k"))
+	}
+	m, _ = m.Update(keyMsg("d"))
+	if cfg.Environments[0].Auth != nil || strings.Contains(m.View(), "Token URL") {
+		t.Error("expected auth removed")
+	}
+}
+
+func TestProtectHeader(t *testing.T) {
+	m := New()
+	secrets := secret.NewResolver(t.TempDir())
+	m.SetSecrets(secrets)
+	cfg := testConfig()
+	m.Open(&cfg, 100, 40)
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyTab}) // dev headers
+
+	m, cmd := m.Update(keyMsg("s"))
+	if got := cfg.Environments[0].Headers[0].Value; got != "secret:dev.authorization" {
+		t.Fatalf("expected a secrets file reference, got %q", got)
+	}
+	if cmd == nil {
+		t.Error("expected ConfigChangedMsg cmd")
+	}
+	if v, err := secrets.Resolve("secret:dev.authorization"); err != nil || v != "Bearer dev" {
+		t.Errorf("expected the value in the secrets file, got %q, %v", v, err)
+	}
+	if !strings.Contains(m.View(), "secrets.json: dev.authorization") {
+		t.Error("expected the reference shown masked")
+	}
+
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyTab}) // global headers
+	t.Setenv(secret.PassphraseEnv, "")
+	m, _ = m.Update(keyMsg("e"))
+	if !strings.Contains(m.View(), "set QLA_PASSPHRASE") {
+		t.Error("expected a hint to set the passphrase")
+	}
+	t.Setenv(secret.PassphraseEnv, "pw")
+	m, _ = m.Update(keyMsg("e"))
+	got := cfg.GlobalHeaders[0].Value
+	if v, err := secret.Decrypt(got, "pw"); err != nil || v != "application/json" {
+		t.Errorf("expected an encrypted value, got %q (%v)", got, err)
+	}
+	if strings.Contains(m.View(), "application/json") || !strings.Contains(m.View(), "encrypted") {
+		t.Error("expected the encrypted value masked")
+	}
+}
+
+func TestWorkspaceValuesLocked(t *testing.T) {
+	wsDir := t.TempDir()
+	shared := `{"environments":[{"name":"dev","endpoint":"https://shared/graphql","timeout":"5s",` +
+		`"headers":[{"key":"X-Client","value":"qla","enabled":true}]}]}`
+	if err := os.WriteFile(filepath.Join(wsDir, "config.json"), []byte(shared), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := config.NewStore(t.TempDir())
+	store.Config = testConfig()
+	_ = store.Save()
+	_ = store.Load()
+	if err := store.TrustWorkspace(wsDir); err != nil {
+		t.Fatal(err)
+	}
+	cfg := store.Config
+
+	m := New()
+	m.Open(&cfg, 120, 40)
+	if !strings.Contains(m.View(), "workspace") {
+		t.Error("expected the workspace shown")
+	}
+
+	// The endpoint is shared, the variables are not.
+	m, _ = m.Update(keyMsg("e"))
+	if m.mode != ModeNormal || !strings.Contains(m.View(), "set by the workspace") {
+		t.Error("expected the shared endpoint locked")
+	}
+	m, _ = m.Update(keyMsg("v"))
+	if m.mode != ModeEditEnvVars {
+		t.Error("expected personal variables editable")
+	}
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m, _ = m.Update(keyMsg("d"))
+	if len(cfg.Environments) != 2 {
+		t.Error("expected a workspace environment not to be deleted")
+	}
+
+	// Shared header X-Client comes first, the personal Authorization after it.
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	m, _ = m.Update(tea.KeyPressMsg{Code: ' ', Text: " "})
+	if !cfg.Environments[0].Headers[0].Enabled {
+		t.Error("expected the shared header locked")
+	}
+	m, _ = m.Update(keyMsg("j"))
+	m, _ = m.Update(tea.KeyPressMsg{Code: ' ', Text: " "})
+	if cfg.Environments[0].Headers[1].Enabled {
+		t.Error("expected the personal header toggled")
+	}
+
+	// Connection fields follow the same rule.
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	m, _ = m.Update(keyMsg("c"))
+	m, _ = m.Update(keyMsg("d"))
+	if cfg.Environments[0].Timeout != "5s" {
+		t.Error("expected the shared timeout locked")
+	}
+}
+
+func TestEnvExtends(t *testing.T) {
+	cfg := testConfig()
+	cfg.Environments[0].Headers = append(cfg.Environments[0].Headers,
+		config.Header{Key: "X-Client", Value: "qla", Enabled: true})
+	cfg.ActiveEnv = "prod"
+	m := New()
+	m.Open(&cfg, 120, 40)
+
+	// prod (second row) extends dev
+	m, _ = m.Update(keyMsg("j"))
+	m, _ = m.Update(keyMsg("i"))
+	if m.mode != ModeEditEnvExtends {
+		t.Fatalf("expected ModeEditEnvExtends, got %d", m.mode)
+	}
+	m.input.SetValue("prod")
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.mode != ModeEditEnvExtends || !strings.Contains(m.View(), "cannot extend itself") {
+		t.Error("expected extending itself rejected")
+	}
+	m.input.SetValue("dev")
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cfg.Environments[1].Extends != "dev" || !strings.Contains(m.View(), "extends dev") {
+		t.Fatalf("expected prod to extend dev, got %q", cfg.Environments[1].Extends)
+	}
+
+	// dev can no longer extend prod, nor be deleted
+	m, _ = m.Update(keyMsg("k"))
+	m, _ = m.Update(keyMsg("i"))
+	m.input.SetValue("prod")
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if cfg.Environments[0].Extends != "" || !strings.Contains(m.View(), "cycle") {
+		t.Error("expected a cycle rejected")
+	}
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m, _ = m.Update(keyMsg("d"))
+	if len(cfg.Environments) != 2 || !strings.Contains(m.View(), "prod extends dev") {
+		t.Error("expected a parent environment not to be deleted")
+	}
+
+	// The inherited X-Client is listed after prod's own Authorization
+	m, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if !strings.Contains(m.View(), "from dev") {
+		t.Error("expected the inherited header shown")
+	}
+	m, _ = m.Update(keyMsg("j"))
+	m, _ = m.Update(keyMsg("d"))
+	if !strings.Contains(m.View(), "inherited from dev") {
+		t.Error("expected delete of an inherited header refused")
+	}
+	m, _ = m.Update(tea.KeyPressMsg{Code: ' ', Text: " "})
+	hdrs := cfg.Environments[1].Headers
+	if len(hdrs) != 2 || hdrs[1].Key != "X-Client" || hdrs[1].Enabled {
+		t.Errorf("expected a disabled override, got %+v", hdrs)
+	}
+	if _, ok := cfg.MergedHeaders()["X-Client"]; ok {
+		t.Error("expected the override to disable the inherited header")
+	}
+	if strings.Contains(m.View(), "from dev") {
+		t.Error("expected no inherited header left")
+	}
+}
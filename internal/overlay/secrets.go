@@ -0,0 +1,59 @@
+package overlay
+
+import (
+	"os"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/qraqula/qla/internal/secret"
+)
+
+// SetSecrets sets the resolver whose secrets file header values are moved to.
+func (m *Model) SetSecrets(r *secret.Resolver) {
+	m.secrets = r
+}
+
+// secretName names the secrets file entry of the selected header, such as
+// prod.authorization or global.x-api-key.
+func (m Model) secretName(key string) string {
+	scope := "global"
+	if m.section == SectionHeaders {
+		scope = m.config.ActiveEnv
+	}
+	return strings.ToLower(scope + "." + key)
+}
+
+// protectHeader replaces the selected header value with a secret reference:
+// an entry of the secrets file, or with encrypt an enc: value.
+func (m Model) protectHeader(encrypt bool) (Model, tea.Cmd) {
+	hdrs := m.currentHeaders()
+	if hdrs == nil || m.hdrCursor >= len(*hdrs) {
+		return m, nil
+	}
+	h := &(*hdrs)[m.hdrCursor]
+	if h.Value == "" || secret.IsRef(h.Value) {
+		return m, nil
+	}
+
+	var ref string
+	var err error
+	switch {
+	case encrypt:
+		pass := os.Getenv(secret.PassphraseEnv)
+		if pass == "" {
+			m.editErr = "set " + secret.PassphraseEnv + " to encrypt values"
+			return m, nil
+		}
+		ref, err = secret.Encrypt(h.Value, pass)
+	case m.secrets == nil:
+		return m, nil
+	default:
+		ref, err = m.secrets.Store(m.secretName(h.Key), h.Value)
+	}
+	if err != nil {
+		m.editErr = err.Error()
+		return m, nil
+	}
+	h.Value = ref
+	return m, m.emitChanged()
+}
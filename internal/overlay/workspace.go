@@ -0,0 +1,96 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// envKeyFields maps keys of the environment list to the field they edit;
+// "" stands for the environment as a whole.
+var envKeyFields = map[string]string{
+	"r": "",
+	"d": "",
+	"e": "endpoint",
+	"v": "variables",
+	"t": "subscriptions",
+	"m": "transport",
+	"p": "values",
+	"x": "extract",
+	"i": "extends",
+}
+
+// headerEditKeys are the keys that change the selected header.
+var headerEditKeys = []string{"enter", "d", "space", " ", "s", "e"}
+
+// workspaceLocked reports whether key would change something the workspace
+// defines, which the overlay leaves to the committed file, and says so.
+func (m *Model) workspaceLocked(key string) bool {
+	if m.config == nil || m.config.Workspace() == nil {
+		return false
+	}
+	var locked bool
+	switch m.section {
+	case SectionEnvs:
+		field, ok := envKeyFields[key]
+		env := m.connEnv()
+		if !ok || env == nil {
+			return false
+		}
+		if field == "" {
+			locked = m.config.WorkspaceEnv(env.Name)
+		} else {
+			locked = m.config.WorkspaceField(env.Name, field)
+		}
+	default:
+		if !slices.Contains(headerEditKeys, key) {
+			return false
+		}
+		locked = m.headerFromWorkspace()
+	}
+	if locked {
+		m.editErr = m.workspaceNotice()
+	}
+	return locked
+}
+
+// connLocked reports whether the workspace sets the connection field f of
+// the shown environment, and says so.
+func (m *Model) connLocked(f connField) bool {
+	env := m.connEnv()
+	if m.config.Workspace() == nil || env == nil || !m.config.WorkspaceField(env.Name, f.json) {
+		return false
+	}
+	m.editErr = m.workspaceNotice()
+	return true
+}
+
+// headerFromWorkspace reports whether the selected header is defined by the
+// workspace.
+func (m Model) headerFromWorkspace() bool {
+	hdrs := m.currentHeaders()
+	if hdrs == nil || m.hdrCursor >= len(*hdrs) {
+		return false
+	}
+	env := ""
+	if m.section == SectionHeaders {
+		env = m.config.ActiveEnv
+	}
+	return m.config.WorkspaceHeader(env, (*hdrs)[m.hdrCursor].Key)
+}
+
+func (m Model) workspaceNotice() string {
+	return "set by the workspace; edit " + filepath.Join(m.workspaceDir(), "config.json")
+}
+
+// workspaceDir returns the workspace directory relative to the working
+// directory when that is shorter.
+func (m Model) workspaceDir() string {
+	dir := m.config.Workspace().Dir
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, dir); err == nil && len(rel) < len(dir) {
+			return rel
+		}
+	}
+	return dir
+}
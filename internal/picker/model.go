@@ -0,0 +1,129 @@
+package picker
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// Messages returned to the parent app.
+type SelectMsg struct{ Index int }
+type CloseMsg struct{}
+
+var (
+	boxStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("196")).
+			Padding(1, 2)
+
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("196"))
+
+	selectedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+
+	normalStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252"))
+
+	hintStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+)
+
+// Model is a small modal list from which the user picks one item.
+type Model struct {
+	title   string
+	items   []string
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+func New() Model {
+	return Model{}
+}
+
+// Open shows the picker with items, preselecting index selected.
+func (m *Model) Open(title string, items []string, selected, w, h int) {
+	m.title = title
+	m.items = items
+	m.cursor = 0
+	if selected >= 0 && selected < len(items) {
+		m.cursor = selected
+	}
+	m.width = w
+	m.height = h
+	m.visible = true
+}
+
+func (m *Model) Close() {
+	m.visible = false
+}
+
+func (m Model) IsOpen() bool {
+	return m.visible
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	kmsg, ok := msg.(tea.KeyPressMsg)
+	if !ok || !m.visible {
+		return m, nil
+	}
+
+	switch k := kmsg.String(); k {
+	case "esc", "q":
+		m.Close()
+		return m, func() tea.Msg { return CloseMsg{} }
+	case "j", "down":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "k", "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "enter":
+		return m.pick(m.cursor)
+	default:
+		// 1-9 pick directly
+		if len(k) == 1 && k[0] >= '1' && k[0] <= '9' {
+			return m.pick(int(k[0] - '1'))
+		}
+	}
+	return m, nil
+}
+
+func (m Model) pick(idx int) (Model, tea.Cmd) {
+	if idx < 0 || idx >= len(m.items) {
+		return m, nil
+	}
+	m.Close()
+	return m, func() tea.Msg { return SelectMsg{Index: idx} }
+}
+
+// RenderOver draws the picker centered on the screen.
+func (m Model) RenderOver(background string) string {
+	if !m.visible {
+		return background
+	}
+
+	lines := []string{titleStyle.Render(m.title), ""}
+	for i, item := range m.items {
+		label := fmt.Sprintf("%d  %s", i+1, item)
+		if i == m.cursor {
+			lines = append(lines, selectedStyle.Render("▸ "+label))
+		} else {
+			lines = append(lines, normalStyle.Render("  "+label))
+		}
+	}
+	lines = append(lines, "", hintStyle.Render("j/k nav  ↵ run  1-9 pick  esc cancel"))
+
+	box := boxStyle.Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box,
+		lipgloss.WithWhitespaceChars(" "),
+	)
+}
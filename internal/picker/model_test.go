@@ -0,0 +1,58 @@
+package picker
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func keyMsg(k string) tea.KeyPressMsg {
+	return tea.KeyPressMsg{Code: -1, Text: k}
+}
+
+func TestPickWithEnter(t *testing.T) {
+	m := New()
+	m.Open("Run which operation?", []string{"query A", "query B", "mutation C"}, 0, 80, 24)
+
+	m, _ = m.Update(keyMsg("j"))
+	m, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.IsOpen() {
+		t.Error("expected picker to close after selection")
+	}
+	if cmd == nil {
+		t.Fatal("expected SelectMsg")
+	}
+	if sel, ok := cmd().(SelectMsg); !ok || sel.Index != 1 {
+		t.Errorf("expected index 1, got %#v", cmd())
+	}
+}
+
+func TestPickWithDigit(t *testing.T) {
+	m := New()
+	m.Open("Pick", []string{"a", "b", "c"}, 0, 80, 24)
+
+	if _, cmd := m.Update(keyMsg("9")); cmd != nil {
+		t.Error("out-of-range digit should be ignored")
+	}
+	_, cmd := m.Update(keyMsg("3"))
+	if sel, ok := cmd().(SelectMsg); !ok || sel.Index != 2 {
+		t.Errorf("expected index 2, got %#v", cmd())
+	}
+}
+
+func TestEscCancels(t *testing.T) {
+	m := New()
+	m.Open("Pick", []string{"a", "b"}, 1, 80, 24)
+	if !strings.Contains(m.RenderOver(""), "▸ 2  b") {
+		t.Error("expected preselected item to be highlighted")
+	}
+
+	m, cmd := m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.IsOpen() {
+		t.Error("expected picker to close on esc")
+	}
+	if _, ok := cmd().(CloseMsg); !ok {
+		t.Error("expected CloseMsg")
+	}
+}
@@ -0,0 +1,108 @@
+// Package poll re-runs a query at an interval and follows how its response
+// changes, until a condition on the response holds.
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/qraqula/qla/internal/jsondiff"
+	"github.com/qraqula/qla/internal/jsonpath"
+)
+
+// DefaultInterval is the interval of a spec that sets none.
+const DefaultInterval = 2 * time.Second
+
+// MinInterval is the shortest interval accepted.
+const MinInterval = 100 * time.Millisecond
+
+// Spec is how to poll: every Interval, until Until holds when set.
+type Spec struct {
+	Interval time.Duration
+	Until    *jsonpath.Condition
+}
+
+// ParseSpec parses "[every] 5s [until data.job.status == \"DONE\"]". The
+// interval may be left out.
+func ParseSpec(s string) (Spec, error) {
+	spec := Spec{Interval: DefaultInterval}
+	s = strings.TrimSpace(s)
+	interval, until, hasUntil := s, "", false
+	if i := strings.Index(" "+s+" ", " until "); i >= 0 {
+		interval, until, hasUntil = s[:i], s[min(i+len("until"), len(s)):], true
+	}
+	if hasUntil && strings.TrimSpace(until) == "" {
+		return Spec{}, errors.New("until: no condition")
+	}
+	interval = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(interval), "every"))
+	if interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return Spec{}, fmt.Errorf("invalid interval %q (use every 5s)", interval)
+		}
+		if d < MinInterval {
+			return Spec{}, fmt.Errorf("interval %s is shorter than %s", d, MinInterval)
+		}
+		spec.Interval = d
+	}
+	if hasUntil {
+		c, err := jsonpath.ParseCondition(until)
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.Until = &c
+	}
+	return spec, nil
+}
+
+// String formats s as ParseSpec reads it.
+func (s Spec) String() string {
+	out := "every " + s.Interval.String()
+	if s.Until != nil {
+		out += " until " + s.Until.String()
+	}
+	return out
+}
+
+// Poller follows the responses of a poll.
+type Poller struct {
+	spec    Spec
+	count   int
+	updates int
+	prev    any
+}
+
+// New returns a poller for spec.
+func New(spec Spec) *Poller {
+	return &Poller{spec: spec}
+}
+
+// Observe records the next response, a value decoded by encoding/json. It
+// returns the changes since the previous response and whether the stop
+// condition holds. A condition on a path the response lacks does not hold.
+func (p *Poller) Observe(v any) (changes []jsondiff.Change, done bool) {
+	p.count++
+	if p.count > 1 {
+		changes = jsondiff.Diff(p.prev, v)
+		if len(changes) > 0 {
+			p.updates++
+		}
+	}
+	p.prev = v
+	if p.spec.Until != nil {
+		done, _, _ = p.spec.Until.Eval(v)
+	}
+	return changes, done
+}
+
+// Spec returns the spec of the poll.
+func (p *Poller) Spec() Spec { return p.spec }
+
+// Count returns the number of responses observed.
+func (p *Poller) Count() int { return p.count }
+
+// Updates returns the number of responses that differed from the one
+// before.
+func (p *Poller) Updates() int { return p.updates }
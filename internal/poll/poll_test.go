@@ -0,0 +1,68 @@
+package poll
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		in, want, err string
+	}{
+		{in: "", want: "every 2s"},
+		{in: "5s", want: "every 5s"},
+		{in: "every 500ms", want: "every 500ms"},
+		{in: `every 1m until data.job.status == "DONE"`, want: `every 1m0s until data.job.status == "DONE"`},
+		{in: `until data.job.done == true`, want: `every 2s until data.job.done == true`},
+		{in: "every soon", err: `invalid interval "soon"`},
+		{in: "10ms", err: "shorter than 100ms"},
+		{in: "5s until", err: "no condition"},
+		{in: "5s until data.job", err: "invalid condition"},
+	}
+	for _, tt := range tests {
+		got, err := ParseSpec(tt.in)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseSpec(%q): expected error %q, got %v", tt.in, tt.err, err)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("ParseSpec(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if s, _ := ParseSpec("every 3s"); s.Interval != 3*time.Second || s.Until != nil {
+		t.Errorf("unexpected spec %+v", s)
+	}
+}
+
+func TestPoller(t *testing.T) {
+	spec, _ := ParseSpec(`1s until data.job.status == "DONE"`)
+	p := New(spec)
+	observe := func(s string) (int, bool) {
+		var v any
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatal(err)
+		}
+		changes, done := p.Observe(v)
+		return len(changes), done
+	}
+
+	if n, done := observe(`{"data": {"job": null}}`); n != 0 || done {
+		t.Errorf("expected no changes for the first response and a missing path not to hold, got %d %v", n, done)
+	}
+	if n, done := observe(`{"data": {"job": {"status": "RUNNING", "progress": 10}}}`); n != 1 || done {
+		t.Errorf("expected the job change, got %d %v", n, done)
+	}
+	if n, _ := observe(`{"data": {"job": {"status": "RUNNING", "progress": 10}}}`); n != 0 {
+		t.Errorf("expected no changes, got %d", n)
+	}
+	if n, done := observe(`{"data": {"job": {"status": "DONE", "progress": 100}}}`); n != 2 || !done {
+		t.Errorf("expected 2 changes and the condition met, got %d %v", n, done)
+	}
+	if p.Count() != 4 || p.Updates() != 2 {
+		t.Errorf("expected 4 responses with 2 updates, got %d %d", p.Count(), p.Updates())
+	}
+}
@@ -0,0 +1,198 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/interp"
+	"github.com/qraqula/qla/internal/secret"
+	"github.com/qraqula/qla/internal/validate"
+)
+
+// Spec describes a request to send with the active environment.
+type Spec struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any    // take precedence over the environment's
+	Endpoint      string            // defaults to the environment's
+	Headers       map[string]string // set over the configured headers, as is
+	Client        *graphql.Client   // reused when set, keeping cached tokens
+	Dynamic       map[string]string // {{$...}} values to replay rather than draw
+}
+
+// Prepared is a request ready to send.
+type Prepared struct {
+	Client   *graphql.Client
+	Endpoint string
+	Headers  map[string]string
+	Request  graphql.Request
+	Dynamic  map[string]string // {{$...}} values drawn for it
+
+	// What it was prepared from, for Redraw.
+	cfg     *config.Config
+	secrets *secret.Resolver
+	spec    Spec
+}
+
+// Prepare resolves spec against the active environment of cfg as the TUI
+// does when a query is run. Errors name the step that failed.
+func Prepare(cfg *config.Config, secrets *secret.Resolver, spec Spec) (*Prepared, error) {
+	return prepare(cfg.Clone(), secrets, spec)
+}
+
+// Redraw returns p prepared again with {{$...}} values drawn anew, to send
+// it once more as a new request, or p itself when it has none. It reads the
+// configuration as it was when p was prepared, so it is safe to call from
+// several goroutines at once.
+func (p *Prepared) Redraw() (*Prepared, error) {
+	if len(p.Dynamic) == 0 {
+		return p, nil
+	}
+	spec := p.spec
+	spec.Client = p.Client
+	spec.Dynamic = nil
+	return prepare(p.cfg, p.secrets, spec)
+}
+
+// ExecuteAgain sends p as a new request: Redraw then Execute. Benchmarks
+// send with it so that a create mutation does not repeat the ids of the one
+// before.
+func (p *Prepared) ExecuteAgain(ctx context.Context) (*graphql.Result, error) {
+	next, err := p.Redraw()
+	if err != nil {
+		return nil, err
+	}
+	return next.Execute(ctx)
+}
+
+func prepare(cfg *config.Config, secrets *secret.Resolver, spec Spec) (*Prepared, error) {
+	endpoint := spec.Endpoint
+	if endpoint == "" {
+		endpoint = cfg.Endpoint(cfg.ActiveEnv)
+	}
+	if endpoint == "" {
+		return nil, errors.New("no endpoint configured")
+	}
+
+	envVars, err := EnvVariables(cfg, spec.Query, spec.OperationName, spec.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("environment: %w", err)
+	}
+	vars := MergeVariables(envVars, spec.Variables)
+
+	// Dynamic values are drawn once for the endpoint, headers and variables.
+	dynamic := interp.NewDynamic()
+	dynamic.Seed(spec.Dynamic)
+	lookup := interp.Chain(cfg.Lookup(), dynamic.Lookup)
+	ep, headers, err := Target(cfg, secrets, endpoint, lookup)
+	if err != nil {
+		return nil, err
+	}
+	if vars != nil {
+		expanded, unresolved := interp.ExpandValue(vars, lookup)
+		if err := Unresolved(unresolved); err != nil {
+			return nil, fmt.Errorf("placeholders: %w", err)
+		}
+		vars = typed(expanded.(map[string]any), vars, validate.ScalarVariables(spec.Query, spec.OperationName))
+	}
+	for k, v := range spec.Headers {
+		maps.DeleteFunc(headers, func(h, _ string) bool { return strings.EqualFold(h, k) })
+		headers[k] = v
+	}
+
+	client := spec.Client
+	if client == nil {
+		if client, err = NewClient(cfg, secrets); err != nil {
+			return nil, fmt.Errorf("connection: %w", err)
+		}
+	}
+	return &Prepared{
+		Client:   client,
+		Endpoint: ep,
+		Headers:  headers,
+		Request: graphql.Request{
+			Query:         spec.Query,
+			OperationName: spec.OperationName,
+			Variables:     vars,
+		},
+		Dynamic: dynamic.Values(),
+		cfg:     cfg,
+		secrets: secrets,
+		spec:    spec,
+	}, nil
+}
+
+// typed gives the variables that types declares Int, Float or Boolean and
+// vars set to a lone placeholder, such as "{{$randomInt 1 6}}", the value it
+// expanded to as a number or boolean: a string can never satisfy them.
+// Placeholders inside input objects and lists stay strings.
+func typed(expanded, vars map[string]any, types map[string]string) map[string]any {
+	for name, typ := range types {
+		raw, ok := vars[name].(string)
+		if !ok || !validate.IsTypedScalar(typ) {
+			continue
+		}
+		if _, sole := interp.Sole(raw); !sole {
+			continue
+		}
+		s, _ := expanded[name].(string)
+		if !json.Valid([]byte(s)) {
+			continue
+		}
+		// As a json.Number, wide integers keep every digit.
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			continue
+		}
+		switch v.(type) {
+		case json.Number:
+			if typ != "Boolean" {
+				expanded[name] = v
+			}
+		case bool:
+			if typ == "Boolean" {
+				expanded[name] = v
+			}
+		}
+	}
+	return expanded
+}
+
+// Execute sends the request, collecting the parts of an @defer/@stream
+// response into the final merged one. Subscriptions are not supported.
+func (p *Prepared) Execute(ctx context.Context) (*graphql.Result, error) {
+	if !validate.UsesIncrementalDelivery(p.Request.Query) {
+		return p.Client.Execute(ctx, p.Endpoint, p.Request, p.Headers)
+	}
+	start := time.Now()
+	stream, err := p.Client.Stream(ctx, p.Endpoint, p.Request, p.Headers)
+	if err != nil {
+		return nil, err
+	}
+	var last graphql.Response
+	for {
+		ev, ok := stream.Next()
+		if !ok {
+			break
+		}
+		last = ev.Response
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+	return &graphql.Result{
+		Response:   last,
+		StatusCode: stream.StatusCode(),
+		Size:       stream.Size(),
+		Duration:   time.Since(start),
+	}, nil
+}
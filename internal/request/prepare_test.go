@@ -0,0 +1,194 @@
+package request
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/qraqula/qla/internal/auth"
+
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/secret"
+)
+
+func TestPrepare(t *testing.T) {
+	cfg := &config.Config{
+		ActiveEnv: "dev",
+		Environments: []config.Environment{{
+			Name:      "dev",
+			Endpoint:  "https://{{host}}/graphql",
+			Variables: `{"limit": 5, "unused": 1}`,
+			Values:    map[string]string{"host": "dev.example.com", "name": "ada"},
+			Headers:   []config.Header{{Key: "Authorization", Value: "Bearer dev", Enabled: true}},
+		}},
+	}
+	p, err := Prepare(cfg, secret.NewResolver(t.TempDir()), Spec{
+		Query:     `query Q($limit: Int, $name: String, $id: ID) { q }`,
+		Variables: map[string]any{"name": "{{name}}", "id": "{{$uuid}}"},
+		Headers:   map[string]string{"authorization": "Bearer override"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Endpoint != "https://dev.example.com/graphql" {
+		t.Errorf("unexpected endpoint %q", p.Endpoint)
+	}
+	if len(p.Headers) != 1 || p.Headers["authorization"] != "Bearer override" {
+		t.Errorf("expected the header replaced case-insensitively, got %v", p.Headers)
+	}
+	vars := p.Request.Variables
+	if vars["limit"] != float64(5) || vars["name"] != "ada" || vars["unused"] != nil {
+		t.Errorf("unexpected variables %v", vars)
+	}
+	if id := p.Dynamic["$uuid"]; id == "" || vars["id"] != id {
+		t.Errorf("expected the drawn uuid recorded, got %v and %v", vars["id"], p.Dynamic)
+	}
+
+	cfg.Environments[0].Values = nil
+	if _, err := Prepare(cfg, nil, Spec{Query: "{ q }"}); err == nil || !strings.HasPrefix(err.Error(), "placeholders: unresolved") {
+		t.Errorf("expected unresolved placeholders, got %v", err)
+	}
+
+	cfg.Environments[0].Extends = cfg.Environments[0].Name
+	if _, err := Prepare(cfg, nil, Spec{Query: "{ q }"}); err == nil || !strings.HasPrefix(err.Error(), "environment: extends cycle") {
+		t.Errorf("expected the cycle reported, got %v", err)
+	}
+}
+
+func TestPrepareKeepsExtractedRefsLiteral(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{"data":{"q":1}}`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "ran")
+	ref := "cmd:touch " + marker
+	cfg := &config.Config{
+		ActiveEnv: "dev",
+		Environments: []config.Environment{{
+			Name:     "dev",
+			Endpoint: srv.URL,
+			Headers: []config.Header{
+				{Key: "X-Token", Value: "{{token}}", Enabled: true},
+				{Key: "X-Configured", Value: "cmd:printf configured", Enabled: true},
+			},
+			Auth: &auth.Config{Type: auth.TypeBasic, Username: "ada", Password: "{{token}}"},
+		}},
+	}
+	// As if a response had been extracted into token.
+	cfg.SetExtracted("dev", map[string]string{"token": ref})
+
+	p, err := Prepare(cfg, secret.NewResolver(dir), Spec{Query: "{ q }"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Headers["X-Token"] != ref {
+		t.Errorf("expected the extracted value sent literally, got %q", p.Headers["X-Token"])
+	}
+	if p.Headers["X-Configured"] != "configured" {
+		t.Errorf("expected the configured reference resolved, got %q", p.Headers["X-Configured"])
+	}
+	if _, err := p.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Basic " + base64.StdEncoding.EncodeToString([]byte("ada:"+ref)); got.Get("Authorization") != want {
+		t.Errorf("expected the extracted password used literally, got %q", got.Get("Authorization"))
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Errorf("expected no command run, got %v", err)
+	}
+}
+
+func TestRedraw(t *testing.T) {
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+		w.Write([]byte(`{"data":{"q":1}}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		ActiveEnv: "dev",
+		Environments: []config.Environment{{
+			Name:     "dev",
+			Endpoint: srv.URL,
+			Headers:  []config.Header{{Key: "X-Request-ID", Value: "{{$uuid}}", Enabled: true}},
+		}},
+	}
+	p, err := Prepare(cfg, nil, Spec{Query: "{ q }", Dynamic: map[string]string{"$uuid": "replayed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Changes after the fact are not seen.
+	cfg.Environments[0].Endpoint = "http://127.0.0.1:0"
+	for range 2 {
+		if _, err := p.ExecuteAgain(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ids) != 2 || ids[0] == "replayed" || ids[0] == ids[1] {
+		t.Errorf("expected a new uuid for every request, got %q", ids)
+	}
+
+	cfg.Environments[0].Headers = nil
+	p, err = Prepare(cfg, nil, Spec{Query: "{ q }"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := p.Redraw(); again != p || err != nil {
+		t.Errorf("expected a request without dynamic values kept, got %v", err)
+	}
+}
+
+func TestPrepareTypesLonePlaceholders(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Variables map[string]any }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		got = req.Variables
+		w.Write([]byte(`{"data":{"q":1}}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		ActiveEnv: "dev",
+		Environments: []config.Environment{{
+			Name:     "dev",
+			Endpoint: srv.URL,
+			Values:   map[string]string{"on": "true", "zip": "01234", "code": "42"},
+		}},
+	}
+	p, err := Prepare(cfg, nil, Spec{
+		Query: `query Q($n: Int!, $f: Float, $on: Boolean, $code: String, $zip: Int, $label: Int) { q }`,
+		Variables: map[string]any{
+			"n": "{{$randomInt 1 6}}", "f": "{{code}}", "on": "{{on}}",
+			"code": "{{code}}", "zip": "{{zip}}", "label": "n{{code}}",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := got["n"].(float64); !ok || n < 1 || n > 6 {
+		t.Errorf("expected $n sent as a number in [1, 6], got %#v", got["n"])
+	}
+	if got["f"] != float64(42) || got["on"] != true {
+		t.Errorf("expected $f and $on typed, got %#v and %#v", got["f"], got["on"])
+	}
+	// Strings stay strings, as do values that are not numbers and text
+	// around a placeholder.
+	if got["code"] != "42" || got["zip"] != "01234" || got["label"] != "n42" {
+		t.Errorf("expected strings kept, got %v", got)
+	}
+}
@@ -0,0 +1,144 @@
+// Package request turns the configuration into requests the way the TUI
+// sends them, so headless commands share environments with it: the client
+// of the active environment, its endpoint and headers with placeholders and
+// secrets resolved, and the variables it supplies.
+package request
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/qraqula/qla/internal/auth"
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/interp"
+	"github.com/qraqula/qla/internal/secret"
+	"github.com/qraqula/qla/internal/validate"
+)
+
+// NewClient builds the GraphQL client for the active environment's
+// transport, connection settings and auth, applying extra last.
+func NewClient(cfg *config.Config, secrets *secret.Resolver, extra ...graphql.Option) (*graphql.Client, error) {
+	mode := graphql.ModePOST
+	switch cfg.Transport() {
+	case config.TransportGET:
+		mode = graphql.ModeGET
+	case config.TransportAPQ:
+		mode = graphql.ModeAPQ
+	}
+	opts := []graphql.Option{graphql.WithMode(mode)}
+
+	env := cfg.ActiveEnvironment()
+	if env == nil {
+		return graphql.NewClient(append(opts, extra...)...), nil
+	}
+	// The token endpoint is reached with the same connection settings.
+	tokenClient := &http.Client{Timeout: 30 * time.Second}
+	if env.Timeout != "" {
+		d, err := time.ParseDuration(env.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q", env.Timeout)
+		}
+		opts = append(opts, graphql.WithTimeout(d))
+		tokenClient.Timeout = d
+	}
+	if env.CACert != "" || env.ClientCert != "" || env.ClientKey != "" || env.Insecure || env.Proxy != "" {
+		t, err := graphql.NewTransport(graphql.TransportConfig{
+			CAFile:   env.CACert,
+			CertFile: env.ClientCert,
+			KeyFile:  env.ClientKey,
+			Insecure: env.Insecure,
+			Proxy:    env.Proxy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, graphql.WithTransport(t))
+		tokenClient.Transport = t
+	}
+	if env.Auth != nil && env.Auth.Type != "" {
+		// References are resolved as configured, before placeholders, so
+		// that no placeholder value is run as a cmd: reference.
+		authCfg, err := env.Auth.ResolveSecrets(secrets.Resolve)
+		if err != nil {
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+		authCfg, unresolved := authCfg.Expand(cfg.Lookup())
+		if err := Unresolved(unresolved); err != nil {
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+		var rotations auth.Rotations
+		if secrets != nil {
+			rotations = secrets
+		}
+		p, err := auth.New(authCfg, tokenClient, rotations)
+		if err != nil {
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+		opts = append(opts, graphql.WithAuth(p))
+	}
+	return graphql.NewClient(append(opts, extra...)...), nil
+}
+
+// Target returns endpoint and the active environment's headers with their
+// placeholders and secret references resolved, failing when any of them is
+// unknown. Only configured values are taken for references, never what a
+// placeholder expands to. Errors name the step that failed.
+func Target(cfg *config.Config, secrets *secret.Resolver, endpoint string, lookup interp.Lookup) (string, map[string]string, error) {
+	if _, err := cfg.Chain(cfg.ActiveEnv); err != nil {
+		return "", nil, fmt.Errorf("environment: %w", err)
+	}
+	ep, unresolved := interp.Expand(endpoint, lookup)
+	headers, more := cfg.ResolveHeaders(lookup)
+	if err := Unresolved(append(unresolved, more...)); err != nil {
+		return "", nil, fmt.Errorf("placeholders: %w", err)
+	}
+	refs, err := secrets.ResolveHeaders(cfg.SecretHeaders())
+	if err != nil {
+		return "", nil, fmt.Errorf("secrets: %w", err)
+	}
+	maps.Copy(headers, refs)
+	return ep, headers, nil
+}
+
+// EnvVariables returns the active environment's variables that the named
+// operation of query declares and vars does not set: vars take precedence.
+func EnvVariables(cfg *config.Config, query, operationName string, vars map[string]any) (map[string]any, error) {
+	all, err := cfg.EnvVariables()
+	if err != nil || len(all) == 0 {
+		return nil, err
+	}
+	env := make(map[string]any)
+	for _, name := range validate.VariableNames(query, operationName) {
+		if _, set := vars[name]; set {
+			continue
+		}
+		if v, ok := all[name]; ok {
+			env[name] = v
+		}
+	}
+	return env, nil
+}
+
+// MergeVariables returns vars with env added, without modifying either.
+func MergeVariables(env, vars map[string]any) map[string]any {
+	if len(env) == 0 {
+		return vars
+	}
+	merged := make(map[string]any, len(env)+len(vars))
+	maps.Copy(merged, env)
+	maps.Copy(merged, vars)
+	return merged
+}
+
+// Unresolved returns an error listing the placeholders in names, or nil.
+func Unresolved(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	slices.Sort(names)
+	return fmt.Errorf("unresolved %s", interp.Format(slices.Compact(names)))
+}
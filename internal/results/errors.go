@@ -0,0 +1,166 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/qraqula/qla/internal/graphql"
+)
+
+// Messages returned to the parent app by the errors view.
+type ShowPathMsg struct{ Path []any }
+type GotoLocationMsg struct{ Line, Column int }
+type CloseErrorsMsg struct{}
+
+var (
+	errSelectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	errMessageStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	errCodeStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	errPathStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+)
+
+// ErrorsView lists the errors of a GraphQL response with their code, path and
+// query location. Enter follows the path into the result, g jumps to the
+// location in the query editor.
+type ErrorsView struct {
+	errors []graphql.Error
+	cursor int
+	width  int
+	height int
+}
+
+// NewErrorsView returns an empty errors view.
+func NewErrorsView() ErrorsView {
+	return ErrorsView{}
+}
+
+// SetErrors replaces the listed errors.
+func (v *ErrorsView) SetErrors(errs []graphql.Error) {
+	v.errors = errs
+	if v.cursor >= len(errs) {
+		v.cursor = 0
+	}
+}
+
+// Count returns the number of listed errors.
+func (v ErrorsView) Count() int { return len(v.errors) }
+
+// Selected returns the error under the cursor.
+func (v ErrorsView) Selected() (graphql.Error, bool) {
+	if v.cursor >= len(v.errors) {
+		return graphql.Error{}, false
+	}
+	return v.errors[v.cursor], true
+}
+
+func (v *ErrorsView) SetSize(w, h int) {
+	v.width = w
+	v.height = h
+}
+
+func (v ErrorsView) Update(msg tea.Msg) (ErrorsView, tea.Cmd) {
+	kmsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return v, nil
+	}
+	switch kmsg.String() {
+	case "j", "down":
+		if v.cursor < len(v.errors)-1 {
+			v.cursor++
+		}
+	case "k", "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "enter", "p":
+		if e, ok := v.Selected(); ok && len(e.Path) > 0 {
+			return v, func() tea.Msg { return ShowPathMsg{Path: e.Path} }
+		}
+	case "g":
+		if e, ok := v.Selected(); ok && len(e.Locations) > 0 {
+			loc := e.Locations[0]
+			return v, func() tea.Msg { return GotoLocationMsg{Line: loc.Line, Column: loc.Column} }
+		}
+	case "esc", "e":
+		return v, func() tea.Msg { return CloseErrorsMsg{} }
+	}
+	return v, nil
+}
+
+func (v ErrorsView) View() string {
+	title := titleStyle.Render(" Errors ") + dimStyle.Render(fmt.Sprintf(" %d", len(v.errors)))
+	if len(v.errors) == 0 {
+		return title + "\n" + dimStyle.Render("  (no errors)")
+	}
+
+	w := v.width - 2
+	var lines []string
+	selStart, selEnd := 0, 0
+	for i, e := range v.errors {
+		if i == v.cursor {
+			selStart = len(lines)
+		}
+		lines = append(lines, v.renderError(i, e, w)...)
+		if i == v.cursor {
+			selEnd = len(lines)
+		}
+		lines = append(lines, "")
+	}
+
+	// Scroll so the selected error is fully visible.
+	visible := v.height - 3
+	if visible < 1 {
+		visible = 1
+	}
+	start := 0
+	if selEnd > visible {
+		start = selEnd - visible
+	}
+	if start > selStart {
+		start = selStart
+	}
+	end := min(start+visible, len(lines))
+	return title + "\n" + strings.Join(lines[start:end], "\n")
+}
+
+func (v ErrorsView) renderError(i int, e graphql.Error, w int) []string {
+	marker := "  "
+	msg := errMessageStyle.Render(e.Message)
+	if i == v.cursor {
+		marker = errSelectedStyle.Render("▸ ")
+		msg = errSelectedStyle.Render(e.Message)
+	}
+	head := marker
+	if code := e.Code(); code != "" {
+		head += errCodeStyle.Render(code) + " "
+	}
+	lines := []string{ansi.Truncate(head+msg, w, "…")}
+
+	var where []string
+	if p := e.PathString(); p != "" {
+		where = append(where, "at "+errPathStyle.Render(p))
+	}
+	for _, loc := range e.Locations {
+		where = append(where, dimStyle.Render(fmt.Sprintf("%d:%d", loc.Line, loc.Column)))
+	}
+	if len(where) > 0 {
+		lines = append(lines, ansi.Truncate("    "+strings.Join(where, "  "), w, "…"))
+	}
+
+	// Remaining extensions (the code is already in the header)
+	ext := make(map[string]any, len(e.Extensions))
+	for k, val := range e.Extensions {
+		if k != "code" && k != "classification" {
+			ext[k] = val
+		}
+	}
+	if len(ext) > 0 {
+		raw, _ := json.Marshal(ext)
+		lines = append(lines, ansi.Truncate(dimStyle.Render("    "+string(raw)), w, "…"))
+	}
+	return lines
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/bubbles/v2/textinput"
@@ -24,6 +25,13 @@ const (
 	matchBgOff = "\x1b[49m"
 )
 
+// ANSI code for changed value highlighting (blue background).
+const changeBgOn = "\x1b[44m"
+
+// PollMsg asks the parent app to poll the query as Spec describes, such as
+// "every 5s until data.job.status == \"DONE\"".
+type PollMsg struct{ Spec string }
+
 type matchPos struct {
 	line int
 	col  int
@@ -36,11 +44,20 @@ type Model struct {
 	rawContent         string
 	highlightedContent string
 
+	// Streaming state: number of events appended since the last SetContent/SetPrettyJSON
+	events int
+
+	// Shown after the title, such as the state of a poll
+	note string
+
 	searching   bool
 	searchQuery string
 	matches     []matchPos
 	matchIdx    int
 	searchInput textinput.Model
+
+	prompting bool
+	pollInput textinput.Model
 }
 
 func New(width, height int) Model {
@@ -50,10 +67,16 @@ func New(width, height int) Model {
 	si := textinput.New()
 	si.Placeholder = "search..."
 	si.CharLimit = 200
-	return Model{vp: vp, width: width, height: height, searchInput: si}
+	pi := textinput.New()
+	pi.Prompt = "poll "
+	pi.Placeholder = "every 2s until data.job.status == \"DONE\""
+	pi.CharLimit = 200
+	return Model{vp: vp, width: width, height: height, searchInput: si, pollInput: pi}
 }
 
 func (m *Model) SetContent(s string) {
+	m.events = 0
+	m.note = ""
 	m.rawContent = s
 	m.highlightedContent = s
 	m.vp.SetContent(s)
@@ -65,6 +88,8 @@ func (m *Model) SetPrettyJSON(data []byte) error {
 		return err
 	}
 	plain := buf.String()
+	m.events = 0
+	m.note = ""
 	m.rawContent = plain
 	highlighted := highlight.Colorize(plain, "json")
 	m.highlightedContent = highlighted
@@ -72,12 +97,76 @@ func (m *Model) SetPrettyJSON(data []byte) error {
 	return nil
 }
 
+// AppendEvent adds a streamed event below the previous ones, preceded by a
+// separator with its sequence number and receive time, and keeps the view
+// pinned to the bottom so new events stay visible.
+func (m *Model) AppendEvent(received time.Time, data []byte) {
+	var buf bytes.Buffer
+	plain := string(data)
+	if err := json.Indent(&buf, data, "", "  "); err == nil {
+		plain = buf.String()
+	}
+	m.events++
+	sep := fmt.Sprintf("── #%d  %s ──", m.events, received.Format("15:04:05.000"))
+
+	if m.events == 1 {
+		m.rawContent = ""
+		m.highlightedContent = ""
+	} else {
+		m.rawContent += "\n"
+		m.highlightedContent += "\n"
+	}
+	m.rawContent += sep + "\n" + plain
+	m.highlightedContent += dimStyle.Render(sep) + "\n" + highlight.Colorize(plain, "json")
+	m.updateViewportContent()
+	m.vp.GotoBottom()
+}
+
+// ScrollToPath scrolls to the node at path inside the response "data"
+// object, as reported by a GraphQL error. It returns false when the path
+// is not present in the shown response.
+func (m *Model) ScrollToPath(path []any) bool {
+	line, ok := pathLine(m.rawContent, append([]any{"data"}, path...))
+	if !ok {
+		return false
+	}
+	m.vp.SetYOffset(line)
+	return true
+}
+
+// MarkChanges highlights the lines of the shown JSON on which the values at
+// paths start, such as those that changed since the previous response, and
+// returns how many it found.
+func (m *Model) MarkChanges(paths [][]any) int {
+	lines := pathLines(m.rawContent, paths)
+	if len(lines) == 0 {
+		return 0
+	}
+	hLines := strings.Split(m.highlightedContent, "\n")
+	rLines := strings.Split(m.rawContent, "\n")
+	for _, i := range lines {
+		if i < len(hLines) && i < len(rLines) {
+			indent := len(rLines[i]) - len(strings.TrimLeft(rLines[i], " "))
+			hLines[i] = insertHighlights(hLines[i], [][2]int{{indent, len(rLines[i])}}, changeBgOn)
+		}
+	}
+	m.highlightedContent = strings.Join(hLines, "\n")
+	m.updateViewportContent()
+	return len(lines)
+}
+
+// SetNote sets a note shown after the title until the content is replaced.
+func (m *Model) SetNote(note string) { m.note = note }
+
+// EventCount returns the number of streamed events currently shown.
+func (m Model) EventCount() int { return m.events }
+
 func (m *Model) SetSize(w, h int) {
 	m.width = w
 	m.height = h
 	m.vp.SetWidth(w - 2)
 	searchH := 0
-	if m.searching {
+	if m.searching || m.prompting {
 		searchH = 1
 	}
 	m.vp.SetHeight(h - 3 - searchH)
@@ -191,6 +280,12 @@ func (m *Model) updateViewportContent() {
 // in an already-highlighted (ANSI-coded) line. ranges are byte positions in the
 // raw (uncolored) text; the function maps them through ANSI escape sequences.
 func insertMatchHighlights(highlighted string, ranges [][2]int) string {
+	return insertHighlights(highlighted, ranges, matchBgOn)
+}
+
+// insertHighlights adds the background bgOn around ranges, as
+// insertMatchHighlights does.
+func insertHighlights(highlighted string, ranges [][2]int, bgOn string) string {
 	if len(ranges) == 0 {
 		return highlighted
 	}
@@ -215,7 +310,7 @@ func insertMatchHighlights(highlighted string, ranges [][2]int) string {
 			buf.WriteString(highlighted[i:j])
 			// Re-apply our background after any ANSI code inside a match
 			if inMatch {
-				buf.WriteString(matchBgOn)
+				buf.WriteString(bgOn)
 			}
 			i = j
 			continue
@@ -223,7 +318,7 @@ func insertMatchHighlights(highlighted string, ranges [][2]int) string {
 
 		// Start match highlight
 		if !inMatch && ri < len(ranges) && visPos == ranges[ri][0] {
-			buf.WriteString(matchBgOn)
+			buf.WriteString(bgOn)
 			inMatch = true
 		}
 
@@ -238,7 +333,7 @@ func insertMatchHighlights(highlighted string, ranges [][2]int) string {
 			ri++
 			// Check if next range starts immediately
 			if ri < len(ranges) && visPos == ranges[ri][0] {
-				buf.WriteString(matchBgOn)
+				buf.WriteString(bgOn)
 				inMatch = true
 			}
 		}
@@ -251,7 +346,41 @@ func insertMatchHighlights(highlighted string, ranges [][2]int) string {
 	return buf.String()
 }
 
+// Prompting reports whether the poll prompt is open.
+func (m Model) Prompting() bool { return m.prompting }
+
+// StartPollPrompt opens the poll prompt with spec, the one used last.
+func (m *Model) StartPollPrompt(spec string) tea.Cmd {
+	m.prompting = true
+	m.pollInput.SetValue(spec)
+	m.pollInput.CursorEnd()
+	m.SetSize(m.width, m.height)
+	return m.pollInput.Focus()
+}
+
+func (m *Model) closePollPrompt() {
+	m.prompting = false
+	m.pollInput.Blur()
+	m.SetSize(m.width, m.height)
+}
+
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if m.prompting {
+		if kmsg, ok := msg.(tea.KeyPressMsg); ok {
+			switch kmsg.String() {
+			case "enter":
+				spec := m.pollInput.Value()
+				m.closePollPrompt()
+				return m, func() tea.Msg { return PollMsg{Spec: spec} }
+			case "esc":
+				m.closePollPrompt()
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		m.pollInput, cmd = m.pollInput.Update(msg)
+		return m, cmd
+	}
 	if m.searching {
 		if kmsg, ok := msg.(tea.KeyPressMsg); ok {
 			switch kmsg.String() {
@@ -291,6 +420,17 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 func (m Model) View() string {
 	title := titleStyle.Render(" Result ")
+	if m.events == 1 {
+		title += dimStyle.Render(" 1 event")
+	} else if m.events > 0 {
+		title += dimStyle.Render(fmt.Sprintf(" %d events", m.events))
+	}
+	if m.note != "" {
+		title += dimStyle.Render(" " + m.note)
+	}
+	if m.prompting {
+		return title + "\n" + m.pollInput.View() + "\n" + m.vp.View()
+	}
 	if m.searching {
 		searchLine := m.searchInput.View()
 		if len(m.matches) > 0 {
@@ -3,6 +3,7 @@ package results
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -193,3 +194,88 @@ func TestSearchHighlightsRemovedOnClose(t *testing.T) {
 		t.Error("expected search highlights removed after closing search")
 	}
 }
+
+func TestAppendEvent(t *testing.T) {
+	m := New(80, 20)
+	m.SetContent("Subscribing...")
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	m.AppendEvent(ts, []byte(`{"data":{"tick":1}}`))
+	if view := m.View(); !strings.Contains(view, "1 event") || strings.Contains(view, "1 events") {
+		t.Error("expected a single event in the title")
+	}
+	m.AppendEvent(ts.Add(time.Second), []byte(`{"data":{"tick":2}}`))
+
+	if m.EventCount() != 2 {
+		t.Errorf("expected 2 events, got %d", m.EventCount())
+	}
+	content := m.Content()
+	if strings.Contains(content, "Subscribing") {
+		t.Error("expected placeholder content to be replaced by the first event")
+	}
+	if !strings.Contains(content, "#1  15:04:05.000") || !strings.Contains(content, "#2  15:04:06.000") {
+		t.Errorf("expected numbered, timestamped separators, got:\n%s", content)
+	}
+	if !strings.Contains(m.View(), "2 events") {
+		t.Error("expected event count in title")
+	}
+
+	m.SetContent("reset")
+	if m.EventCount() != 0 {
+		t.Error("expected SetContent to reset the event count")
+	}
+}
+
+func TestScrollToPath(t *testing.T) {
+	m := New(80, 6)
+	m.SetSize(80, 6)
+	data := `{"data":{"user":{"name":"Ada","tags":["a","b"],"posts":[{"title":"x"},{"title":null}]}},"errors":[{"message":"boom"}]}`
+	if err := m.SetPrettyJSON([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(m.Content(), "\n")
+	line, ok := pathLine(m.Content(), []any{"data", "user", "posts", float64(1), "title"})
+	if !ok || !strings.Contains(lines[line], `"title": null`) {
+		t.Fatalf("expected line of posts[1].title, got %d (%v)", line, ok)
+	}
+	if line, ok := pathLine(m.Content(), []any{"data", "user", "tags", 1}); !ok || strings.TrimSpace(lines[line]) != `"b"` {
+		t.Errorf("expected line of tags[1], got %d", line)
+	}
+
+	if !m.ScrollToPath([]any{"user", "posts", float64(1), "title"}) {
+		t.Fatal("expected ScrollToPath to find the node")
+	}
+	if first := strings.Split(m.View(), "\n")[1]; !strings.Contains(first, "title") || !strings.Contains(first, "null") {
+		t.Errorf("expected failing node on the first visible line, got %q", first)
+	}
+	if m.ScrollToPath([]any{"user", "missing"}) {
+		t.Error("expected missing path to report false")
+	}
+}
+
+func TestMarkChanges(t *testing.T) {
+	m := New(80, 20)
+	if err := m.SetPrettyJSON([]byte(`{"data":{"job":{"status":"DONE","progress":100,"tags":["a","b"]}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	m.SetNote("poll #3")
+	n := m.MarkChanges([][]any{{"data", "job", "status"}, {"data", "job", "tags", 1}, {"data", "gone"}})
+	if n != 2 {
+		t.Fatalf("expected 2 lines marked, got %d", n)
+	}
+	lines := strings.Split(m.highlightedContent, "\n")
+	if !strings.Contains(lines[3], changeBgOn) || !strings.Contains(lines[7], changeBgOn) {
+		t.Errorf("expected the status and tags[1] lines marked:\n%s", m.highlightedContent)
+	}
+	if strings.Contains(lines[4], changeBgOn) {
+		t.Errorf("expected the progress line left alone: %q", lines[4])
+	}
+	if !strings.Contains(m.View(), "poll #3") {
+		t.Error("expected the note after the title")
+	}
+
+	m.SetContent("other")
+	if strings.Contains(m.View(), "poll #3") {
+		t.Error("expected the note cleared with the content")
+	}
+}
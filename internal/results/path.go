@@ -0,0 +1,128 @@
+package results
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pathFrame is an open object or array while walking indented JSON.
+type pathFrame struct {
+	path  []string
+	array bool
+	index int
+}
+
+// pathLine returns the 0-based line of pretty-printed JSON (as produced by
+// json.Indent) on which the value at path starts. Path elements are object
+// keys or array indexes.
+func pathLine(pretty string, path []any) (int, bool) {
+	target := pathKey(path)
+	found, ok := 0, false
+	walkLines(pretty, func(i int, path []string) bool {
+		if strings.Join(path, "\x00") == target {
+			found, ok = i, true
+			return false
+		}
+		return true
+	})
+	return found, ok
+}
+
+// pathLines returns the lines on which the values at paths start, in line
+// order, leaving out the paths that are not found.
+func pathLines(pretty string, paths [][]any) []int {
+	want := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		want[pathKey(p)] = true
+	}
+	var lines []int
+	walkLines(pretty, func(i int, path []string) bool {
+		if path != nil && want[strings.Join(path, "\x00")] {
+			lines = append(lines, i)
+		}
+		return true
+	})
+	return lines
+}
+
+func pathKey(path []any) string {
+	elems := make([]string, len(path))
+	for i, p := range path {
+		elems[i] = pathElem(p)
+	}
+	return strings.Join(elems, "\x00")
+}
+
+// walkLines calls fn with each line of pretty-printed JSON that starts a
+// value and the path of that value, nil for the root, until fn returns
+// false.
+func walkLines(pretty string, fn func(line int, path []string) bool) {
+	var stack []pathFrame
+	for i, line := range strings.Split(pretty, "\n") {
+		t := strings.TrimSpace(line)
+		if t == "" {
+			continue
+		}
+		if t[0] == '}' || t[0] == ']' {
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+
+		var cur []string
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			elem, ok := "", false
+			if top.array {
+				elem, ok = strconv.Itoa(top.index), true
+				top.index++
+			} else {
+				elem, ok = leadingKey(t)
+			}
+			if ok {
+				cur = append(append([]string{}, top.path...), elem)
+			}
+		}
+		if !fn(i, cur) {
+			return
+		}
+
+		t = strings.TrimSuffix(t, ",")
+		if strings.HasSuffix(t, "{") || strings.HasSuffix(t, "[") {
+			stack = append(stack, pathFrame{path: cur, array: strings.HasSuffix(t, "[")})
+		}
+	}
+}
+
+// leadingKey extracts the object key from a line such as `"name": "Ada",`.
+func leadingKey(line string) (string, bool) {
+	if !strings.HasPrefix(line, `"`) {
+		return "", false
+	}
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			key, err := strconv.Unquote(line[:i+1])
+			if err != nil || !strings.HasPrefix(line[i+1:], ":") {
+				return "", false
+			}
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func pathElem(p any) string {
+	switch v := p.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.Itoa(int(v))
+	case int:
+		return strconv.Itoa(v)
+	}
+	return ""
+}
@@ -103,6 +103,16 @@ type introspectionResponse struct {
 // endpoint using the provided client. It parses the response into a Schema,
 // filtering out built-in introspection types (those prefixed with "__").
 func FetchSchema(ctx context.Context, client *graphql.Client, endpoint string, headers map[string]string) (*Schema, error) {
+	data, err := FetchIntrospection(ctx, client, endpoint, headers)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIntrospection(data)
+}
+
+// FetchIntrospection sends the introspection query like FetchSchema and
+// returns the data of the response as is: {"__schema": {...}}.
+func FetchIntrospection(ctx context.Context, client *graphql.Client, endpoint string, headers map[string]string) (json.RawMessage, error) {
 	req := graphql.Request{
 		Query: IntrospectionQuery,
 	}
@@ -123,20 +133,36 @@ func FetchSchema(ctx context.Context, client *graphql.Client, endpoint string, h
 		}
 		return nil, fmt.Errorf("introspection errors: %s", strings.Join(msgs, "; "))
 	}
+	return result.Response.Data, nil
+}
 
-	var wrapper introspectionResponse
-	if err := json.Unmarshal(result.Response.Data, &wrapper); err != nil {
+// ParseIntrospection parses introspection JSON, either the data of the
+// response ({"__schema": ...}) or the whole response ({"data": ...}),
+// filtering out built-in introspection types.
+func ParseIntrospection(data []byte) (*Schema, error) {
+	var wrapper struct {
+		introspectionResponse
+		Data *introspectionResponse `json:"data"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
 		return nil, fmt.Errorf("unmarshal introspection response: %w", err)
 	}
+	s := wrapper.Schema
+	if wrapper.Data != nil {
+		s = wrapper.Data.Schema
+	}
+	if s.Types == nil {
+		return nil, fmt.Errorf("no __schema in introspection response")
+	}
 
 	// Filter out built-in introspection types (prefixed with "__").
-	filtered := make([]FullType, 0, len(wrapper.Schema.Types))
-	for _, t := range wrapper.Schema.Types {
+	filtered := make([]FullType, 0, len(s.Types))
+	for _, t := range s.Types {
 		if !strings.HasPrefix(t.Name, "__") {
 			filtered = append(filtered, t)
 		}
 	}
-	wrapper.Schema.Types = filtered
+	s.Types = filtered
 
-	return &wrapper.Schema, nil
+	return &s, nil
 }
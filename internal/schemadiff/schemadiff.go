@@ -0,0 +1,275 @@
+// Package schemadiff compares two GraphQL schemas and classifies each
+// change by its risk to existing clients, after graphql-js's
+// findBreakingChanges and findDangerousChanges.
+package schemadiff
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Level is the risk of a change to existing clients.
+type Level int
+
+const (
+	Safe      Level = iota // no client can break
+	Dangerous              // valid clients may behave differently, e.g. an unknown enum value
+	Breaking               // valid clients may fail, e.g. a removed field
+)
+
+func (l Level) String() string {
+	switch l {
+	case Breaking:
+		return "breaking"
+	case Dangerous:
+		return "dangerous"
+	}
+	return "safe"
+}
+
+// Change is a single difference between two schemas.
+type Change struct {
+	Level   Level
+	Path    string // the type, field or argument changed, e.g. Query.user(id)
+	Message string
+}
+
+func (c Change) String() string {
+	return c.Path + ": " + c.Message
+}
+
+// Diff returns the changes from old to new, the most severe first.
+func Diff(old, new *ast.Schema) []Change {
+	var d differ
+	for _, name := range typeNames(old) {
+		o := old.Types[name]
+		n, ok := new.Types[name]
+		switch {
+		case !ok:
+			d.add(Breaking, name, "%s removed", kindName(o.Kind))
+		case o.Kind != n.Kind:
+			d.add(Breaking, name, "changed from %s to %s", kindName(o.Kind), kindName(n.Kind))
+		default:
+			d.diffType(o, n)
+		}
+	}
+	for _, name := range typeNames(new) {
+		if _, ok := old.Types[name]; !ok {
+			d.add(Safe, name, "%s added", kindName(new.Types[name].Kind))
+		}
+	}
+	slices.SortStableFunc(d.changes, func(a, b Change) int {
+		return cmp.Or(cmp.Compare(b.Level, a.Level), cmp.Compare(a.Path, b.Path))
+	})
+	return d.changes
+}
+
+// Max returns the highest level of changes, Safe when there are none.
+func Max(changes []Change) Level {
+	level := Safe
+	for _, c := range changes {
+		level = max(level, c.Level)
+	}
+	return level
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(level Level, path, format string, args ...any) {
+	d.changes = append(d.changes, Change{Level: level, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *differ) diffType(o, n *ast.Definition) {
+	switch o.Kind {
+	case ast.Object, ast.Interface:
+		d.diffFields(o, n)
+		d.diffInterfaces(o, n)
+	case ast.InputObject:
+		d.diffInputFields(o, n)
+	case ast.Enum:
+		d.diffEnum(o, n)
+	case ast.Union:
+		d.diffUnion(o, n)
+	}
+}
+
+func (d *differ) diffFields(o, n *ast.Definition) {
+	for _, of := range o.Fields {
+		if strings.HasPrefix(of.Name, "__") {
+			continue
+		}
+		path := o.Name + "." + of.Name
+		nf := n.Fields.ForName(of.Name)
+		if nf == nil {
+			d.add(Breaking, path, "field removed")
+			continue
+		}
+		if !safeOutput(of.Type, nf.Type) {
+			d.add(Breaking, path, "type changed from %s to %s", of.Type, nf.Type)
+		} else if of.Type.String() != nf.Type.String() {
+			d.add(Safe, path, "type changed from %s to %s", of.Type, nf.Type)
+		}
+		d.diffArgs(path, of.Arguments, nf.Arguments)
+	}
+	for _, nf := range n.Fields {
+		if o.Fields.ForName(nf.Name) == nil && !strings.HasPrefix(nf.Name, "__") {
+			d.add(Safe, o.Name+"."+nf.Name, "field added")
+		}
+	}
+}
+
+func (d *differ) diffArgs(field string, o, n ast.ArgumentDefinitionList) {
+	for _, oa := range o {
+		path := field + "(" + oa.Name + ")"
+		na := n.ForName(oa.Name)
+		if na == nil {
+			d.add(Breaking, path, "argument removed")
+			continue
+		}
+		if !safeInput(oa.Type, na.Type) {
+			d.add(Breaking, path, "type changed from %s to %s", oa.Type, na.Type)
+		} else if oa.Type.String() != na.Type.String() {
+			d.add(Safe, path, "type changed from %s to %s", oa.Type, na.Type)
+		}
+		if oa.DefaultValue != nil && (na.DefaultValue == nil || oa.DefaultValue.String() != na.DefaultValue.String()) {
+			d.add(Dangerous, path, "default value changed from %s to %s", oa.DefaultValue, valueString(na.DefaultValue))
+		}
+	}
+	for _, na := range n {
+		if o.ForName(na.Name) != nil {
+			continue
+		}
+		path := field + "(" + na.Name + ")"
+		if na.Type.NonNull && na.DefaultValue == nil {
+			d.add(Breaking, path, "required argument added (%s)", na.Type)
+		} else {
+			d.add(Dangerous, path, "optional argument added (%s)", na.Type)
+		}
+	}
+}
+
+func (d *differ) diffInputFields(o, n *ast.Definition) {
+	for _, of := range o.Fields {
+		path := o.Name + "." + of.Name
+		nf := n.Fields.ForName(of.Name)
+		if nf == nil {
+			d.add(Breaking, path, "input field removed")
+			continue
+		}
+		if !safeInput(of.Type, nf.Type) {
+			d.add(Breaking, path, "type changed from %s to %s", of.Type, nf.Type)
+		} else if of.Type.String() != nf.Type.String() {
+			d.add(Safe, path, "type changed from %s to %s", of.Type, nf.Type)
+		}
+	}
+	for _, nf := range n.Fields {
+		if o.Fields.ForName(nf.Name) != nil {
+			continue
+		}
+		path := o.Name + "." + nf.Name
+		if nf.Type.NonNull && nf.DefaultValue == nil {
+			d.add(Breaking, path, "required input field added (%s)", nf.Type)
+		} else {
+			d.add(Dangerous, path, "optional input field added (%s)", nf.Type)
+		}
+	}
+}
+
+func (d *differ) diffEnum(o, n *ast.Definition) {
+	for _, v := range o.EnumValues {
+		if n.EnumValues.ForName(v.Name) == nil {
+			d.add(Breaking, o.Name+"."+v.Name, "enum value removed")
+		}
+	}
+	for _, v := range n.EnumValues {
+		if o.EnumValues.ForName(v.Name) == nil {
+			d.add(Dangerous, o.Name+"."+v.Name, "enum value added")
+		}
+	}
+}
+
+func (d *differ) diffUnion(o, n *ast.Definition) {
+	for _, t := range o.Types {
+		if !slices.Contains(n.Types, t) {
+			d.add(Breaking, o.Name, "member %s removed", t)
+		}
+	}
+	for _, t := range n.Types {
+		if !slices.Contains(o.Types, t) {
+			d.add(Dangerous, o.Name, "member %s added", t)
+		}
+	}
+}
+
+func (d *differ) diffInterfaces(o, n *ast.Definition) {
+	for _, i := range o.Interfaces {
+		if !slices.Contains(n.Interfaces, i) {
+			d.add(Breaking, o.Name, "no longer implements %s", i)
+		}
+	}
+	for _, i := range n.Interfaces {
+		if !slices.Contains(o.Interfaces, i) {
+			d.add(Dangerous, o.Name, "now implements %s", i)
+		}
+	}
+}
+
+// safeOutput reports whether a field of type o may become n: clients still
+// get what they expect when a value only becomes non-null.
+func safeOutput(o, n *ast.Type) bool {
+	if o.NonNull && !n.NonNull {
+		return false
+	}
+	if o.Elem != nil {
+		return n.Elem != nil && safeOutput(o.Elem, n.Elem)
+	}
+	return n.Elem == nil && n.NamedType == o.NamedType
+}
+
+// safeInput reports whether an argument or input field of type o may become
+// n: values clients send stay valid when a type only becomes nullable.
+func safeInput(o, n *ast.Type) bool {
+	if n.NonNull && !o.NonNull {
+		return false
+	}
+	if o.Elem != nil {
+		return n.Elem != nil && safeInput(o.Elem, n.Elem)
+	}
+	return n.Elem == nil && n.NamedType == o.NamedType
+}
+
+// typeNames returns the names of the types of s that are not built in,
+// sorted.
+func typeNames(s *ast.Schema) []string {
+	var names []string
+	for name, def := range s.Types {
+		if !def.BuiltIn && !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func kindName(k ast.DefinitionKind) string {
+	switch k {
+	case ast.Object:
+		return "type"
+	case ast.InputObject:
+		return "input"
+	}
+	return strings.ToLower(string(k))
+}
+
+func valueString(v *ast.Value) string {
+	if v == nil {
+		return "none"
+	}
+	return v.String()
+}
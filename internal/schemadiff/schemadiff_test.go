@@ -0,0 +1,75 @@
+package schemadiff
+
+import (
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func load(t *testing.T, sdl string) *ast.Schema {
+	t.Helper()
+	s, err := gqlparser.LoadSchema(&ast.Source{Input: sdl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDiff(t *testing.T) {
+	old := load(t, `
+		type Query { user(id: ID!): User, users(first: Int = 10): [User], legacy: String }
+		type User { id: ID!, name: String, email: String }
+		enum Role { ADMIN, EDITOR, VIEWER }
+		input UserFilter { name: String, role: Role! }
+		union Result = User
+		scalar Gone
+	`)
+	new := load(t, `
+		type Query { user(id: ID!, tenant: String!): User, users(first: Int = 20, after: String): [User!] }
+		type User { id: ID!, name: String!, email: Int, age: Int }
+		enum Role { ADMIN, VIEWER, AUDITOR }
+		input UserFilter { name: String, role: Role, active: Boolean! }
+		type Admin { id: ID! }
+		union Result = User | Admin
+	`)
+
+	want := map[string]Level{
+		"Gone: scalar removed":                                     Breaking,
+		"Query.legacy: field removed":                              Breaking,
+		"Query.user(tenant): required argument added (String!)":    Breaking,
+		"Role.EDITOR: enum value removed":                          Breaking,
+		"User.email: type changed from String to Int":              Breaking,
+		"UserFilter.active: required input field added (Boolean!)": Breaking,
+		"Query.users(first): default value changed from 10 to 20":  Dangerous,
+		"Query.users(after): optional argument added (String)":     Dangerous,
+		"Role.AUDITOR: enum value added":                           Dangerous,
+		"Result: member Admin added":                               Dangerous,
+		"Query.users: type changed from [User] to [User!]":         Safe,
+		"User.name: type changed from String to String!":           Safe,
+		"UserFilter.role: type changed from Role! to Role":         Safe,
+		"User.age: field added":                                    Safe,
+		"Admin: type added":                                        Safe,
+	}
+	changes := Diff(old, new)
+	got := make(map[string]Level, len(changes))
+	for _, c := range changes {
+		got[c.String()] = c.Level
+	}
+	for msg, level := range want {
+		if l, ok := got[msg]; !ok || l != level {
+			t.Errorf("expected %s change %q, got %v (present: %v)", level, msg, l, ok)
+		}
+	}
+	if len(changes) != len(want) {
+		t.Errorf("expected %d changes, got %d: %v", len(want), len(changes), changes)
+	}
+	for i := 1; i < len(changes); i++ {
+		if changes[i].Level > changes[i-1].Level {
+			t.Fatalf("changes not sorted by level: %v", changes)
+		}
+	}
+	if Max(changes) != Breaking || Max(nil) != Safe {
+		t.Error("unexpected Max")
+	}
+}
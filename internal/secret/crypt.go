@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+const (
+	encVersion = "v1:"
+	saltSize   = 16
+	iterations = 600_000 // OWASP recommendation for PBKDF2-HMAC-SHA256
+)
+
+// Encrypt encrypts plain with a key derived from passphrase and returns an
+// enc: reference: AES-256-GCM with a random salt and nonce.
+func Encrypt(plain, passphrase string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(append(salt, nonce...), nonce, []byte(plain), nil)
+	return PrefixEnc + encVersion + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt.
+func Decrypt(ref, passphrase string) (string, error) {
+	payload, ok := strings.CutPrefix(ref, PrefixEnc+encVersion)
+	if !ok {
+		return "", errors.New("unsupported enc: value")
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(raw) < saltSize {
+		return "", errors.New("malformed enc: value")
+	}
+	gcm, err := newGCM(passphrase, raw[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	rest := raw[saltSize:]
+	if len(rest) < gcm.NonceSize() {
+		return "", errors.New("malformed enc: value")
+	}
+	plain, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("wrong passphrase or corrupted enc: value")
+	}
+	return string(plain), nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,88 @@
+package secret
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const fileName = "secrets.json"
+
+// ReadFile reads a secrets file: a JSON object of names to values. It
+// refuses files other users can read. A missing file holds no secrets.
+func ReadFile(path string) (map[string]string, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("%s is accessible by other users; run chmod 600 %s", fileName, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var secrets map[string]string
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON", fileName)
+	}
+	return secrets, nil
+}
+
+// Store saves value under name in the secrets file, creating it with mode
+// 0600, and returns the reference to use in its place.
+func (r *Resolver) Store(name, value string) (string, error) {
+	path := r.Path()
+	secrets, err := ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if secrets == nil {
+		secrets = make(map[string]string)
+	}
+	secrets[name] = value
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", err
+	}
+	return PrefixFile + name, nil
+}
+
+// Rotated returns the refresh token saved by SaveRotated for the configured
+// one.
+func (r *Resolver) Rotated(configured string) (string, bool) {
+	secrets, err := ReadFile(r.Path())
+	token, ok := secrets[rotatedName(configured)]
+	return token, err == nil && ok
+}
+
+// SaveRotated saves token, a refresh token issued in place of the configured
+// one, in the secrets file.
+func (r *Resolver) SaveRotated(configured, token string) error {
+	_, err := r.Store(rotatedName(configured), token)
+	return err
+}
+
+// rotatedName names the rotated refresh token of configured in the secrets
+// file. A new configured token starts afresh.
+func rotatedName(configured string) string {
+	sum := sha256.Sum256([]byte(configured))
+	return "refreshToken." + hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,172 @@
+// Package secret resolves secret references in header values so tokens need
+// not be stored in config.json:
+//
+//	cmd:pass show api/prod   output of a shell command
+//	secret:prod.token        entry of the 0600 secrets.json file
+//	enc:v1:...               value encrypted with the QLA_PASSPHRASE passphrase
+//
+// References are resolved in memory when a request is built; the resolved
+// values are never written back.
+package secret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reference prefixes.
+const (
+	PrefixCmd  = "cmd:"
+	PrefixFile = "secret:"
+	PrefixEnc  = "enc:"
+)
+
+// PassphraseEnv names the environment variable holding the passphrase of
+// enc: values.
+const PassphraseEnv = "QLA_PASSPHRASE"
+
+// cmdTimeout bounds a cmd: reference so a hanging command cannot block the UI.
+const cmdTimeout = 10 * time.Second
+
+// IsRef reports whether v is a secret reference.
+func IsRef(v string) bool {
+	return strings.HasPrefix(v, PrefixCmd) || strings.HasPrefix(v, PrefixFile) || strings.HasPrefix(v, PrefixEnc)
+}
+
+// Describe returns a masked rendering of the reference v that tells where
+// the value comes from without revealing it.
+func Describe(v string) string {
+	switch {
+	case strings.HasPrefix(v, PrefixCmd):
+		return "•••••••• cmd: " + strings.TrimSpace(strings.TrimPrefix(v, PrefixCmd))
+	case strings.HasPrefix(v, PrefixFile):
+		return "•••••••• " + fileName + ": " + strings.TrimPrefix(v, PrefixFile)
+	case strings.HasPrefix(v, PrefixEnc):
+		return "•••••••• encrypted"
+	}
+	return v
+}
+
+// Resolver resolves references. Command output and decrypted values are
+// cached for the session, since commands such as pass may prompt and key
+// derivation is deliberately slow.
+type Resolver struct {
+	dir string // holds secrets.json
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// NewResolver returns a Resolver reading secrets.json from dir.
+func NewResolver(dir string) *Resolver {
+	return &Resolver{dir: dir}
+}
+
+// Path returns the path of the secrets file.
+func (r *Resolver) Path() string {
+	return filepath.Join(r.dir, fileName)
+}
+
+// Forget drops cached command output so commands run again.
+func (r *Resolver) Forget() {
+	r.mu.Lock()
+	r.cache = nil
+	r.mu.Unlock()
+}
+
+// Resolve returns the value v refers to, or v itself when it is not a
+// reference.
+func (r *Resolver) Resolve(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, PrefixCmd):
+		return r.cached(v, func() (string, error) {
+			return command(strings.TrimSpace(strings.TrimPrefix(v, PrefixCmd)))
+		})
+	case strings.HasPrefix(v, PrefixFile):
+		name := strings.TrimPrefix(v, PrefixFile)
+		secrets, err := ReadFile(r.Path())
+		if err != nil {
+			return "", err
+		}
+		val, ok := secrets[name]
+		if !ok {
+			return "", fmt.Errorf("no secret %q in %s", name, fileName)
+		}
+		return val, nil
+	case strings.HasPrefix(v, PrefixEnc):
+		pass, ok := os.LookupEnv(PassphraseEnv)
+		if !ok || pass == "" {
+			return "", fmt.Errorf("set %s to decrypt enc: values", PassphraseEnv)
+		}
+		return r.cached(v, func() (string, error) { return Decrypt(v, pass) })
+	}
+	return v, nil
+}
+
+// ResolveHeaders returns headers with every reference resolved, leaving
+// headers itself unmodified.
+func (r *Resolver) ResolveHeaders(headers map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(headers))
+	for k, v := range headers {
+		val, err := r.Resolve(v)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", k, err)
+		}
+		out[k] = val
+	}
+	return out, nil
+}
+
+// cached returns the cached value of ref, calling resolve on a miss.
+func (r *Resolver) cached(ref string, resolve func() (string, error)) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.cache[ref]; ok {
+		return v, nil
+	}
+	v, err := resolve()
+	if err != nil {
+		return "", err
+	}
+	if r.cache == nil {
+		r.cache = make(map[string]string)
+	}
+	r.cache[ref] = v
+	return v, nil
+}
+
+// command runs command with the shell and returns its output.
+func command(command string) (string, error) {
+	if command == "" {
+		return "", errors.New("empty cmd: reference")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", command, msg)
+		}
+		return "", fmt.Errorf("%s: %w", command, err)
+	}
+	// Like pass, most tools end the secret with a newline.
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
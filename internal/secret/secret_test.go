@@ -0,0 +1,121 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestResolveCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "runs")
+	r := NewResolver(dir)
+	ref := "cmd: echo run >> " + counter + "; printf 'tok\\n'"
+
+	for range 2 {
+		if got, err := r.Resolve(ref); err != nil || got != "tok" {
+			t.Fatalf("unexpected %q, %v", got, err)
+		}
+	}
+	runs, _ := os.ReadFile(counter)
+	if strings.Count(string(runs), "run") != 1 {
+		t.Errorf("expected the output cached, got %d runs", strings.Count(string(runs), "run"))
+	}
+	r.Forget()
+	_, _ = r.Resolve(ref)
+	runs, _ = os.ReadFile(counter)
+	if strings.Count(string(runs), "run") != 2 {
+		t.Error("expected the command to run again after Forget")
+	}
+
+	if _, err := r.Resolve("cmd: echo denied >&2; exit 1"); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected stderr in error, got %v", err)
+	}
+}
+
+func TestSecretsFile(t *testing.T) {
+	r := NewResolver(t.TempDir())
+	if _, err := r.Resolve("secret:prod.token"); err == nil {
+		t.Error("expected error for a missing secret")
+	}
+	ref, err := r.Store("prod.token", "s3cr3t")
+	if err != nil || ref != "secret:prod.token" {
+		t.Fatalf("unexpected %q, %v", ref, err)
+	}
+	if _, err := r.Store("dev.token", "d3v"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.Resolve(ref); err != nil || got != "s3cr3t" {
+		t.Errorf("unexpected %q, %v", got, err)
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+	info, _ := os.Stat(r.Path())
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	_ = os.Chmod(r.Path(), 0o644)
+	if _, err := r.Resolve(ref); err == nil || !strings.Contains(err.Error(), "chmod 600") {
+		t.Errorf("expected a world-readable file to be refused, got %v", err)
+	}
+}
+
+func TestRotatedRefreshToken(t *testing.T) {
+	r := NewResolver(t.TempDir())
+	if _, ok := r.Rotated("rt-0"); ok {
+		t.Error("expected no rotated token yet")
+	}
+	if err := r.SaveRotated("rt-0", "rt-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := r.Rotated("rt-0"); !ok || got != "rt-1" {
+		t.Errorf("unexpected %q, %v", got, ok)
+	}
+	if _, ok := r.Rotated("rt-new"); ok {
+		t.Error("expected a new configured token to start afresh")
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	ref, err := Encrypt("Bearer abc", "correct horse")
+	if err != nil || !strings.HasPrefix(ref, "enc:v1:") || strings.Contains(ref, "abc") {
+		t.Fatalf("unexpected %q, %v", ref, err)
+	}
+	if got, err := Decrypt(ref, "correct horse"); err != nil || got != "Bearer abc" {
+		t.Errorf("unexpected %q, %v", got, err)
+	}
+	if _, err := Decrypt(ref, "wrong"); err == nil {
+		t.Error("expected error for a wrong passphrase")
+	}
+
+	r := NewResolver(t.TempDir())
+	t.Setenv(PassphraseEnv, "")
+	if _, err := r.Resolve(ref); err == nil || !strings.Contains(err.Error(), PassphraseEnv) {
+		t.Errorf("expected passphrase error, got %v", err)
+	}
+	t.Setenv(PassphraseEnv, "correct horse")
+	headers, err := r.ResolveHeaders(map[string]string{"Authorization": ref, "X-Plain": "1"})
+	if err != nil || headers["Authorization"] != "Bearer abc" || headers["X-Plain"] != "1" {
+		t.Errorf("unexpected %v, %v", headers, err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := map[string]string{
+		"cmd:pass show api/prod": "•••••••• cmd: pass show api/prod",
+		"secret:prod.token":      "•••••••• secrets.json: prod.token",
+		"enc:v1:AAAA":            "•••••••• encrypted",
+		"Bearer abc":             "Bearer abc",
+	}
+	for in, want := range tests {
+		if got := Describe(in); got != want {
+			t.Errorf("Describe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -37,16 +37,27 @@ func (m *Model) SetWidth(w int) {
 	m.width = w
 }
 
-func (m *Model) SetResult(statusCode int, duration time.Duration, size int, hasErrors bool) {
+// SetResult shows the response metadata. A zero ttfb (streams, tests) is
+// left out.
+func (m *Model) SetResult(statusCode int, duration, ttfb time.Duration, size int, hasErrors bool) {
 	status := fmt.Sprintf("%d", statusCode)
+	timing := formatDuration(duration)
+	if ttfb > 0 {
+		timing += " (TTFB " + formatDuration(ttfb) + ")"
+	}
 	if hasErrors {
 		status += " (with errors)"
-		m.text = warnStyle.Render(status) + barStyle.Render(fmt.Sprintf("  %s  %s", formatDuration(duration), formatSize(size)))
+		m.text = warnStyle.Render(status) + barStyle.Render(fmt.Sprintf("  %s  %s", timing, formatSize(size)))
 	} else {
-		m.text = okStyle.Render(status) + barStyle.Render(fmt.Sprintf("  %s  %s", formatDuration(duration), formatSize(size)))
+		m.text = okStyle.Render(status) + barStyle.Render(fmt.Sprintf("  %s  %s", timing, formatSize(size)))
 	}
 }
 
+// AddNote appends a short annotation to the current status text.
+func (m *Model) AddNote(note string) {
+	m.text += barStyle.Render("  " + note)
+}
+
 func (m *Model) SetError(msg string) {
 	m.text = errStyle.Render("Error: " + msg)
 }
@@ -63,6 +74,22 @@ func (m *Model) SetAborted() {
 	m.text = warnStyle.Render("Query aborted")
 }
 
+// SetStreaming shows a running event count while a subscription is open.
+func (m *Model) SetStreaming(events int) {
+	m.text = okStyle.Render("Subscribed") + barStyle.Render(fmt.Sprintf("  %s  ^c to stop", plural(events, "event")))
+}
+
+// SetStreamClosed shows the final event count once a subscription has ended.
+func (m *Model) SetStreamClosed(events int) {
+	m.text = barStyle.Render("Subscription closed  " + plural(events, "event"))
+}
+
+// SetReceiving shows progress while an @defer/@stream response is still
+// delivering parts.
+func (m *Model) SetReceiving(parts int) {
+	m.text = okStyle.Render("Receiving") + barStyle.Render(fmt.Sprintf("  %s  ^c to stop", plural(parts, "part")))
+}
+
 func (m *Model) SetSchemaLoading() {
 	m.text = barStyle.Render("Fetching schema...")
 }
@@ -110,6 +137,13 @@ func (m Model) renderHints() string {
 	return strings.Join(parts, "  ")
 }
 
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Second {
 		return fmt.Sprintf("%dms", d.Milliseconds())
@@ -16,7 +16,7 @@ func TestNewEmpty(t *testing.T) {
 
 func TestSetResult(t *testing.T) {
 	m := New()
-	m.SetResult(200, 142*time.Millisecond, 3200, false)
+	m.SetResult(200, 142*time.Millisecond, 0, 3200, false)
 	view := m.View()
 	if !strings.Contains(view, "200") {
 		t.Errorf("expected '200' in view, got %q", view)
@@ -26,9 +26,17 @@ func TestSetResult(t *testing.T) {
 	}
 }
 
+func TestSetResultWithTTFB(t *testing.T) {
+	m := New()
+	m.SetResult(200, 142*time.Millisecond, 95*time.Millisecond, 3200, false)
+	if view := m.View(); !strings.Contains(view, "142ms (TTFB 95ms)") {
+		t.Errorf("expected TTFB in view, got %q", view)
+	}
+}
+
 func TestSetResultWithErrors(t *testing.T) {
 	m := New()
-	m.SetResult(200, 100*time.Millisecond, 500, true)
+	m.SetResult(200, 100*time.Millisecond, 0, 500, true)
 	view := m.View()
 	if !strings.Contains(view, "with errors") {
 		t.Errorf("expected 'with errors' in view, got %q", view)
@@ -83,3 +91,32 @@ func TestSetHintsEmpty(t *testing.T) {
 		t.Errorf("expected default hint 'execute' in view, got %q", view)
 	}
 }
+
+func TestSetStreaming(t *testing.T) {
+	m := New()
+	m.SetStreaming(1)
+	if view := m.View(); !strings.Contains(view, "1 event") || !strings.Contains(view, "Subscribed") {
+		t.Errorf("expected streaming status, got %q", view)
+	}
+	m.SetStreamClosed(4)
+	if view := m.View(); !strings.Contains(view, "closed") || !strings.Contains(view, "4 events") {
+		t.Errorf("expected closed status, got %q", view)
+	}
+}
+
+func TestSetReceiving(t *testing.T) {
+	m := New()
+	m.SetReceiving(2)
+	if view := m.View(); !strings.Contains(view, "Receiving") || !strings.Contains(view, "2 parts") {
+		t.Errorf("expected receiving status, got %q", view)
+	}
+}
+
+func TestAddNote(t *testing.T) {
+	m := New()
+	m.SetResult(200, 0, 0, 10, false)
+	m.AddNote("APQ hit")
+	if view := m.View(); !strings.Contains(view, "200") || !strings.Contains(view, "APQ hit") {
+		t.Errorf("expected result with note, got %q", view)
+	}
+}
@@ -0,0 +1,153 @@
+// Package suite runs the entries of a history folder in order as a test
+// suite, checking each response against the assertions of its query:
+//
+//	# qla:assert status 200
+//	# qla:assert noerrors
+//	# qla:assert data.user.name == "Ada"
+//	# qla:assert duration < 500ms
+//
+// An entry without assertions passes when the HTTP status is 2xx and the
+// response has no errors.
+package suite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/jsonpath"
+)
+
+// Kind is what an assertion checks.
+type Kind int
+
+const (
+	Status      Kind = iota // the HTTP status code
+	NoErrors                // the response has no GraphQL errors
+	Equals                  // a value of the response, by JSONPath
+	MaxDuration             // the time the request took
+)
+
+// Assertion is a single check of a response.
+type Assertion struct {
+	Kind      Kind
+	Status    int
+	Condition jsonpath.Condition
+	Max       time.Duration
+}
+
+func (a Assertion) String() string {
+	switch a.Kind {
+	case Status:
+		return "status " + strconv.Itoa(a.Status)
+	case NoErrors:
+		return "noerrors"
+	case MaxDuration:
+		return "duration < " + a.Max.String()
+	}
+	return a.Condition.String()
+}
+
+// Parse parses an assertion: "status 200", "noerrors", "duration < 500ms"
+// or a condition such as "data.user.name == \"Ada\"".
+func Parse(s string) (Assertion, error) {
+	s = strings.TrimSpace(s)
+	fields := strings.Fields(s)
+	switch {
+	case len(fields) == 0:
+		return Assertion{}, errors.New("empty assertion")
+	case fields[0] == "status":
+		if len(fields) != 2 {
+			return Assertion{}, fmt.Errorf("invalid assertion %q (use status 200)", s)
+		}
+		code, err := strconv.Atoi(fields[1])
+		if err != nil || code < 100 || code > 599 {
+			return Assertion{}, fmt.Errorf("invalid status %q", fields[1])
+		}
+		return Assertion{Kind: Status, Status: code}, nil
+	case s == "noerrors" || s == "no errors":
+		return Assertion{Kind: NoErrors}, nil
+	case fields[0] == "duration" || strings.HasPrefix(fields[0], "duration<"):
+		limit := strings.TrimLeft(strings.TrimSpace(strings.TrimPrefix(s, "duration")), "<=")
+		d, err := time.ParseDuration(strings.TrimSpace(limit))
+		if err != nil || d <= 0 {
+			return Assertion{}, fmt.Errorf("invalid assertion %q (use duration < 500ms)", s)
+		}
+		return Assertion{Kind: MaxDuration, Max: d}, nil
+	}
+	c, err := jsonpath.ParseCondition(s)
+	if err != nil {
+		return Assertion{}, err
+	}
+	return Assertion{Kind: Equals, Condition: c}, nil
+}
+
+var directiveRE = regexp.MustCompile(`(?m)^\s*#\s*qla:assert\s+(.*)$`)
+
+// FromQuery returns the assertions of the # qla:assert comments in query.
+func FromQuery(query string) ([]Assertion, error) {
+	var asserts []Assertion
+	for _, m := range directiveRE.FindAllStringSubmatch(query, -1) {
+		a, err := Parse(m[1])
+		if err != nil {
+			return nil, err
+		}
+		asserts = append(asserts, a)
+	}
+	return asserts, nil
+}
+
+// Check returns why r fails the assertion, or nil when it passes.
+func (a Assertion) Check(r *graphql.Result) error {
+	switch a.Kind {
+	case Status:
+		if r.StatusCode != a.Status {
+			return fmt.Errorf("status %d", r.StatusCode)
+		}
+	case NoErrors:
+		if r.RawBody != nil {
+			return errors.New("response is not JSON")
+		}
+		if n := len(r.Response.Errors); n > 0 {
+			return fmt.Errorf("%d errors: %s", n, r.Response.Errors[0].Message)
+		}
+	case MaxDuration:
+		if r.Duration > a.Max {
+			return fmt.Errorf("took %s", r.Duration.Round(time.Millisecond))
+		}
+	case Equals:
+		if r.RawBody != nil {
+			return errors.New("response is not JSON")
+		}
+		var root any
+		raw, _ := json.Marshal(r.Response)
+		if err := json.Unmarshal(raw, &root); err != nil {
+			return err
+		}
+		ok, got, err := a.Condition.Eval(root)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			value, _ := json.Marshal(got)
+			return fmt.Errorf("got %s", value)
+		}
+	}
+	return nil
+}
+
+// defaultCheck is the check of entries without assertions.
+func defaultCheck(r *graphql.Result) error {
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return fmt.Errorf("status %d", r.StatusCode)
+	}
+	if r.RawBody != nil {
+		return errors.New("response is not JSON")
+	}
+	return Assertion{Kind: NoErrors}.Check(r)
+}
@@ -0,0 +1,86 @@
+package suite
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/qraqula/qla/internal/graphql"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"status 201":                "status 201",
+		"noerrors":                  "noerrors",
+		"no errors":                 "noerrors",
+		"duration < 500ms":          "duration < 500ms",
+		"duration<=2s":              "duration < 2s",
+		`data.user.name == "Ada"`:   `data.user.name == "Ada"`,
+		"data.users[0].id != 7":     "data.users[0].id != 7",
+		`errors[0].message == boom`: `errors[0].message == boom`,
+	}
+	for in, want := range tests {
+		a, err := Parse(in)
+		if err != nil || a.String() != want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", in, a, err, want)
+		}
+	}
+	for _, bad := range []string{"", "status", "status ok", "status 42", "duration", "duration < soon", "data.user"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	asserts, err := FromQuery("# qla:assert status 200\nquery Q {\n  # qla:assert data.q == 1\n  q\n}")
+	if err != nil || len(asserts) != 2 || asserts[0].Kind != Status || asserts[1].Kind != Equals {
+		t.Fatalf("unexpected %v, %v", asserts, err)
+	}
+	if _, err := FromQuery("# qla:assert status two hundred"); err == nil {
+		t.Error("expected error for an invalid assertion")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	r := &graphql.Result{
+		StatusCode: 200,
+		Duration:   300 * time.Millisecond,
+		Response: graphql.Response{
+			Data:   json.RawMessage(`{"user":{"name":"Ada","age":36}}`),
+			Errors: []graphql.Error{{Message: "partial"}},
+		},
+	}
+	tests := map[string]string{
+		"status 200":                   "",
+		"status 201":                   "status 200",
+		"noerrors":                     "1 errors: partial",
+		"duration < 1s":                "",
+		"duration < 100ms":             "took 300ms",
+		`data.user.name == "Ada"`:      "",
+		"data.user.age == 37":          "got 36",
+		"data.user.email == x":         "data.user.email not found",
+		`errors[0].message == partial`: "",
+	}
+	for in, want := range tests {
+		a, err := Parse(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := ""
+		if err := a.Check(r); err != nil {
+			got = err.Error()
+		}
+		if got != want {
+			t.Errorf("%s: got %q, want %q", in, got, want)
+		}
+	}
+
+	html := &graphql.Result{StatusCode: 502, RawBody: []byte("<html>Bad Gateway</html>")}
+	for _, in := range []string{"noerrors", "data.user.name == x"} {
+		a, _ := Parse(in)
+		if err := a.Check(html); err == nil || err.Error() != "response is not JSON" {
+			t.Errorf("%s: expected a non-JSON response to fail, got %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package suite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/qraqula/qla/internal/history"
+)
+
+// CloseMsg is returned to the parent app when the report is left.
+type CloseMsg struct{}
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	passStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	failStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+	nameStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+)
+
+// Report shows the progress and outcome of a suite run: one row per entry
+// with its status and timing, and the failed checks below it. Enter loads
+// the entry under the cursor into the editor.
+type Report struct {
+	folder  string
+	env     string
+	entries []history.Entry
+	results []Result
+	start   time.Time
+	running bool
+	aborted bool
+	cursor  int
+	width   int
+	height  int
+}
+
+// NewReport returns an empty report.
+func NewReport() Report {
+	return Report{}
+}
+
+// Start resets the report for a run of entries, the folder's in run order,
+// against env.
+func (r *Report) Start(folder, env string, entries []history.Entry) {
+	*r = Report{
+		folder:  folder,
+		env:     env,
+		entries: entries,
+		start:   time.Now(),
+		running: true,
+		width:   r.width,
+		height:  r.height,
+	}
+}
+
+// Add records the result of the next entry.
+func (r *Report) Add(res Result) {
+	r.results = append(r.results, res)
+	r.cursor = len(r.results) - 1
+	if len(r.results) == len(r.entries) {
+		r.running = false
+	}
+}
+
+// Stop ends the run; aborted marks the entries left as not run.
+func (r *Report) Stop(aborted bool) {
+	r.running = false
+	r.aborted = aborted
+}
+
+// Next returns the entry to run next.
+func (r Report) Next() (history.Entry, bool) {
+	if !r.running || len(r.results) >= len(r.entries) {
+		return history.Entry{}, false
+	}
+	return r.entries[len(r.results)], true
+}
+
+// Running reports whether the run is in progress.
+func (r Report) Running() bool { return r.running }
+
+// HasRun reports whether there is a run to show.
+func (r Report) HasRun() bool { return r.folder != "" }
+
+// Folder returns the name of the folder run.
+func (r Report) Folder() string { return r.folder }
+
+// Started returns when the run started.
+func (r Report) Started() time.Time { return r.start }
+
+// Results returns the results so far, in run order.
+func (r Report) Results() []Result { return r.results }
+
+func (r *Report) SetSize(w, h int) {
+	r.width = w
+	r.height = h
+}
+
+func (r Report) Update(msg tea.Msg) (Report, tea.Cmd) {
+	kmsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return r, nil
+	}
+	switch kmsg.String() {
+	case "j", "down":
+		if r.cursor < len(r.entries)-1 {
+			r.cursor++
+		}
+	case "k", "up":
+		if r.cursor > 0 {
+			r.cursor--
+		}
+	case "enter":
+		if r.cursor < len(r.entries) {
+			e := r.entries[r.cursor]
+			return r, func() tea.Msg { return history.LoadEntryMsg{Entry: e} }
+		}
+	case "esc":
+		return r, func() tea.Msg { return CloseMsg{} }
+	}
+	return r, nil
+}
+
+func (r Report) View() string {
+	title := titleStyle.Render(" Suite ")
+	if !r.HasRun() {
+		return title + "\n" + dimStyle.Render("  (no suite run yet)")
+	}
+	title += " " + nameStyle.Render(r.folder)
+	if r.env != "" {
+		title += dimStyle.Render(" on " + r.env)
+	}
+
+	w := r.width - 2
+	var lines []string
+	selStart, selEnd := 0, 0
+	for i, e := range r.entries {
+		if i == r.cursor {
+			selStart = len(lines)
+		}
+		lines = append(lines, r.renderRow(i, e, w)...)
+		if i == r.cursor {
+			selEnd = len(lines)
+		}
+	}
+
+	// Scroll so the selected row is fully visible.
+	visible := r.height - 3
+	if visible < 1 {
+		visible = 1
+	}
+	start := 0
+	if selEnd > visible {
+		start = selEnd - visible
+	}
+	if start > selStart {
+		start = selStart
+	}
+	end := min(start+visible, len(lines))
+	return title + "\n" + strings.Join(lines[start:end], "\n") + "\n" + r.summary()
+}
+
+func (r Report) renderRow(i int, e history.Entry, w int) []string {
+	marker := "  "
+	name := nameStyle.Render(e.Name)
+	if i == r.cursor {
+		marker = selectedStyle.Render("▸ ")
+		name = selectedStyle.Render(e.Name)
+	}
+	if i >= len(r.results) {
+		state := "·"
+		if r.running && i == len(r.results) {
+			state = "…"
+		}
+		return []string{ansi.Truncate(marker+dimStyle.Render(state+" ")+name, w, "…")}
+	}
+
+	res := r.results[i]
+	icon := passStyle.Render("✓")
+	if !res.Passed() {
+		icon = failStyle.Render("✗")
+	}
+	detail := fmt.Sprintf("  %s", res.Duration.Round(time.Millisecond))
+	if res.Status != 0 {
+		detail = fmt.Sprintf("  %d%s", res.Status, detail)
+	}
+	lines := []string{ansi.Truncate(marker+icon+" "+name+dimStyle.Render(detail), w, "…")}
+	for _, f := range res.Failures() {
+		lines = append(lines, ansi.Truncate("    "+failStyle.Render(f), w, "…"))
+	}
+	return lines
+}
+
+func (r Report) summary() string {
+	passed, failed, total := Summary(r.results)
+	s := fmt.Sprintf("%d passed, %d failed", passed, failed)
+	if left := len(r.entries) - len(r.results); left > 0 {
+		if r.running {
+			s += fmt.Sprintf(", %d to go", left)
+		} else if r.aborted {
+			s += fmt.Sprintf(", %d not run", left)
+		}
+	}
+	s += " · " + total.Round(time.Millisecond).String()
+	if failed > 0 {
+		return failStyle.Render(s)
+	}
+	if !r.running && passed == len(r.entries) {
+		return passStyle.Render(s)
+	}
+	return dimStyle.Render(s)
+}
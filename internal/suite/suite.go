@@ -0,0 +1,220 @@
+package suite
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/extract"
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/history"
+	"github.com/qraqula/qla/internal/request"
+	"github.com/qraqula/qla/internal/secret"
+	"github.com/qraqula/qla/internal/validate"
+)
+
+// Check is the outcome of one assertion.
+type Check struct {
+	Assertion string
+	Err       error // why it failed, nil when it passed
+}
+
+// Result is the outcome of one entry.
+type Result struct {
+	Entry    history.Entry
+	Status   int
+	Duration time.Duration
+	Checks   []Check
+	Err      error // the request could not be sent or failed
+}
+
+// Passed reports whether the request succeeded and every check passed.
+func (r Result) Passed() bool {
+	return r.Err == nil && !slices.ContainsFunc(r.Checks, func(c Check) bool { return c.Err != nil })
+}
+
+// Failures lists the failed checks, or the error of the request.
+func (r Result) Failures() []string {
+	if r.Err != nil {
+		return []string{r.Err.Error()}
+	}
+	var out []string
+	for _, c := range r.Checks {
+		if c.Err != nil {
+			out = append(out, c.Assertion+": "+c.Err.Error())
+		}
+	}
+	return out
+}
+
+// Order returns the entries of a folder in the order a suite runs them: the
+// order they were saved in, oldest first.
+func Order(entries []history.Entry) []history.Entry {
+	ordered := slices.Clone(entries)
+	slices.SortStableFunc(ordered, func(a, b history.Entry) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return ordered
+}
+
+// Runner runs entries against the active environment of its configuration.
+// The values extract rules pick out of a response are kept for the entries
+// that follow but never saved.
+type Runner struct {
+	cfg     *config.Config
+	secrets *secret.Resolver
+	client  *graphql.Client
+}
+
+// New returns a runner for the active environment of a copy of cfg. The
+// entries share client, or a client built for the environment when nil.
+func New(cfg *config.Config, secrets *secret.Resolver, client *graphql.Client) (*Runner, error) {
+	own := cfg.Clone()
+	if client == nil {
+		var err error
+		if client, err = request.NewClient(own, secrets); err != nil {
+			return nil, fmt.Errorf("connection: %w", err)
+		}
+	}
+	return &Runner{cfg: own, secrets: secrets, client: client}, nil
+}
+
+// Run runs entries in order, calling each, when set, with every result. It
+// stops early when ctx is cancelled.
+func (r *Runner) Run(ctx context.Context, entries []history.Entry, each func(Result)) []Result {
+	var results []Result
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		res := r.RunEntry(ctx, e)
+		results = append(results, res)
+		if each != nil {
+			each(res)
+		}
+	}
+	return results
+}
+
+// RunEntry sends one entry and checks its response. Without an active
+// environment the entry's own endpoint is used.
+func (r *Runner) RunEntry(ctx context.Context, e history.Entry) Result {
+	res := Result{Entry: e}
+	asserts, err := FromQuery(e.Query)
+	if err != nil {
+		res.Err = fmt.Errorf("assert: %w", err)
+		return res
+	}
+	rules, err := r.extractRules(e.Query)
+	if err != nil {
+		res.Err = fmt.Errorf("extract: %w", err)
+		return res
+	}
+	op, err := operation(e.Query)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	var vars map[string]any
+	if strings.TrimSpace(e.Variables) != "" {
+		if err := json.Unmarshal([]byte(e.Variables), &vars); err != nil {
+			res.Err = fmt.Errorf("invalid variables JSON: %w", err)
+			return res
+		}
+	}
+	endpoint := ""
+	if r.cfg.ActiveEnvironment() == nil {
+		endpoint = e.Endpoint
+	}
+	p, err := request.Prepare(r.cfg, r.secrets, request.Spec{
+		Query:         e.Query,
+		OperationName: op,
+		Variables:     vars,
+		Endpoint:      endpoint,
+		Client:        r.client,
+	})
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	start := time.Now()
+	result, err := p.Execute(ctx)
+	if err != nil {
+		res.Err = err
+		res.Duration = time.Since(start)
+		return res
+	}
+	res.Status = result.StatusCode
+	res.Duration = cmp.Or(result.Duration, time.Since(start))
+	result.Duration = res.Duration
+
+	if len(asserts) == 0 {
+		res.Checks = []Check{{Assertion: "status 2xx, noerrors", Err: defaultCheck(result)}}
+	}
+	for _, a := range asserts {
+		res.Checks = append(res.Checks, Check{Assertion: a.String(), Err: a.Check(result)})
+	}
+	if result.RawBody == nil {
+		r.applyExtract(rules, result.Response.Data)
+	}
+	return res
+}
+
+// operation returns the operation of query to run, failing for documents
+// with several and for subscriptions.
+func operation(query string) (string, error) {
+	ops := validate.Operations(query)
+	switch {
+	case len(ops) > 1:
+		return "", errors.New("the document has several operations; keep one per entry")
+	case len(ops) == 1 && ops[0].Type == "subscription":
+		return "", errors.New("subscriptions are not supported")
+	case len(ops) == 1:
+		return ops[0].Name, nil
+	}
+	return "", nil
+}
+
+// extractRules returns the rules of the active environment followed by the
+// # qla:extract rules of query.
+func (r *Runner) extractRules(query string) ([]extract.Rule, error) {
+	var rules []extract.Rule
+	if env := r.cfg.ActiveEnvironment(); env != nil {
+		rules = append(rules, env.Extract...)
+	}
+	more, err := extract.FromQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, more...), nil
+}
+
+func (r *Runner) applyExtract(rules []extract.Rule, data json.RawMessage) {
+	env := r.cfg.ActiveEnvironment()
+	if env == nil {
+		return
+	}
+	values, _ := extract.Apply(rules, data)
+	if len(values) == 0 {
+		return
+	}
+	r.cfg.SetExtracted(env.Name, values)
+}
+
+// Summary counts the passed and failed results and adds up their durations.
+func Summary(results []Result) (passed, failed int, total time.Duration) {
+	for _, r := range results {
+		if r.Passed() {
+			passed++
+		} else {
+			failed++
+		}
+		total += r.Duration
+	}
+	return passed, failed, total
+}
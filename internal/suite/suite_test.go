@@ -0,0 +1,157 @@
+package suite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qraqula/qla/internal/config"
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/history"
+)
+
+// testServer answers Login with a token and Me with the user of the token
+// sent in X-Token, failing without one.
+func testServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case req.OperationName == "Login":
+			_, _ = w.Write([]byte(`{"data":{"login":{"token":"tok-` + req.Variables["user"].(string) + `"}}}`))
+		case r.Header.Get("X-Token") == "tok-ada":
+			_, _ = w.Write([]byte(`{"data":{"me":{"name":"Ada"}}}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"unauthorized"}]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testConfig(url string) *config.Config {
+	return &config.Config{
+		ActiveEnv: "dev",
+		Environments: []config.Environment{{
+			Name:     "dev",
+			Endpoint: url,
+			Values:   map[string]string{"token": "none"},
+			Headers:  []config.Header{{Key: "X-Token", Value: "{{token}}", Enabled: true}},
+		}},
+	}
+}
+
+func entry(name, query, vars string, age time.Duration) history.Entry {
+	return history.Entry{ID: name, Name: name, Query: query, Variables: vars, CreatedAt: time.Now().Add(-age)}
+}
+
+func TestRun(t *testing.T) {
+	srv := testServer(t)
+	cfg := testConfig(srv.URL)
+	entries := Order([]history.Entry{
+		entry("me", "# qla:assert data.me.name == \"Ada\"\n# qla:assert duration < 5s\nquery Me { me { name } }", "", time.Minute),
+		entry("login", "# qla:extract data.login.token -> token\nmutation Login($user: String!) { login(user: $user) { token } }", `{"user":"ada"}`, time.Hour),
+		entry("wrong", "# qla:assert data.me.name == \"Bob\"\n# qla:assert status 201\nquery Me { me { name } }", "", time.Second),
+	})
+	if entries[0].Name != "login" || entries[2].Name != "wrong" {
+		t.Fatalf("expected oldest first, got %v", entries)
+	}
+
+	r, err := New(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var seen int
+	results := r.Run(context.Background(), entries, func(Result) { seen++ })
+	if seen != 3 || len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d (%d reported)", len(results), seen)
+	}
+	if !results[0].Passed() || results[0].Checks[0].Assertion != "status 2xx, noerrors" {
+		t.Errorf("expected login to pass the default check, got %+v", results[0])
+	}
+	if !results[1].Passed() || len(results[1].Checks) != 2 {
+		t.Errorf("expected me to pass with the extracted token, got %v", results[1].Failures())
+	}
+	want := []string{`data.me.name == "Bob": got "Ada"`, "status 201: status 200"}
+	if got := results[2].Failures(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got failures %q, want %q", got, want)
+	}
+	if cfg.Environments[0].Values["token"] != "none" {
+		t.Error("expected the extracted token not to leak into the configuration")
+	}
+	if passed, failed, _ := Summary(results); passed != 2 || failed != 1 {
+		t.Errorf("got %d passed, %d failed", passed, failed)
+	}
+
+	// A value the session extracted earlier gives way to the suite's own.
+	cfg.SetExtracted("dev", map[string]string{"token": "stale"})
+	r, _ = New(cfg, nil, nil)
+	results = r.Run(context.Background(), entries[:2], nil)
+	if !results[1].Passed() {
+		t.Errorf("expected me to pass with the token the suite extracted, got %v", results[1].Failures())
+	}
+	if v, _ := cfg.Lookup()("token"); v != "stale" {
+		t.Errorf("expected the session's value kept, got %q", v)
+	}
+
+	// Without the login the default check fails on the status.
+	r, _ = New(cfg, nil, nil)
+	res := r.RunEntry(context.Background(), entry("me", "query Me { me { name } }", "", 0))
+	if res.Passed() || res.Failures()[0] != "status 2xx, noerrors: status 401" {
+		t.Errorf("expected the default check to fail, got %v", res.Failures())
+	}
+}
+
+func TestRunEntryErrors(t *testing.T) {
+	r, err := New(testConfig("http://127.0.0.1:1"), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]history.Entry{
+		"several operations": entry("two", "query A { a } query B { b }", "", 0),
+		"not supported":      entry("sub", "subscription S { s }", "", 0),
+		"variables JSON":     entry("vars", "query A { a }", "{", 0),
+		"assert":             entry("assert", "# qla:assert status x\nquery A { a }", "", 0),
+		"connection refused": entry("down", "query A { a }", "", 0),
+	}
+	for want, e := range tests {
+		res := r.RunEntry(context.Background(), e)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), want) {
+			t.Errorf("%s: expected an error containing %q, got %v", e.Name, want, res.Err)
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	results := []Result{
+		{Entry: history.Entry{Name: "ok"}, Status: 200, Duration: 120 * time.Millisecond, Checks: []Check{{Assertion: "status 200"}}},
+		{Entry: history.Entry{Name: "bad"}, Status: 500, Duration: 80 * time.Millisecond, Checks: []Check{{Assertion: "status 200", Err: errFor("status 500")}}},
+		{Entry: history.Entry{Name: "down"}, Err: errFor("connection refused")},
+	}
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, "smoke", time.Time{}, results); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuites tests="3" failures="1" errors="1" time="0.200">`,
+		`<testsuite name="smoke" tests="3" failures="1" errors="1" time="0.200">`,
+		`<testcase name="ok" classname="smoke" time="0.120"></testcase>`,
+		`<failure message="status 200: status 500">status 200: status 500</failure>`,
+		`<error message="connection refused">connection refused</error>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, buf.String())
+		}
+	}
+}
+
+type errFor string
+
+func (e errFor) Error() string { return string(e) }
@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+	"github.com/vektah/gqlparser/v2/validator/rules"
+)
+
+// Problem is one error in a linted document.
+type Problem struct {
+	File    string
+	Line    int // 1-based, 0 when the error has no location
+	Column  int
+	Message string
+	Rule    string // the validation rule, empty for syntax errors
+}
+
+// Lint validates every operation and fragment of the sources against the
+// schema and returns every problem, ordered by source and position.
+// Fragments may be defined in any of the sources, as in client repos that
+// keep them in files of their own, so a fragment unused by the operations of
+// its source is not a problem.
+func Lint(sources []*ast.Source, schemaAST *SchemaAST) []Problem {
+	found := make([][]Problem, len(sources))
+	docs := make([]*ast.QueryDocument, len(sources))
+	var fragments ast.FragmentDefinitionList
+	for i, src := range sources {
+		doc, err := parser.ParseQuery(src)
+		if err != nil {
+			var gqlErr *gqlerror.Error
+			if !errors.As(err, &gqlErr) {
+				gqlErr = gqlerror.Wrap(err)
+			}
+			found[i] = []Problem{problem(src.Name, gqlErr)}
+			continue
+		}
+		docs[i] = doc
+		fragments = append(fragments, doc.Fragments...)
+	}
+
+	lintRules := rules.NewDefaultRules()
+	lintRules.RemoveRule("NoUnusedFragments")
+	for i, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		name := sources[i].Name
+		// The fragments of the other sources are only there to be spread;
+		// their own problems are reported with their source.
+		merged := &ast.QueryDocument{
+			Operations: doc.Operations,
+			Fragments:  slices.Clone(doc.Fragments),
+			Position:   doc.Position,
+		}
+		for _, f := range fragments {
+			if merged.Fragments.ForName(f.Name) == nil {
+				merged.Fragments = append(merged.Fragments, f)
+			}
+		}
+		for _, err := range validator.ValidateWithRules(schemaAST.ast, merged, lintRules) {
+			if file, _ := err.Extensions["file"].(string); file == "" || file == name {
+				found[i] = append(found[i], problem(name, err))
+			}
+		}
+		slices.SortStableFunc(found[i], func(a, b Problem) int {
+			if a.Line != b.Line {
+				return a.Line - b.Line
+			}
+			return a.Column - b.Column
+		})
+	}
+	return slices.Concat(found...)
+}
+
+func problem(file string, err *gqlerror.Error) Problem {
+	p := Problem{File: file, Message: err.Message, Rule: err.Rule}
+	if len(err.Locations) > 0 {
+		p.Line, p.Column = err.Locations[0].Line, err.Locations[0].Column
+	}
+	return p
+}
@@ -0,0 +1,39 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestLint(t *testing.T) {
+	s := LoadSchema(testSchema())
+	sources := []*ast.Source{
+		{Name: "users.graphql", Input: "query Users {\n  users { ...UserFields nickname }\n}\n\nquery User($id: ID!) {\n  user(id: $id, x: 1) { id }\n}\n"},
+		{Name: "fragments.graphql", Input: "fragment UserFields on User {\n  id\n  name\n}\n\nfragment Unused on User { age }\n"},
+		{Name: "broken.graphql", Input: "query {\n  users {\n"},
+		{Name: "ok.graphql", Input: "{ users { ...UserFields } }"},
+	}
+	got := Lint(sources, s)
+	want := []Problem{
+		{File: "users.graphql", Line: 2, Column: 25, Message: `Cannot query field "nickname" on type "User". Did you mean "name"?`, Rule: "FieldsOnCorrectType"},
+		{File: "users.graphql", Line: 6, Column: 3, Message: `Unknown argument "x" on field "Query.user".`, Rule: "KnownArgumentNames"},
+		{File: "fragments.graphql", Line: 6, Column: 27, Message: `Cannot query field "age" on type "User". Did you mean "name"?`, Rule: "FieldsOnCorrectType"},
+		{File: "broken.graphql", Line: 3, Column: 1, Message: "Expected Name, found <EOF>"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("problem %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestLintUnknownFragment(t *testing.T) {
+	got := Lint([]*ast.Source{{Name: "a.graphql", Input: "{ users { ...Missing } }"}}, LoadSchema(testSchema()))
+	if len(got) != 1 || got[0].Rule != "KnownFragmentNames" {
+		t.Errorf("expected an unknown fragment, got %+v", got)
+	}
+}
@@ -3,8 +3,12 @@ package validate
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
+	"github.com/qraqula/qla/internal/graphql"
+	"github.com/qraqula/qla/internal/interp"
 	"github.com/qraqula/qla/internal/schema"
 	"github.com/vektah/gqlparser/v2"
 	"github.com/vektah/gqlparser/v2/ast"
@@ -23,12 +27,35 @@ func LoadSchema(s *schema.Schema) *SchemaAST {
 	if s == nil {
 		return nil
 	}
-	sdl := IntrospectionToSDL(s)
-	parsed, err := gqlparser.LoadSchema(&ast.Source{Input: sdl})
+	parsed, err := ParseSchema(s)
 	if err != nil {
 		return nil
 	}
-	return &SchemaAST{ast: parsed, source: s}
+	return parsed
+}
+
+// ParseSchema is LoadSchema reporting why the conversion failed.
+func ParseSchema(s *schema.Schema) (*SchemaAST, error) {
+	parsed, err := gqlparser.LoadSchema(&ast.Source{Input: IntrospectionToSDL(s)})
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaAST{ast: parsed, source: s}, nil
+}
+
+// ParseSDL parses a schema written in SDL. name identifies the source in
+// errors, usually its file name.
+func ParseSDL(name, sdl string) (*SchemaAST, error) {
+	parsed, err := gqlparser.LoadSchema(&ast.Source{Name: name, Input: sdl})
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaAST{ast: parsed}, nil
+}
+
+// AST returns the parsed schema.
+func (s *SchemaAST) AST() *ast.Schema {
+	return s.ast
 }
 
 // Query validates a GraphQL query string against the schema.
@@ -56,14 +83,180 @@ func Query(query string, schemaAST *SchemaAST) error {
 	return nil
 }
 
-// Variables validates variables JSON against the variable definitions in a query.
+// OperationType returns the type ("query", "mutation" or "subscription") of
+// the named operation, or of the only operation when operationName is empty.
+// It returns "" if the document does not parse or the operation is unknown.
+func OperationType(query, operationName string) string {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return ""
+	}
+	if op := selectOperation(doc.Operations, operationName); op != nil {
+		return string(op.Operation)
+	}
+	return ""
+}
+
+// VariableNames returns the variables declared by the named operation, or by
+// the only operation when operationName is empty. It needs no schema and
+// returns nil if the document does not parse or the operation is unknown.
+func VariableNames(query, operationName string) []string {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return nil
+	}
+	op := selectOperation(doc.Operations, operationName)
+	if op == nil {
+		return nil
+	}
+	names := make([]string, len(op.VariableDefinitions))
+	for i, def := range op.VariableDefinitions {
+		names[i] = def.Variable
+	}
+	return names
+}
+
+// ScalarVariables returns the named type of each variable of the named
+// operation of query that is not a list, such as "Int" for $n: Int!.
+func ScalarVariables(query, operationName string) map[string]string {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return nil
+	}
+	op := selectOperation(doc.Operations, operationName)
+	if op == nil {
+		return nil
+	}
+	types := make(map[string]string)
+	for _, def := range op.VariableDefinitions {
+		if def.Type.Elem == nil {
+			types[def.Variable] = def.Type.NamedType
+		}
+	}
+	return types
+}
+
+// Operation describes one operation of a document. StartLine and EndLine
+// (1-based, inclusive) span the operation up to the next definition.
+type Operation struct {
+	Name      string
+	Type      string
+	StartLine int
+	EndLine   int
+}
+
+// Operations lists the operations of query in document order. It returns nil
+// when the query does not parse.
+func Operations(query string) []Operation {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return nil
+	}
+
+	// Every definition starts a new block; an operation ends where the next
+	// definition (operation or fragment) begins.
+	var starts []int
+	for _, op := range doc.Operations {
+		starts = append(starts, op.Position.Line)
+	}
+	for _, frag := range doc.Fragments {
+		starts = append(starts, frag.Position.Line)
+	}
+	sort.Ints(starts)
+	lastLine := strings.Count(query, "\n") + 1
+
+	ops := make([]Operation, 0, len(doc.Operations))
+	for _, op := range doc.Operations {
+		end := lastLine
+		for _, s := range starts {
+			if s > op.Position.Line {
+				end = s - 1
+				break
+			}
+		}
+		ops = append(ops, Operation{
+			Name:      op.Name,
+			Type:      string(op.Operation),
+			StartLine: op.Position.Line,
+			EndLine:   end,
+		})
+	}
+	return ops
+}
+
+// OperationAt returns the operation whose lines contain line (1-based).
+func OperationAt(ops []Operation, line int) (Operation, bool) {
+	for _, op := range ops {
+		if line >= op.StartLine && line <= op.EndLine {
+			return op, true
+		}
+	}
+	return Operation{}, false
+}
+
+// selectOperation picks the named operation, or the only one when name is
+// empty. It returns nil when the choice is ambiguous or the name is unknown.
+func selectOperation(ops ast.OperationList, name string) *ast.OperationDefinition {
+	if name == "" {
+		if len(ops) == 1 {
+			return ops[0]
+		}
+		return nil
+	}
+	return ops.ForName(name)
+}
+
+// UsesIncrementalDelivery reports whether the query uses @defer or @stream,
+// which makes the server answer with a stream of partial results.
+func UsesIncrementalDelivery(query string) bool {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return false
+	}
+	for _, op := range doc.Operations {
+		if hasIncrementalDirective(op.SelectionSet) {
+			return true
+		}
+	}
+	for _, frag := range doc.Fragments {
+		if hasIncrementalDirective(frag.SelectionSet) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasIncrementalDirective(set ast.SelectionSet) bool {
+	for _, sel := range set {
+		var dirs ast.DirectiveList
+		var children ast.SelectionSet
+		switch s := sel.(type) {
+		case *ast.Field:
+			dirs, children = s.Directives, s.SelectionSet
+		case *ast.InlineFragment:
+			dirs, children = s.Directives, s.SelectionSet
+		case *ast.FragmentSpread:
+			dirs = s.Directives
+		}
+		if dirs.ForName("defer") != nil || dirs.ForName("stream") != nil {
+			return true
+		}
+		if hasIncrementalDirective(children) {
+			return true
+		}
+	}
+	return false
+}
+
+// Variables validates variables JSON against the variable definitions of the
+// operation named operationName (or the only operation when it is empty).
 // It checks:
 //   - All required variables (non-null without defaults) are present
 //   - No unknown variables are provided
 //   - Basic type compatibility (scalars, enums, input objects)
 //
 // If schemaAST is nil, only JSON syntax is validated.
-func Variables(varsJSON string, query string, schemaAST *SchemaAST) error {
+func Variables(varsJSON string, query string, operationName string, schemaAST *SchemaAST) error {
 	varsJSON = strings.TrimSpace(varsJSON)
 	if varsJSON == "" {
 		return nil
@@ -91,11 +284,10 @@ func Variables(varsJSON string, query string, schemaAST *SchemaAST) error {
 		return nil
 	}
 
-	if len(doc.Operations) == 0 {
+	op := selectOperation(doc.Operations, operationName)
+	if op == nil {
 		return nil
 	}
-
-	op := doc.Operations[0]
 	defs := op.VariableDefinitions
 
 	// Check for missing required variables
@@ -124,6 +316,13 @@ func Variables(varsJSON string, query string, schemaAST *SchemaAST) error {
 		if !ok {
 			continue
 		}
+		// A placeholder standing alone is sent as a number or boolean
+		// where the variable takes one; see request.Prepare.
+		if s, ok := val.(string); ok && def.Type.Elem == nil && IsTypedScalar(def.Type.NamedType) {
+			if _, sole := interp.Sole(s); sole {
+				continue
+			}
+		}
 		if err := checkType(val, def.Type, schemaAST.ast); err != nil {
 			return fmt.Errorf("$%s: %w", def.Variable, err)
 		}
@@ -169,7 +368,7 @@ func checkType(val any, typ *ast.Type, s *ast.Schema) error {
 	def := s.Types[name]
 
 	switch {
-	case isScalar(name):
+	case isScalar(name), name == "Upload":
 		return checkScalar(val, name)
 	case def != nil && def.Kind == ast.Enum:
 		str, ok := val.(string)
@@ -216,6 +415,14 @@ func checkScalar(val any, name string) error {
 		if _, ok := val.(bool); !ok {
 			return fmt.Errorf("expected boolean for Boolean")
 		}
+	case "Upload":
+		path, ok := graphql.FileRef(val)
+		if !ok {
+			return fmt.Errorf("expected file reference (\"@./path\") for Upload")
+		}
+		if _, err := os.Stat(graphql.ExpandPath(path)); err != nil {
+			return fmt.Errorf("file not found: %s", path)
+		}
 	}
 	return nil
 }
@@ -254,6 +461,12 @@ func checkInputObject(obj map[string]any, def *ast.Definition, s *ast.Schema) er
 	return nil
 }
 
+// IsTypedScalar reports whether the built-in scalar name takes a JSON
+// number or boolean rather than a string.
+func IsTypedScalar(name string) bool {
+	return name == "Int" || name == "Float" || name == "Boolean"
+}
+
 func isScalar(name string) bool {
 	switch name {
 	case "String", "Int", "Float", "Boolean", "ID":
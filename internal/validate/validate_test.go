@@ -1,6 +1,9 @@
 package validate
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/qraqula/qla/internal/schema"
@@ -156,7 +159,7 @@ func TestQuerySyntaxErrorNoSchema(t *testing.T) {
 
 func TestVariablesValid(t *testing.T) {
 	ast := LoadSchema(testSchema())
-	err := Variables(`{"id": "123"}`, `query($id: ID!) { user(id: $id) { name } }`, ast)
+	err := Variables(`{"id": "123"}`, `query($id: ID!) { user(id: $id) { name } }`, "", ast)
 	if err != nil {
 		t.Errorf("expected valid variables, got: %v", err)
 	}
@@ -164,7 +167,7 @@ func TestVariablesValid(t *testing.T) {
 
 func TestVariablesMissingRequired(t *testing.T) {
 	ast := LoadSchema(testSchema())
-	err := Variables(`{}`, `query($id: ID!) { user(id: $id) { name } }`, ast)
+	err := Variables(`{}`, `query($id: ID!) { user(id: $id) { name } }`, "", ast)
 	if err == nil {
 		t.Error("expected error for missing required variable")
 	}
@@ -175,7 +178,7 @@ func TestVariablesMissingRequired(t *testing.T) {
 
 func TestVariablesUnknown(t *testing.T) {
 	ast := LoadSchema(testSchema())
-	err := Variables(`{"id": "1", "extra": true}`, `query($id: ID!) { user(id: $id) { name } }`, ast)
+	err := Variables(`{"id": "1", "extra": true}`, `query($id: ID!) { user(id: $id) { name } }`, "", ast)
 	if err == nil {
 		t.Error("expected error for unknown variable")
 	}
@@ -186,7 +189,7 @@ func TestVariablesUnknown(t *testing.T) {
 
 func TestVariablesWrongType(t *testing.T) {
 	ast := LoadSchema(testSchema())
-	err := Variables(`{"id": 123}`, `query($id: ID!) { user(id: $id) { name } }`, ast)
+	err := Variables(`{"id": 123}`, `query($id: ID!) { user(id: $id) { name } }`, "", ast)
 	if err == nil {
 		t.Error("expected error for wrong type (number for ID)")
 	}
@@ -194,7 +197,7 @@ func TestVariablesWrongType(t *testing.T) {
 
 func TestVariablesEnumValid(t *testing.T) {
 	ast := LoadSchema(testSchema())
-	err := Variables(`{"role": "ADMIN"}`, `query($role: Role) { users(role: $role) { name } }`, ast)
+	err := Variables(`{"role": "ADMIN"}`, `query($role: Role) { users(role: $role) { name } }`, "", ast)
 	if err != nil {
 		t.Errorf("expected valid enum variable, got: %v", err)
 	}
@@ -202,7 +205,7 @@ func TestVariablesEnumValid(t *testing.T) {
 
 func TestVariablesEnumInvalid(t *testing.T) {
 	ast := LoadSchema(testSchema())
-	err := Variables(`{"role": "INVALID"}`, `query($role: Role) { users(role: $role) { name } }`, ast)
+	err := Variables(`{"role": "INVALID"}`, `query($role: Role) { users(role: $role) { name } }`, "", ast)
 	if err == nil {
 		t.Error("expected error for invalid enum value")
 	}
@@ -210,7 +213,7 @@ func TestVariablesEnumInvalid(t *testing.T) {
 
 func TestVariablesInvalidJSON(t *testing.T) {
 	ast := LoadSchema(testSchema())
-	err := Variables(`{broken`, `{ user(id: "1") { id } }`, ast)
+	err := Variables(`{broken`, `{ user(id: "1") { id } }`, "", ast)
 	if err == nil {
 		t.Error("expected error for invalid JSON")
 	}
@@ -218,14 +221,14 @@ func TestVariablesInvalidJSON(t *testing.T) {
 
 func TestVariablesEmpty(t *testing.T) {
 	ast := LoadSchema(testSchema())
-	err := Variables(``, `{ user(id: "1") { id } }`, ast)
+	err := Variables(``, `{ user(id: "1") { id } }`, "", ast)
 	if err != nil {
 		t.Errorf("expected nil for empty variables, got: %v", err)
 	}
 }
 
 func TestVariablesNoSchema(t *testing.T) {
-	err := Variables(`{"id": "1"}`, `query($id: ID!) { user(id: $id) { name } }`, nil)
+	err := Variables(`{"id": "1"}`, `query($id: ID!) { user(id: $id) { name } }`, "", nil)
 	if err != nil {
 		t.Errorf("expected pass without schema, got: %v", err)
 	}
@@ -252,12 +255,41 @@ func TestVariablesIntValid(t *testing.T) {
 		},
 	}
 	ast := LoadSchema(s)
-	err := Variables(`{"count": 5}`, `query($count: Int) { item(count: $count) }`, ast)
+	err := Variables(`{"count": 5}`, `query($count: Int) { item(count: $count) }`, "", ast)
 	if err != nil {
 		t.Errorf("expected valid int, got: %v", err)
 	}
 }
 
+func TestVariablesIntPlaceholder(t *testing.T) {
+	s := &schema.Schema{
+		QueryType: &schema.TypeRef{Kind: "OBJECT", Name: ptr("Query")},
+		Types: []schema.FullType{
+			{
+				Kind: "OBJECT",
+				Name: "Query",
+				Fields: []schema.Field{
+					{
+						Name: "item",
+						Args: []schema.InputValue{
+							{Name: "count", Type: schema.TypeRef{Kind: "SCALAR", Name: ptr("Int")}},
+						},
+						Type: schema.TypeRef{Kind: "SCALAR", Name: ptr("String")},
+					},
+				},
+			},
+		},
+	}
+	ast := LoadSchema(s)
+	query := `query($count: Int) { item(count: $count) }`
+	if err := Variables(`{"count": "{{$randomInt 1 6}}"}`, query, "", ast); err != nil {
+		t.Errorf("expected a lone placeholder accepted for Int, got: %v", err)
+	}
+	if err := Variables(`{"count": "n{{$randomInt}}"}`, query, "", ast); err == nil {
+		t.Error("expected text around a placeholder rejected for Int")
+	}
+}
+
 func TestVariablesIntFloat(t *testing.T) {
 	s := &schema.Schema{
 		QueryType: &schema.TypeRef{Kind: "OBJECT", Name: ptr("Query")},
@@ -278,7 +310,7 @@ func TestVariablesIntFloat(t *testing.T) {
 		},
 	}
 	ast := LoadSchema(s)
-	err := Variables(`{"count": 5.5}`, `query($count: Int) { item(count: $count) }`, ast)
+	err := Variables(`{"count": 5.5}`, `query($count: Int) { item(count: $count) }`, "", ast)
 	if err == nil {
 		t.Error("expected error for float where Int expected")
 	}
@@ -296,3 +328,144 @@ func searchContains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestOperationType(t *testing.T) {
+	tests := map[string]string{
+		"{ user { id } }":                                  "query",
+		"mutation M { save }":                              "mutation",
+		"subscription OnTick { tick }":                     "subscription",
+		"fragment F on User { id }\nsubscription { tick }": "subscription",
+		"{ broken": "",
+	}
+	for q, want := range tests {
+		if got := OperationType(q, ""); got != want {
+			t.Errorf("OperationType(%q) = %q, want %q", q, got, want)
+		}
+	}
+}
+
+func TestVariableNames(t *testing.T) {
+	query := "query A($id: ID!, $tenant: String) { a }\n\nquery B { b }"
+	if got := strings.Join(VariableNames(query, "A"), ","); got != "id,tenant" {
+		t.Errorf("expected id,tenant, got %q", got)
+	}
+	if got := VariableNames(query, "B"); len(got) != 0 {
+		t.Errorf("expected no variables for B, got %v", got)
+	}
+	if got := VariableNames(query, ""); got != nil {
+		t.Errorf("expected nil for an ambiguous document, got %v", got)
+	}
+}
+
+func TestScalarVariables(t *testing.T) {
+	got := ScalarVariables("query A($n: Int!, $ids: [ID!], $on: Boolean) { a }", "A")
+	if len(got) != 2 || got["n"] != "Int" || got["on"] != "Boolean" {
+		t.Errorf("expected n and on without the list, got %v", got)
+	}
+}
+
+func TestUsesIncrementalDelivery(t *testing.T) {
+	tests := map[string]bool{
+		"{ user { id } }":                                               false,
+		"{ user { id ... @defer { name } } }":                           true,
+		"{ feed { posts @stream(initialCount: 1) { id } } }":            true,
+		"{ user { ...F @defer } }\nfragment F on User { name }":         true,
+		"{ user { ...F } }\nfragment F on User { ... @defer { name } }": true,
+		"{ broken": false,
+	}
+	for q, want := range tests {
+		if got := UsesIncrementalDelivery(q); got != want {
+			t.Errorf("UsesIncrementalDelivery(%q) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+const multiOpDoc = `query GetUser($id: ID!) {
+  user(id: $id) { ...Name }
+}
+
+fragment Name on User { name }
+
+query ListUsers($role: Role) {
+  users(role: $role) { name }
+}
+`
+
+func TestOperations(t *testing.T) {
+	ops := Operations(multiOpDoc)
+	if len(ops) != 2 {
+		t.Fatalf("expected 2 operations, got %d", len(ops))
+	}
+	if ops[0].Name != "GetUser" || ops[0].StartLine != 1 || ops[0].EndLine != 4 {
+		t.Errorf("unexpected first operation: %+v", ops[0])
+	}
+	if ops[1].Name != "ListUsers" || ops[1].StartLine != 7 || ops[1].EndLine != 10 {
+		t.Errorf("unexpected second operation: %+v", ops[1])
+	}
+
+	if op, ok := OperationAt(ops, 8); !ok || op.Name != "ListUsers" {
+		t.Errorf("expected ListUsers at line 8, got %+v", op)
+	}
+	if _, ok := OperationAt(ops, 5); ok {
+		t.Error("expected no operation on the fragment line")
+	}
+	if Operations("{ broken") != nil {
+		t.Error("expected nil for unparsable document")
+	}
+}
+
+func TestVariablesSelectedOperation(t *testing.T) {
+	ast := LoadSchema(testSchema())
+	if err := Variables(`{"role": "ADMIN"}`, multiOpDoc, "ListUsers", ast); err != nil {
+		t.Errorf("expected valid variables for ListUsers, got: %v", err)
+	}
+	if err := Variables(`{"role": "ADMIN"}`, multiOpDoc, "GetUser", ast); err == nil {
+		t.Error("expected GetUser to reject its missing $id")
+	}
+	if err := Variables(`{"role": "ADMIN"}`, multiOpDoc, "", ast); err != nil {
+		t.Errorf("expected ambiguous selection to skip validation, got: %v", err)
+	}
+	if got := OperationType("query A { a }\nsubscription B { b }", "B"); got != "subscription" {
+		t.Errorf("expected subscription for B, got %q", got)
+	}
+}
+
+func TestVariablesUpload(t *testing.T) {
+	s := &schema.Schema{
+		QueryType:    &schema.TypeRef{Kind: "OBJECT", Name: ptr("Query")},
+		MutationType: &schema.TypeRef{Kind: "OBJECT", Name: ptr("Mutation")},
+		Types: []schema.FullType{
+			{Kind: "OBJECT", Name: "Query", Fields: []schema.Field{{Name: "ok", Type: schema.TypeRef{Kind: "SCALAR", Name: ptr("Boolean")}}}},
+			{Kind: "SCALAR", Name: "Upload"},
+			{
+				Kind: "OBJECT",
+				Name: "Mutation",
+				Fields: []schema.Field{
+					{
+						Name: "upload",
+						Args: []schema.InputValue{
+							{Name: "file", Type: schema.TypeRef{Kind: "NON_NULL", OfType: &schema.TypeRef{Kind: "SCALAR", Name: ptr("Upload")}}},
+						},
+						Type: schema.TypeRef{Kind: "SCALAR", Name: ptr("Boolean")},
+					},
+				},
+			},
+		},
+	}
+	ast := LoadSchema(s)
+	query := `mutation($file: Upload!) { upload(file: $file) }`
+
+	file := filepath.Join(t.TempDir(), "avatar.png")
+	if err := os.WriteFile(file, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Variables(`{"file": "@`+file+`"}`, query, "", ast); err != nil {
+		t.Errorf("expected file reference to be valid, got: %v", err)
+	}
+	if err := Variables(`{"file": "avatar.png"}`, query, "", ast); err == nil {
+		t.Error("expected error for plain string as Upload")
+	}
+	if err := Variables(`{"file": "@./does-not-exist.png"}`, query, "", ast); err == nil || !contains(err.Error(), "not found") {
+		t.Errorf("expected missing file error, got: %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 var (
 	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	dimStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 )
 
 type Model struct {
@@ -18,6 +19,7 @@ type Model struct {
 	width   int
 	height  int
 	editing bool
+	fromEnv []string // variables supplied by the active environment
 }
 
 func New() Model {
@@ -72,6 +74,11 @@ func (m *Model) SetSize(w, h int) {
 	m.ta.SetHeight(h - 3)
 }
 
+// SetFromEnv lists the variables the active environment adds to the panel's.
+func (m *Model) SetFromEnv(names []string) {
+	m.fromEnv = names
+}
+
 func (m Model) ParsedVariables() (map[string]any, error) {
 	v := strings.TrimSpace(m.ta.Value())
 	if v == "" {
@@ -99,5 +106,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 func (m Model) View() string {
 	title := titleStyle.Render(" Variables ")
+	if len(m.fromEnv) > 0 {
+		title += dimStyle.Render(" + env: " + strings.Join(m.fromEnv, ", "))
+	}
 	return title + "\n" + m.ta.View()
 }